@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/Amr-9/sayl/internal/compare"
+	"github.com/Amr-9/sayl/internal/report"
+)
+
+// runDiffCommand implements `sayl diff`: it compares a candidate report.json
+// against a baseline and exits with exitThresholdsFailed on regressions.
+func runDiffCommand(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sayl diff [-latency 10%] [-rps 10%] [-error-rate 1] [-html diff.html] baseline.json candidate.json")
+		fs.PrintDefaults()
+	}
+
+	def := compare.DefaultTolerances()
+	var latencyStr, rpsStr, errorRateStr, htmlPath string
+	fs.StringVar(&latencyStr, "latency", fmt.Sprintf("%g%%", def.Latency), "Allowed increase of P50/P90/P95/P99 latency, in percent ('off' to disable)")
+	fs.StringVar(&rpsStr, "rps", fmt.Sprintf("%g%%", def.RPS), "Allowed decrease of RPS, in percent ('off' to disable)")
+	fs.StringVar(&errorRateStr, "error-rate", fmt.Sprintf("%g", def.ErrorRate), "Allowed increase of the error rate, in percentage points ('off' to disable)")
+	fs.StringVar(&htmlPath, "html", "", "Also write a side-by-side HTML comparison to this file")
+
+	// Accept flags before, between or after the two report paths.
+	var paths []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return exitError
+		}
+		if fs.NArg() == 0 {
+			break
+		}
+		paths = append(paths, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+	if len(paths) != 2 {
+		fs.Usage()
+		return exitError
+	}
+
+	var tol compare.Tolerances
+	for _, opt := range []struct {
+		name string
+		raw  string
+		dst  *float64
+	}{
+		{"latency", latencyStr, &tol.Latency},
+		{"rps", rpsStr, &tol.RPS},
+		{"error-rate", errorRateStr, &tol.ErrorRate},
+	} {
+		v, err := compare.ParseTolerance(opt.raw)
+		if err != nil {
+			fmt.Printf("Invalid -%s flag: %v\n", opt.name, err)
+			return exitError
+		}
+		*opt.dst = v
+	}
+
+	baseline, err := compare.Load(paths[0])
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitError
+	}
+	candidate, err := compare.Load(paths[1])
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitError
+	}
+
+	c := compare.Compare(baseline, candidate, tol)
+	c.BaselineName, c.CandidateName = paths[0], paths[1]
+	report.PrintConsoleDiff(c)
+
+	if htmlPath != "" {
+		if err := report.GenerateDiffHTML(c, htmlPath); err != nil {
+			fmt.Printf("⚠️  Failed to generate HTML comparison: %v\n", err)
+			return exitError
+		}
+		fmt.Printf("\n📈 HTML comparison saved to %s\n", htmlPath)
+	}
+
+	if c.Regressions > 0 {
+		return exitThresholdsFailed
+	}
+	return exitOK
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Amr-9/sayl/internal/report"
+	"github.com/Amr-9/sayl/internal/runner"
+	"github.com/Amr-9/sayl/pkg/config"
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// isTerminal reports whether f is attached to an interactive terminal.
+// CI runners and redirected output are not, so the TUI is skipped there.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// runHeadless runs the test without the TUI and returns the final report.
+// Unless quiet is set, a compact progress line is printed every interval.
+func runHeadless(ctx context.Context, cfg *models.Config, interval time.Duration, quiet bool, observers ...runner.Observer) models.Report {
+	r := runner.New(*cfg, observers...)
+	rc := r.Config()
+
+	if !quiet {
+		load := fmt.Sprintf("%d req/s", rc.Rate)
+		if len(rc.Stages) > 0 {
+			load = fmt.Sprintf("%d stages", len(rc.Stages))
+		}
+		target := fmt.Sprintf("%s %s", rc.Method, rc.URL)
+		if len(rc.Steps) > 0 {
+			target = fmt.Sprintf("scenario with %d steps", len(rc.Steps))
+		}
+		fmt.Printf("🚀 Running %s for %s (%s, %d workers)\n", target, rc.Duration, load, rc.Concurrency)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(ctx)
+	}()
+
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastTick := time.Now()
+	var lastRequests int64
+	for {
+		select {
+		case <-done:
+			rep := r.FinalReport()
+			rep.Config = config.RedactedYAML(&rc)
+			if rep.Interrupted {
+				fmt.Printf("⚠️  Test interrupted after %s — results are partial\n", rep.Elapsed.Round(time.Millisecond))
+			}
+			if rep.CircuitBroken {
+				fmt.Printf("⛔ %s\n", rep.CircuitBreakReason)
+			}
+			return rep
+		case now := <-ticker.C:
+			if quiet {
+				continue
+			}
+			rep := r.Snapshot()
+			rps := float64(rep.TotalRequests-lastRequests) / now.Sub(lastTick).Seconds()
+			lastRequests, lastTick = rep.TotalRequests, now
+			// Progress follows the test time, which stops while paused.
+			line := report.FormatProgress(rep, r.Elapsed(), rps)
+			if r.Paused() {
+				line += " ⏸ paused"
+			}
+			fmt.Println(line)
+		}
+	}
+}
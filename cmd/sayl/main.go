@@ -7,50 +7,75 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/Amr-9/sayl/internal/control"
 	"github.com/Amr-9/sayl/internal/debug"
+	"github.com/Amr-9/sayl/internal/metrics"
+	"github.com/Amr-9/sayl/internal/outputs"
 	"github.com/Amr-9/sayl/internal/report"
+	"github.com/Amr-9/sayl/internal/runner"
+	"github.com/Amr-9/sayl/internal/telemetry"
+	"github.com/Amr-9/sayl/internal/threshold"
 	"github.com/Amr-9/sayl/internal/tui"
+	"github.com/Amr-9/sayl/internal/web"
 	"github.com/Amr-9/sayl/pkg/config"
 	"github.com/Amr-9/sayl/pkg/models"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// Exit codes, documented in the README so CI pipelines can gate on them.
+const (
+	exitOK               = 0   // Test completed and every threshold passed
+	exitError            = 1   // Invalid configuration or runtime error
+	exitThresholdsFailed = 99  // Test completed but at least one threshold failed (or `sayl diff` found a regression)
+	exitInterrupted      = 130 // Test stopped by SIGINT/SIGTERM; a partial report was saved
+)
+
 func main() {
 	// Panic recovery - prevent crashes
 	defer func() {
 		if r := recover(); r != nil {
 			fmt.Printf("\n❌ Fatal error: %v\n", r)
 			fmt.Println("💡 Please report this issue at: https://github.com/Amr-9/sayl/issues")
-			os.Exit(1)
+			os.Exit(exitError)
 		}
 	}()
 
 	// Use all available CPU cores for maximum performance
 	runtime.GOMAXPROCS(runtime.NumCPU())
 
+	// Subcommands that work on saved results instead of running a test.
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "report":
+			os.Exit(runReportCommand(os.Args[2:]))
+		case "diff":
+			os.Exit(runDiffCommand(os.Args[2:]))
+		}
+	}
+
 	// Setup graceful shutdown context
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Handle interrupt signals (Ctrl+C, SIGTERM)
-	sigChan := make(chan os.Signal, 1)
+	// Handle interrupt signals (Ctrl+C, SIGTERM). Cancelling ctx stops the engine;
+	// in-flight results are drained and a partial report is saved.
+	sigChan := make(chan os.Signal, 2)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		<-sigChan
-		fmt.Println("\n\n⚠️  Received interrupt signal, shutting down gracefully...")
+		fmt.Println("\n\n⚠️  Received interrupt signal, stopping the test and saving a partial report...")
 		cancel()
-		// Give a moment for cleanup
-		time.Sleep(500 * time.Millisecond)
+		// A second signal skips the drain and exits immediately.
+		<-sigChan
+		os.Exit(exitInterrupted)
 	}()
 
-	// Store context for TUI to use
-	_ = ctx // Will be passed to TUI in future enhancement
-
 	// Define command-line flags
 	var (
 		configPath  string
@@ -61,6 +86,24 @@ func main() {
 		concurrency int
 		successStr  string
 		debugMode   bool
+		noTUI       bool
+		quiet       bool
+		progressStr string
+		resultsPath string
+		resultsFmt  string
+		metricsAddr string
+		webAddr     string
+		controlAddr string
+		controlTok  string
+		otlpAddr    string
+		otlpProto   string
+		otlpTraces  bool
+		influxURL   string
+		statsdAddr  string
+		pctStr      string
+		embedJSON   bool
+		outDir      string
+		reportOuts  = defaultReportOutputs()
 	)
 
 	flag.StringVar(&configPath, "config", "", "Path to YAML configuration file")
@@ -73,6 +116,25 @@ func main() {
 	flag.StringVar(&successStr, "success", "", "Comma-separated list of success status codes (e.g., 200,201)")
 	flag.BoolVar(&debugMode, "debug", false, "Run in debug mode (single iteration with detailed output)")
 	flag.BoolVar(&debugMode, "d", false, "Run in debug mode (shorthand)")
+	flag.BoolVar(&noTUI, "no-tui", false, "Run without the TUI, printing plain progress lines (automatic when stdout is not a terminal)")
+	flag.BoolVar(&quiet, "quiet", false, "Run without the TUI and without progress lines; only the final summary is printed")
+	flag.BoolVar(&quiet, "q", false, "Quiet mode (shorthand)")
+	flag.StringVar(&progressStr, "progress-interval", "5s", "Interval between progress lines in headless mode")
+	flag.StringVar(&resultsPath, "results", "", "Write every request result to this file (e.g., results.jsonl)")
+	flag.StringVar(&resultsFmt, "results-format", "", "Format of the results file: jsonl, csv or bin (default: from the file extension)")
+	flag.StringVar(&metricsAddr, "metrics-addr", "", "Serve live Prometheus metrics on this address during the run (e.g., :9100)")
+	flag.StringVar(&webAddr, "web", "", "Serve a live dashboard in the browser on this address during the run (e.g., :8089)")
+	flag.StringVar(&controlAddr, "control-addr", "", "Serve the HTTP control API (pause, resume, rate, next stage) on this address (e.g., 127.0.0.1:6565)")
+	flag.StringVar(&controlTok, "control-token", "", "Require this token on the control API as 'Authorization: Bearer <token>'")
+	flag.StringVar(&otlpAddr, "otlp-endpoint", "", "Export metrics to this OpenTelemetry endpoint (e.g., localhost:4317 or http://collector:4318)")
+	flag.StringVar(&otlpProto, "otlp-protocol", "", "OTLP transport: grpc or http (default: grpc)")
+	flag.BoolVar(&otlpTraces, "otlp-traces", false, "Also export one span per request and propagate traceparent to the target")
+	flag.StringVar(&influxURL, "influxdb", "", "Stream per-second metrics to this InfluxDB write URL or udp://host:port")
+	flag.Var(reportOuts, "out", "Write a report as format=path, relative to the run folder; repeatable. Formats: json, html, junit, md")
+	flag.StringVar(&statsdAddr, "statsd", "", "Stream per-second metrics to this StatsD server (e.g., localhost:8125)")
+	flag.StringVar(&pctStr, "percentiles", "", "Comma-separated latency percentiles to report (e.g., 50,90,99,99.9,99.99)")
+	flag.BoolVar(&embedJSON, "embed-json", false, "Bundle the report JSON inside the HTML report")
+	flag.StringVar(&outDir, "out-dir", "", "Directory holding one timestamped folder per run (default: runs)")
 
 	flag.Parse()
 
@@ -83,7 +145,7 @@ func main() {
 		loadedCfg, err := config.LoadConfig(configPath)
 		if err != nil {
 			fmt.Printf("Error loading config file: %v\n", err)
-			os.Exit(1)
+			os.Exit(exitError)
 		}
 		cfg = loadedCfg
 	} else {
@@ -108,7 +170,7 @@ func main() {
 		d, err := time.ParseDuration(durationStr)
 		if err != nil {
 			fmt.Printf("Invalid duration flag: %v\n", err)
-			os.Exit(1)
+			os.Exit(exitError)
 		}
 		cfg.Duration = d
 	}
@@ -129,17 +191,64 @@ func main() {
 		}
 	}
 
+	if outDir != "" {
+		cfg.Output.Dir = outDir
+	}
+	if resultsPath != "" {
+		cfg.Output.Results = resultsPath
+	}
+	if resultsFmt != "" {
+		cfg.Output.ResultsFormat = strings.ToLower(resultsFmt)
+	}
+	if otlpAddr != "" || otlpProto != "" || otlpTraces {
+		if cfg.Output.OTLP == nil {
+			cfg.Output.OTLP = &models.OTLPConfig{}
+		}
+		if otlpAddr != "" {
+			cfg.Output.OTLP.Endpoint = otlpAddr
+			// A plain host:port on the command line is usually a local collector.
+			cfg.Output.OTLP.Insecure = cfg.Output.OTLP.Insecure || !strings.Contains(otlpAddr, "://")
+		}
+		if otlpProto != "" {
+			cfg.Output.OTLP.Protocol = strings.ToLower(otlpProto)
+		}
+		cfg.Output.OTLP.Traces = cfg.Output.OTLP.Traces || otlpTraces
+	}
+	if influxURL != "" {
+		if cfg.Output.InfluxDB == nil {
+			cfg.Output.InfluxDB = &models.InfluxDBConfig{}
+		}
+		cfg.Output.InfluxDB.URL = influxURL
+	}
+	if statsdAddr != "" {
+		if cfg.Output.StatsD == nil {
+			cfg.Output.StatsD = &models.StatsDConfig{}
+		}
+		cfg.Output.StatsD.Address = statsdAddr
+	}
+
+	if pctStr != "" {
+		ps, err := config.ParsePercentiles(pctStr)
+		if err != nil {
+			fmt.Printf("Invalid percentiles flag: %v\n", err)
+			os.Exit(exitError)
+		}
+		cfg.Percentiles = ps
+	}
+	cfg.EmbedJSON = cfg.EmbedJSON || embedJSON
+
 	// 3. Defaults are handled inside config.Validate or TUI Setup
 	// Check if we have enough info to run immediately (Skip Setup)
 	startRunning := false
-	if err := config.Validate(cfg); err == nil {
+	validationErr := config.Validate(cfg)
+	if validationErr == nil {
 		startRunning = true
 	} else {
 		// If a config file was explicitly provided but is invalid, we should report the error and exit
 		// instead of dropping into the TUI.
 		if configPath != "" {
-			fmt.Printf("Configuration Error: %v\n", err)
-			os.Exit(1)
+			fmt.Printf("Configuration Error: %v\n", validationErr)
+			os.Exit(exitError)
 		}
 		// Otherwise (no config file), fall back to TUI setup
 	}
@@ -149,7 +258,7 @@ func main() {
 		if !startRunning {
 			fmt.Println("❌ Debug mode requires a valid configuration.")
 			fmt.Println("💡 Please provide a config file: sayl -config scenario.yaml --debug")
-			os.Exit(1)
+			os.Exit(exitError)
 		}
 
 		// Set debug flag on config
@@ -157,36 +266,277 @@ func main() {
 
 		if err := debug.RunDebugMode(cfg); err != nil {
 			fmt.Printf("❌ Debug mode error: %v\n", err)
-			os.Exit(1)
+			os.Exit(exitError)
 		}
 		return // Exit after debug mode completes
 	}
 
-	p := tea.NewProgram(tui.NewModel(cfg, startRunning))
+	// Live Prometheus endpoint, attached to every run started below.
+	var observers []runner.Observer
+	if metricsAddr != "" {
+		srv, err := metrics.Listen(metricsAddr)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(exitError)
+		}
+		defer srv.Close()
+		observers = append(observers, srv)
+	}
+
+	// Control API, so scripts can pause the run or change its rate.
+	if controlAddr != "" {
+		srv, err := control.Listen(controlAddr, controlTok)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(exitError)
+		}
+		defer srv.Close()
+		if !srv.Loopback() {
+			fmt.Printf("⚠️  The control API on %s is reachable from other machines; anyone who can reach it can pause or retarget the test.\n", srv.Addr())
+			if controlTok == "" {
+				fmt.Println("💡 Bind it to 127.0.0.1 (--control-addr 127.0.0.1:6565) or set --control-token.")
+			}
+		}
+		observers = append(observers, srv)
+	}
+
+	// Live browser dashboard; it shows the final report once the run has ended.
+	var dashboard *web.Server
+	if webAddr != "" {
+		srv, err := web.Listen(webAddr)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(exitError)
+		}
+		defer srv.Close()
+		if !report.ChartJSInlined() {
+			fmt.Println("⚠️  Chart.js is not bundled in this build; the dashboard loads it from the CDN.")
+		}
+		observers = append(observers, srv)
+		dashboard = srv
+	}
+	finishDashboard := func(rep models.Report) {
+		if dashboard == nil {
+			return
+		}
+		if err := dashboard.Finish(rep, report.HTMLOptions{EmbedJSON: cfg.EmbedJSON}); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		}
+	}
+
+	// Exporters that push to external backends. os.Exit skips deferred calls, so
+	// the final metrics and buffered data are flushed explicitly after the run.
+	var flushers []func() error
+	if cfg.Output.OTLP != nil && cfg.Output.OTLP.Endpoint != "" {
+		exp, err := telemetry.New(ctx, cfg.Output.OTLP)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(exitError)
+		}
+		observers = append(observers, exp)
+		flushers = append(flushers, func() error {
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := exp.Shutdown(shutdownCtx); err != nil {
+				return fmt.Errorf("OpenTelemetry export failed: %w", err)
+			}
+			return nil
+		})
+	}
+	tags := outputs.RunTags(cfg.Name, scenarioName(configPath))
+	if cfg.Output.InfluxDB != nil {
+		stream, err := outputs.NewInfluxDB(cfg.Output.InfluxDB, tags)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(exitError)
+		}
+		observers = append(observers, stream)
+		flushers = append(flushers, stream.Close)
+	}
+	if cfg.Output.StatsD != nil {
+		stream, err := outputs.NewStatsD(cfg.Output.StatsD, tags)
+		if err != nil {
+			fmt.Printf("❌ %v\n", err)
+			os.Exit(exitError)
+		}
+		observers = append(observers, stream)
+		flushers = append(flushers, stream.Close)
+	}
+	flushOutputs := func() {
+		for _, flush := range flushers {
+			if err := flush(); err != nil {
+				fmt.Printf("⚠️  %v\n", err)
+			}
+		}
+	}
+
+	// 5. Headless Mode - no TUI when requested or when stdout is not a terminal (CI).
+	// Its arguments are checked before the run folder is created, so a rejected
+	// invocation leaves nothing behind.
+	headless := noTUI || quiet || !isTerminal(os.Stdout)
+	var interval time.Duration
+	if headless {
+		if !startRunning {
+			fmt.Println("❌ Headless mode requires a valid configuration (interactive setup needs a terminal).")
+			if validationErr != nil {
+				fmt.Printf("%v\n", validationErr)
+			}
+			fmt.Println("💡 Please provide a config file or flags: sayl -config scenario.yaml --no-tui")
+			os.Exit(exitError)
+		}
+
+		var err error
+		if interval, err = time.ParseDuration(progressStr); err != nil || interval <= 0 {
+			fmt.Printf("Invalid progress-interval flag: %q\n", progressStr)
+			os.Exit(exitError)
+		}
+	}
+
+	// Every run writes into its own folder: reports, raw results and config.
+	base := cfg.Output.Dir
+	if base == "" {
+		base = defaultRunsDir
+	}
+	folder, err := createRunFolder(base, runName(cfg, configPath), time.Now())
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		os.Exit(exitError)
+	}
+	for format, path := range reportOuts {
+		reportOuts[format] = folder.resolve(path)
+	}
+	cfg.Output.Results = folder.resolve(cfg.Output.Results)
+
+	if headless {
+		if metricsAddr != "" && !quiet {
+			fmt.Printf("📡 Prometheus metrics on %s/metrics\n", localURL(metricsAddr))
+		}
+		if webAddr != "" && !quiet {
+			fmt.Printf("🌐 Live dashboard on %s/\n", localURL(webAddr))
+		}
+		if controlAddr != "" && !quiet {
+			fmt.Printf("🎛️  Control API on %s/status\n", localURL(controlAddr))
+		}
+		rep := runHeadless(ctx, cfg, interval, quiet, observers...)
+		flushOutputs()
+		if rep.TotalRequests > 0 {
+			writeReports(rep, reportOuts, report.HTMLOptions{EmbedJSON: cfg.EmbedJSON})
+			folder.finish(rep)
+			finishDashboard(rep)
+		} else {
+			folder.discard()
+		}
+		os.Exit(exitCode(rep))
+	}
+
+	// Printed before the TUI takes over the screen; they stay in the scrollback.
+	if webAddr != "" {
+		fmt.Printf("🌐 Live dashboard on %s/\n", localURL(webAddr))
+	}
+	if controlAddr != "" {
+		fmt.Printf("🎛️  Control API on %s/status\n", localURL(controlAddr))
+	}
+
+	// Signals are handled above so a run can drain before the program exits;
+	// Ctrl+C inside the TUI arrives as a key press and is handled by the model.
+	p := tea.NewProgram(tui.NewModel(ctx, cfg, startRunning, observers...), tea.WithoutSignalHandler())
 	m, err := p.Run()
+	flushOutputs()
 	if err != nil {
 		fmt.Printf("Error running program: %v\n", err)
-		os.Exit(1)
+		folder.discard()
+		os.Exit(exitError)
 	}
 
 	if finalModel, ok := m.(tui.MainModel); ok {
 		// Only save if we actually ran a test
 		if finalModel.Report().TotalRequests > 0 {
-			rep := finalModel.Report()
+			writeReports(finalModel.Report(), reportOuts, report.HTMLOptions{EmbedJSON: cfg.EmbedJSON})
+			folder.finish(finalModel.Report())
+			finishDashboard(finalModel.Report())
+		} else {
+			folder.discard()
+		}
+		os.Exit(exitCode(finalModel.Report()))
+	}
+}
 
-			// Print console summary
-			report.PrintConsoleReport(rep)
+// scenarioName returns the scenario file name without directory and extension.
+func scenarioName(configPath string) string {
+	if configPath == "" {
+		return ""
+	}
+	base := filepath.Base(configPath)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
 
-			// Save JSON report
-			saveReport("report.json", rep)
-			fmt.Println("\n📊 Report saved to report.json")
+// localURL returns the http URL of a listen address, with "localhost" for an
+// address that names no host (":9100").
+func localURL(addr string) string {
+	if strings.HasPrefix(addr, ":") {
+		addr = "localhost" + addr
+	}
+	return "http://" + addr
+}
 
-			// Generate HTML report with charts
-			if err := report.GenerateHTML(rep, "report.html"); err != nil {
-				fmt.Printf("⚠️  Failed to generate HTML report: %v\n", err)
-			} else {
-				fmt.Println("📈 Interactive HTML report saved to report.html")
-			}
+// exitCode maps the outcome of a run to the process exit code.
+func exitCode(rep models.Report) int {
+	if rep.Interrupted {
+		return exitInterrupted
+	}
+	if !threshold.Passed(rep.Thresholds) {
+		return exitThresholdsFailed
+	}
+	return exitOK
+}
+
+// writeReports prints the console summary and writes the selected report files.
+func writeReports(rep models.Report, outs reportOutputs, htmlOpts report.HTMLOptions) {
+	// Print console summary
+	report.PrintConsoleReport(rep)
+	fmt.Println()
+
+	if path, ok := outs[outJSON]; ok {
+		if err := saveReport(path, rep); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		} else {
+			fmt.Printf("📊 Report saved to %s\n", path)
+		}
+	}
+
+	// Generate HTML report with charts
+	if path, ok := outs[outHTML]; ok {
+		if err := report.GenerateHTML(rep, path, htmlOpts); err != nil {
+			fmt.Printf("⚠️  Failed to generate HTML report: %v\n", err)
+		} else {
+			fmt.Printf("📈 Interactive HTML report saved to %s\n", path)
+		}
+	}
+
+	if path, ok := outs[outJUnit]; ok {
+		if err := report.GenerateJUnit(rep, path); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		} else {
+			fmt.Printf("🧪 JUnit report saved to %s\n", path)
+		}
+	}
+
+	if path, ok := outs[outMD]; ok {
+		if err := report.GenerateMarkdown(rep, path); err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		} else {
+			fmt.Printf("📝 Markdown summary saved to %s\n", path)
+		}
+	}
+
+	if rl := rep.ResultsLog; rl != nil {
+		switch {
+		case rl.Error != "":
+			fmt.Printf("⚠️  Raw results: %s\n", rl.Error)
+		case rl.Dropped > 0:
+			fmt.Printf("🗂️  Raw results saved to %s (%d records, %d dropped because the disk could not keep up)\n", rl.Path, rl.Records, rl.Dropped)
+		default:
+			fmt.Printf("🗂️  Raw results saved to %s (%d records, %s)\n", rl.Path, rl.Records, rl.Format)
 		}
 	}
 }
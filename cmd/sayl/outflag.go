@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Report formats selectable with --out.
+const (
+	outJSON  = "json"
+	outHTML  = "html"
+	outJUnit = "junit"
+	outMD    = "md"
+)
+
+var outFormats = []string{outJSON, outHTML, outJUnit, outMD}
+
+// reportOutputs maps a report format to its output path. It implements flag.Value,
+// so --out can be repeated: --out junit=results.xml --out md=summary.md.
+type reportOutputs map[string]string
+
+func defaultReportOutputs() reportOutputs {
+	return reportOutputs{outJSON: "report.json", outHTML: "report.html"}
+}
+
+func (o reportOutputs) String() string {
+	parts := make([]string, 0, len(o))
+	for _, format := range outFormats {
+		if path, ok := o[format]; ok {
+			parts = append(parts, format+"="+path)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func (o reportOutputs) Set(value string) error {
+	format, path, ok := strings.Cut(value, "=")
+	format = strings.ToLower(strings.TrimSpace(format))
+	if !ok || path == "" {
+		return fmt.Errorf("expected format=path (e.g., junit=results.xml), got %q", value)
+	}
+	for _, f := range outFormats {
+		if f == format {
+			o[format] = path
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown report format %q (use %s)", format, strings.Join(outFormats, ", "))
+}
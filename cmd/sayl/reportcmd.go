@@ -0,0 +1,128 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Amr-9/sayl/internal/report"
+	"github.com/Amr-9/sayl/internal/results"
+	"github.com/Amr-9/sayl/pkg/config"
+)
+
+// runReportCommand implements `sayl report`: it rebuilds report.json and
+// report.html from a raw results file without sending any load.
+func runReportCommand(args []string) int {
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: sayl report -in results.jsonl [-from 60s] [-to 300s] [-step checkout] [-bucket 10s]")
+		fs.PrintDefaults()
+	}
+
+	var (
+		in       string
+		fromStr  string
+		toStr    string
+		step     string
+		bucket   string
+		jsonPath string
+		htmlPath string
+		pctStr   string
+		embed    bool
+	)
+	fs.StringVar(&in, "in", "", "Raw results file written with --results (jsonl, csv or bin)")
+	fs.StringVar(&fromStr, "from", "", "Only use requests started at least this long after the first request (e.g., 60s)")
+	fs.StringVar(&toStr, "to", "", "Only use requests started before this offset from the first request (e.g., 300s)")
+	fs.StringVar(&step, "step", "", "Only use requests of this scenario step")
+	fs.StringVar(&bucket, "bucket", "1s", "Time series resolution, in whole seconds (e.g., 10s)")
+	fs.StringVar(&jsonPath, "json", "report.json", "Output path of the JSON report")
+	fs.StringVar(&htmlPath, "html", "report.html", "Output path of the HTML report")
+	fs.StringVar(&pctStr, "percentiles", "", "Comma-separated latency percentiles to report (e.g., 50,90,99,99.9; default: 50,75,90,95,99)")
+	fs.BoolVar(&embed, "embed-json", false, "Bundle the report JSON inside the HTML report")
+
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if in == "" {
+		fmt.Println("❌ Missing input file.")
+		fmt.Println("💡 Usage: sayl report -in results.jsonl [-from 60s] [-to 300s] [-step checkout]")
+		return exitError
+	}
+
+	var filter results.Filter
+	for _, opt := range []struct {
+		name string
+		raw  string
+		dst  *time.Duration
+	}{
+		{"from", fromStr, &filter.From},
+		{"to", toStr, &filter.To},
+	} {
+		if opt.raw == "" {
+			continue
+		}
+		d, err := time.ParseDuration(opt.raw)
+		if err != nil || d < 0 {
+			fmt.Printf("Invalid -%s flag: %q (use a duration such as 60s or 5m)\n", opt.name, opt.raw)
+			return exitError
+		}
+		*opt.dst = d
+	}
+	if filter.To > 0 && filter.To <= filter.From {
+		fmt.Printf("Invalid window: -to (%s) must be after -from (%s)\n", filter.To, filter.From)
+		return exitError
+	}
+	filter.Step = step
+
+	resolution, err := time.ParseDuration(bucket)
+	if err != nil || resolution < time.Second || resolution%time.Second != 0 {
+		fmt.Printf("Invalid -bucket flag: %q (use whole seconds, e.g. 1s, 10s or 1m)\n", bucket)
+		return exitError
+	}
+
+	percentiles, err := config.ParsePercentiles(pctStr)
+	if err != nil {
+		fmt.Printf("Invalid -percentiles flag: %v\n", err)
+		return exitError
+	}
+
+	rep, err := results.Replay(in, filter, resolution, percentiles)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return exitError
+	}
+
+	// There is no target in a results file, so describe where the numbers came from.
+	var scope []string
+	if step != "" {
+		scope = append(scope, "step "+step)
+	}
+	if fromStr != "" || toStr != "" {
+		to := "end"
+		if filter.To > 0 {
+			to = filter.To.String()
+		}
+		scope = append(scope, fmt.Sprintf("%s–%s", filter.From, to))
+	}
+	rep.TargetURL = in
+	if len(scope) > 0 {
+		rep.TargetURL += " (" + strings.Join(scope, ", ") + ")"
+	}
+
+	report.PrintConsoleReport(rep)
+
+	if err := saveReport(jsonPath, rep); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+		return exitError
+	}
+	fmt.Printf("\n📊 Report saved to %s\n", jsonPath)
+
+	if err := report.GenerateHTML(rep, htmlPath, report.HTMLOptions{EmbedJSON: embed}); err != nil {
+		fmt.Printf("⚠️  Failed to generate HTML report: %v\n", err)
+		return exitError
+	}
+	fmt.Printf("📈 Interactive HTML report saved to %s\n", htmlPath)
+
+	return exitOK
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// defaultRunsDir is the parent of the run folders when neither --out-dir nor
+// output.dir is set.
+const defaultRunsDir = "runs"
+
+// symlink creates the latest pointer; tests replace it to exercise the fallback.
+var symlink = os.Symlink
+
+// runFolder keeps the files of one run in <base>/<timestamp>-<name>, so a run
+// never overwrites the reports or results of an earlier one. <base>/latest
+// points at the folder of the last finished run.
+type runFolder struct {
+	base string
+	path string
+}
+
+// createRunFolder creates the folder of a run started at now. Two runs started
+// within the same second get distinct folders.
+func createRunFolder(base, name string, now time.Time) (*runFolder, error) {
+	if err := os.MkdirAll(base, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory '%s': %w", base, err)
+	}
+	stem := now.Format("20060102-150405") + "-" + name
+	for i := 1; ; i++ {
+		path := filepath.Join(base, stem)
+		if i > 1 {
+			path += "-" + strconv.Itoa(i)
+		}
+		err := os.Mkdir(path, 0755)
+		if err == nil {
+			return &runFolder{base: base, path: path}, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("failed to create run folder '%s': %w", path, err)
+		}
+	}
+}
+
+// resolve places a relative output path inside the run folder, creating its
+// parent directories. Absolute paths are kept as given.
+func (f *runFolder) resolve(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	path = filepath.Join(f.path, path)
+	_ = os.MkdirAll(filepath.Dir(path), 0755)
+	return path
+}
+
+// finish saves the resolved configuration, secrets redacted, next to the reports and points the
+// latest pointer at the folder.
+func (f *runFolder) finish(rep models.Report) {
+	if rep.Config != "" {
+		if err := os.WriteFile(filepath.Join(f.path, "config.yaml"), []byte(rep.Config), 0644); err != nil {
+			fmt.Printf("⚠️  Failed to save the run configuration: %v\n", err)
+		}
+	}
+	if err := f.pointLatest(); err != nil {
+		fmt.Printf("⚠️  Failed to update %s: %v\n", filepath.Join(f.base, "latest"), err)
+	}
+	fmt.Printf("📁 Run saved to %s\n", f.path)
+}
+
+// pointLatest makes <base>/latest a symlink to the run folder. Where symlinks are
+// not available (Windows without developer mode), latest is a file holding the
+// folder name instead.
+func (f *runFolder) pointLatest() error {
+	link := filepath.Join(f.base, "latest")
+	if info, err := os.Lstat(link); err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("'%s' is a directory", link)
+		}
+		if err := os.Remove(link); err != nil {
+			return err
+		}
+	}
+	name := filepath.Base(f.path)
+	if err := symlink(name, link); err == nil {
+		return nil
+	}
+	return os.WriteFile(link, []byte(name+"\n"), 0644)
+}
+
+// discard removes the folder of a run that wrote nothing. A folder that is not
+// empty, e.g. holding a results file, is kept.
+func (f *runFolder) discard() {
+	_ = os.Remove(f.path)
+}
+
+// runName names a run folder after the config's name, the scenario file or the
+// target host, keeping only characters that are safe in file names.
+func runName(cfg *models.Config, configPath string) string {
+	name := cfg.Name
+	if name == "" {
+		name = scenarioName(configPath)
+	}
+	if name == "" {
+		if u, err := url.Parse(cfg.URL); err == nil {
+			name = u.Hostname()
+		}
+	}
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		}
+		return '-'
+	}, name)
+	if len(name) > 40 {
+		name = name[:40]
+	}
+	if name = strings.Trim(name, "-."); name == "" {
+		return "run"
+	}
+	return name
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+func TestRunName(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfgName    string
+		configPath string
+		url        string
+		want       string
+	}{
+		{"config name", "Checkout API v2", "scenarios/login.yaml", "https://api.example.com", "Checkout-API-v2"},
+		{"scenario file", "", "scenarios/login.flow.yaml", "https://api.example.com", "login.flow"},
+		{"target host", "", "", "https://api.example.com:8443/orders", "api.example.com"},
+		{"path separators", "../../etc/passwd", "", "", "etc-passwd"},
+		{"unicode", "تحميل ✓ test", "", "", "test"},
+		{"truncated", strings.Repeat("a", 50), "", "", strings.Repeat("a", 40)},
+		{"nothing usable", "", "", "not a url", "run"},
+		{"only separators", "/// ...", "", "", "run"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &models.Config{Name: tt.cfgName, URL: tt.url}
+			if got := runName(cfg, tt.configPath); got != tt.want {
+				t.Fatalf("runName = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateRunFolder(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "runs", "nested")
+	now := time.Date(2026, 1, 18, 14, 25, 1, 0, time.Local)
+
+	var got []string
+	for range 3 {
+		f, err := createRunFolder(base, "checkout", now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, filepath.Base(f.path))
+	}
+	// Runs started within the same second get distinct folders.
+	want := []string{"20260118-142501-checkout", "20260118-142501-checkout-2", "20260118-142501-checkout-3"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Fatalf("folders %v, want %v", got, want)
+	}
+	for _, name := range want {
+		if info, err := os.Stat(filepath.Join(base, name)); err != nil || !info.IsDir() {
+			t.Fatalf("%s was not created: %v", name, err)
+		}
+	}
+}
+
+func TestRunFolderResolve(t *testing.T) {
+	f, err := createRunFolder(t.TempDir(), "run", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := f.resolve(""); got != "" {
+		t.Errorf("empty path resolved to %q", got)
+	}
+	abs := filepath.Join(t.TempDir(), "results.jsonl")
+	if got := f.resolve(abs); got != abs {
+		t.Errorf("absolute path resolved to %q", got)
+	}
+	got := f.resolve(filepath.Join("ci", "junit.xml"))
+	if got != filepath.Join(f.path, "ci", "junit.xml") {
+		t.Errorf("relative path resolved to %q", got)
+	}
+	if info, err := os.Stat(filepath.Dir(got)); err != nil || !info.IsDir() {
+		t.Errorf("parent of %s was not created: %v", got, err)
+	}
+}
+
+// readLatest returns the folder name that base/latest points at, and whether it
+// is a symlink.
+func readLatest(t *testing.T, base string) (string, bool) {
+	t.Helper()
+	link := filepath.Join(base, "latest")
+	if target, err := os.Readlink(link); err == nil {
+		return target, true
+	}
+	data, err := os.ReadFile(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(data)), false
+}
+
+func TestPointLatest(t *testing.T) {
+	base := t.TempDir()
+	now := time.Now()
+	first, _ := createRunFolder(base, "first", now)
+	second, _ := createRunFolder(base, "second", now)
+
+	if err := first.pointLatest(); err != nil {
+		t.Fatal(err)
+	}
+	if target, isLink := readLatest(t, base); !isLink || target != filepath.Base(first.path) {
+		t.Fatalf("latest -> %q (symlink %v), want %q", target, isLink, filepath.Base(first.path))
+	}
+	// The symlink is relative, so it still resolves once base is moved or archived.
+	if _, err := os.Stat(filepath.Join(base, "latest")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := second.pointLatest(); err != nil {
+		t.Fatal(err)
+	}
+	if target, _ := readLatest(t, base); target != filepath.Base(second.path) {
+		t.Fatalf("latest -> %q after the second run, want %q", target, filepath.Base(second.path))
+	}
+}
+
+func TestPointLatestFallback(t *testing.T) {
+	symlink = func(string, string) error { return errors.New("symlinks not supported") }
+	t.Cleanup(func() { symlink = os.Symlink })
+
+	base := t.TempDir()
+	now := time.Now()
+	first, _ := createRunFolder(base, "first", now)
+	second, _ := createRunFolder(base, "second", now)
+	for _, f := range []*runFolder{first, second} {
+		if err := f.pointLatest(); err != nil {
+			t.Fatal(err)
+		}
+		if target, isLink := readLatest(t, base); isLink || target != filepath.Base(f.path) {
+			t.Fatalf("latest -> %q (symlink %v), want a file naming %q", target, isLink, filepath.Base(f.path))
+		}
+	}
+
+	// An existing latest symlink is replaced by the file too.
+	symlink = os.Symlink
+	if err := first.pointLatest(); err != nil {
+		t.Fatal(err)
+	}
+	symlink = func(string, string) error { return errors.New("symlinks not supported") }
+	if err := second.pointLatest(); err != nil {
+		t.Fatal(err)
+	}
+	if target, isLink := readLatest(t, base); isLink || target != filepath.Base(second.path) {
+		t.Fatalf("latest -> %q (symlink %v), want a file naming %q", target, isLink, filepath.Base(second.path))
+	}
+}
+
+func TestPointLatestDirectory(t *testing.T) {
+	base := t.TempDir()
+	if err := os.Mkdir(filepath.Join(base, "latest"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, _ := createRunFolder(base, "run", time.Now())
+	if err := f.pointLatest(); err == nil {
+		t.Fatal("a latest directory was overwritten")
+	}
+}
+
+func TestRunFolderFinish(t *testing.T) {
+	base := t.TempDir()
+	f, _ := createRunFolder(base, "run", time.Now())
+	f.finish(models.Report{Config: "load:\n  rate: 20\n"})
+
+	data, err := os.ReadFile(filepath.Join(f.path, "config.yaml"))
+	if err != nil || string(data) != "load:\n  rate: 20\n" {
+		t.Fatalf("config.yaml = %q, %v", data, err)
+	}
+	if target, _ := readLatest(t, base); target != filepath.Base(f.path) {
+		t.Fatalf("latest -> %q, want %q", target, filepath.Base(f.path))
+	}
+}
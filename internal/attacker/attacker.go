@@ -1,8 +1,10 @@
 package attacker
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"math/rand/v2"
@@ -10,11 +12,16 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Amr-9/sayl/internal/validator"
 	"github.com/Amr-9/sayl/pkg/models"
 	"github.com/tidwall/gjson"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/net/http2"
 	"golang.org/x/time/rate"
 )
@@ -27,10 +34,23 @@ type RetryConfig struct {
 
 // Engine implements the load testing logic
 type Engine struct {
-	client      *http.Client
+	client       *http.Client
+	streamClient *http.Client // Client of SSE steps: same transport, no whole-request timeout
 	vp          *VariableProcessor
 	retry       RetryConfig
 	sessionPool *sync.Pool
+
+	compressRequest string // Content-Encoding applied to request bodies ("" = none)
+	acceptEncoding  string // Accept-Encoding sent with every request
+
+	tracer trace.Tracer // Records iteration and step spans (nil = tracing disabled)
+
+	// Live state, read by the metrics endpoint while Attack runs.
+	inFlight atomic.Int64                 // requests currently being executed
+	limiter  atomic.Pointer[rate.Limiter] // nil until Attack has set up the limiter
+
+	// Runtime control: pause, rate adjustments and stage skips (see control.go).
+	ctl control
 }
 
 // DefaultRetryConfig returns reasonable defaults for retries
@@ -45,6 +65,7 @@ func NewEngine() *Engine {
 	return &Engine{
 		vp:    NewVariableProcessor(),
 		retry: DefaultRetryConfig(),
+		ctl:   control{changed: make(chan struct{}, 1)},
 		sessionPool: &sync.Pool{
 			New: func() any {
 				return make(map[string]string)
@@ -128,6 +149,11 @@ func isRetryableError(err error) bool {
 	if err == nil {
 		return false
 	}
+	// The server answered; retrying would only repeat the same GraphQL error.
+	var gqlErr *GraphQLError
+	if errors.As(err, &gqlErr) {
+		return false
+	}
 	errStr := strings.ToLower(err.Error()) // lowercase once, not per pattern
 	for _, pattern := range retryablePatterns {
 		if strings.Contains(errStr, pattern) {
@@ -150,9 +176,9 @@ func (e *Engine) Attack(ctx context.Context, cfg models.Config, results chan<- m
 	if cfg.H2C {
 		// HTTP/2 Cleartext (h2c) - for non-TLS HTTP/2 testing
 		roundTripper = &http2.Transport{
-			AllowHTTP:        true,
-			MaxHeaderListSize: 16 * 1024,       // reject unexpectedly large response headers
-			WriteByteTimeout: 10 * time.Second, // prevent stalled connections from blocking workers
+			AllowHTTP:         true,
+			MaxHeaderListSize: 16 * 1024,        // reject unexpectedly large response headers
+			WriteByteTimeout:  10 * time.Second, // prevent stalled connections from blocking workers
 			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
 				// For h2c, we dial plain TCP (no TLS)
 				return (&net.Dialer{
@@ -197,6 +223,13 @@ func (e *Engine) Attack(ctx context.Context, cfg models.Config, results chan<- m
 	if e.client.Timeout == 0 {
 		e.client.Timeout = 30 * time.Second
 	}
+	e.streamClient = &http.Client{Transport: roundTripper}
+
+	e.compressRequest = cfg.CompressRequest
+	e.acceptEncoding = cfg.AcceptEncoding
+	if e.acceptEncoding == "" {
+		e.acceptEncoding = DefaultAcceptEncoding
+	}
 
 	// Pre-warm connections to avoid cold-start latency spikes in the first seconds.
 	targetURL := cfg.URL
@@ -244,10 +277,12 @@ func (e *Engine) Attack(ctx context.Context, cfg models.Config, results chan<- m
 		initialLimit = rate.Limit(cfg.Rate)
 	}
 	limiter := rate.NewLimiter(initialLimit, 1)
+	e.limiter.Store(limiter)
+	e.ctl.begin(cfg, limiter)
 
 	// Stage Controller
 	if len(cfg.Stages) > 0 {
-		go e.runStages(ctx, cfg.Stages, limiter)
+		go e.runStages(ctx, cfg.Stages)
 	}
 
 	// Prepare steps
@@ -255,11 +290,14 @@ func (e *Engine) Attack(ctx context.Context, cfg models.Config, results chan<- m
 	if len(steps) == 0 {
 		// Create a single step from the main config
 		steps = []models.Step{{
-			Name:    "Main",
-			URL:     cfg.URL,
-			Method:  cfg.Method,
-			Headers: cfg.Headers,
-			Body:    string(cfg.Body),
+			Name:       "Main",
+			URL:        cfg.URL,
+			Method:     cfg.Method,
+			Headers:    cfg.Headers,
+			Body:       string(cfg.Body),
+			GraphQL:    cfg.GraphQL,
+			Multipart:  cfg.Multipart,
+			BodyStream: cfg.BodyStream,
 		}}
 	}
 
@@ -278,6 +316,39 @@ func (e *Engine) Attack(ctx context.Context, cfg models.Config, results chan<- m
 		for k, v := range step.Variables {
 			cs.vars[k] = CompileTemplate(v)
 		}
+		if step.Multipart != nil {
+			mp, err := CompileMultipart(step.Multipart)
+			if err != nil {
+				results <- models.Result{
+					Timestamp: time.Now(),
+					Error:     fmt.Errorf("step '%s': %v", step.Name, err),
+					StepName:  step.Name,
+				}
+				close(results)
+				return
+			}
+			defer mp.Close()
+			cs.multipart = mp
+		}
+		if step.BodyStream != nil {
+			sb, err := CompileBodyStream(step.BodyStream)
+			if err != nil {
+				results <- models.Result{
+					Timestamp: time.Now(),
+					Error:     fmt.Errorf("step '%s': %v", step.Name, err),
+					StepName:  step.Name,
+				}
+				close(results)
+				return
+			}
+			defer sb.Close()
+			cs.stream = sb
+		}
+		if e.compressRequest != "" && cs.body.IsStatic() && step.Body != "" {
+			if b, err := CompressBody(e.compressRequest, []byte(step.Body)); err == nil {
+				cs.compressedBody = b
+			}
+		}
 		compiled[i] = cs
 	}
 
@@ -287,7 +358,10 @@ func (e *Engine) Attack(ctx context.Context, cfg models.Config, results chan<- m
 		go func() {
 			defer wg.Done()
 			for {
-				// Wait for rate limit permission
+				// Hold while the test is paused, then wait for rate limit permission
+				if err := e.ctl.wait(ctx); err != nil {
+					return // Context cancelled
+				}
 				if err := limiter.Wait(ctx); err != nil {
 					return // Context cancelled
 				}
@@ -308,9 +382,29 @@ func (e *Engine) Attack(ctx context.Context, cfg models.Config, results chan<- m
 						}
 					}
 
+					// Each iteration is a trace; its steps are child spans whose
+					// context is propagated to the target via traceparent.
+					iterCtx := ctx
+					var iterSpan trace.Span
+					if e.tracer != nil {
+						iterCtx, iterSpan = e.tracer.Start(ctx, "iteration")
+					}
+
 					// Execute scenario steps using pre-compiled templates
 					for j, step := range steps {
-						result := e.executeCompiledStepWithRetry(ctx, step, compiled[j], session)
+						stepCtx := iterCtx
+						var stepSpan trace.Span
+						if e.tracer != nil {
+							stepCtx, stepSpan = e.tracer.Start(iterCtx, step.Name, trace.WithSpanKind(trace.SpanKindClient))
+						}
+
+						e.inFlight.Add(1)
+						result := e.executeCompiledStepWithRetry(stepCtx, step, compiled[j], session)
+						e.inFlight.Add(-1)
+
+						if stepSpan != nil {
+							endStepSpan(stepSpan, step, result)
+						}
 
 						// Send result
 						select {
@@ -318,6 +412,9 @@ func (e *Engine) Attack(ctx context.Context, cfg models.Config, results chan<- m
 						case <-ctx.Done():
 							// Ensure we return the map even if cancelled here
 							e.sessionPool.Put(session)
+							if iterSpan != nil {
+								iterSpan.End()
+							}
 							return
 						}
 
@@ -327,6 +424,10 @@ func (e *Engine) Attack(ctx context.Context, cfg models.Config, results chan<- m
 						}
 					}
 
+					if iterSpan != nil {
+						iterSpan.End()
+					}
+
 					// Return map to pool for reuse
 					e.sessionPool.Put(session)
 				}
@@ -338,38 +439,47 @@ func (e *Engine) Attack(ctx context.Context, cfg models.Config, results chan<- m
 	close(results)
 }
 
-func (e *Engine) runStages(ctx context.Context, stages []models.Stage, limiter *rate.Limiter) {
-	for _, stage := range stages {
-		startLimit := float64(limiter.Limit())
-		targetLimit := float64(stage.Target)
-		if targetLimit == 0 {
-			targetLimit = 1
-		}
-		duration := stage.Duration
-		ticker := time.NewTicker(100 * time.Millisecond)
-		startTime := time.Now()
+// SetTracer enables tracing: every scenario iteration becomes a span with one
+// child span per step, and requests carry a W3C traceparent header.
+// It must be called before Attack.
+func (e *Engine) SetTracer(t trace.Tracer) {
+	e.tracer = t
+}
 
-		for {
-			select {
-			case <-ctx.Done():
-				ticker.Stop()
-				return
-			case t := <-ticker.C:
-				elapsed := t.Sub(startTime)
-				if elapsed >= duration {
-					limiter.SetLimit(rate.Limit(targetLimit))
-					goto NextStage
-				}
-				progress := float64(elapsed) / float64(duration)
-				currentRate := startLimit + (targetLimit-startLimit)*progress
-				limiter.SetLimit(rate.Limit(currentRate))
-			}
-		}
-	NextStage:
-		ticker.Stop()
-	}
+// endStepSpan records the outcome of a step on its span and ends it.
+func endStepSpan(span trace.Span, step models.Step, res models.Result) {
+	span.SetAttributes(
+		attribute.String("http.request.method", step.Method),
+		attribute.Int("http.response.status_code", res.Status),
+	)
+	if res.Protocol != "" {
+		span.SetAttributes(attribute.String("network.protocol.version", strings.TrimPrefix(res.Protocol, "HTTP/")))
+	}
+	switch {
+	case res.Error != nil:
+		span.RecordError(res.Error)
+		span.SetStatus(codes.Error, res.Error.Error())
+	case res.AssertionError != nil:
+		span.SetStatus(codes.Error, res.AssertionError.Error())
+	case res.Status >= 400:
+		span.SetStatus(codes.Error, http.StatusText(res.Status))
+	}
+	span.End()
 }
 
+// InFlight returns the number of requests currently being executed.
+func (e *Engine) InFlight() int64 {
+	return e.inFlight.Load()
+}
+
+// TargetRate returns the current rate limit in requests per second,
+// which follows the stages while ramping (0 before Attack has started).
+func (e *Engine) TargetRate() float64 {
+	if l := e.limiter.Load(); l != nil {
+		return float64(l.Limit())
+	}
+	return 0
+}
 
 // executeCompiledStep is identical to executeStep but uses pre-compiled templates
 // to avoid repeated string scanning on every request.
@@ -384,21 +494,74 @@ func (e *Engine) executeCompiledStep(ctx context.Context, step models.Step, cs c
 	// 1. Process Templates via compiled parts (no scanning overhead)
 	url := cs.url.Execute(e.vp, session)
 	method := step.Method
-	bodyStr := cs.body.Execute(e.vp, session)
 
-	req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(bodyStr))
+	var body io.Reader
+	var contentType, contentEncoding string
+	contentLength := int64(-1)
+	switch {
+	case cs.stream != nil:
+		body, contentLength = cs.stream.Reader()
+	case cs.multipart != nil:
+		body, contentLength, contentType = cs.multipart.Build(e.vp, session)
+	case cs.compressedBody != nil:
+		body = bytes.NewReader(cs.compressedBody)
+		contentEncoding = e.compressRequest
+	case e.compressRequest != "":
+		bodyStr := cs.body.Execute(e.vp, session)
+		if bodyStr == "" {
+			body = strings.NewReader("")
+			break
+		}
+		compressed, cerr := CompressBody(e.compressRequest, []byte(bodyStr))
+		if cerr != nil {
+			return models.Result{Timestamp: start, Latency: time.Since(start), Error: cerr, StepName: step.Name}
+		}
+		body = bytes.NewReader(compressed)
+		contentEncoding = e.compressRequest
+	default:
+		body = strings.NewReader(cs.body.Execute(e.vp, session))
+	}
+
+	client := e.client
+	if step.Stream == models.StreamSSE {
+		var stop context.CancelFunc
+		ctx, stop = e.streamContext(ctx, step.SSE)
+		defer stop()
+		client = e.streamClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return models.Result{Timestamp: start, Latency: time.Since(start), Error: err, StepName: step.Name}
 	}
+	if contentLength >= 0 {
+		req.ContentLength = contentLength
+	}
 
 	req.Header.Set("User-Agent", "Sayl/1.0")
 	req.Header.Set("Accept", "*/*")
+	req.Header.Set("Accept-Encoding", e.acceptEncoding)
+	if e.tracer != nil {
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("url.full", url))
+		propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	}
+	if step.Stream == models.StreamSSE {
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("Cache-Control", "no-cache")
+	}
 	for k, ct := range cs.headers {
 		req.Header.Set(k, ct.Execute(e.vp, session))
 	}
+	if contentType != "" {
+		// Must carry this request's boundary, so it overrides any configured value.
+		req.Header.Set("Content-Type", contentType)
+	}
+	if contentEncoding != "" {
+		req.Header.Set("Content-Encoding", contentEncoding)
+	}
 
 	// 2. Execute Request
-	resp, err := e.client.Do(req)
+	resp, err := client.Do(req)
 	latency := time.Since(start)
 	if err != nil {
 		return models.Result{Timestamp: start, Latency: latency, Error: err, StepName: step.Name, Protocol: ""}
@@ -407,15 +570,23 @@ func (e *Engine) executeCompiledStep(ctx context.Context, step models.Step, cs c
 
 	protocol := resp.Proto
 
-	// 3. Read Body
+	if step.Stream == models.StreamSSE && resp.StatusCode < 300 {
+		return e.consumeSSE(resp, step, session, start)
+	}
+
+	// 3. Read Body (decoding Content-Encoding ourselves to count wire vs decoded bytes)
+	wire := &countingReader{r: resp.Body}
+	decoded, release := DecodeBody(resp.Header.Get("Content-Encoding"), wire)
+	defer release()
+
 	var bodyBytes []byte
 	var written int64
-	needBody := len(step.Extract) > 0 || len(step.Assertions) > 0
+	needBody := len(step.Extract) > 0 || len(step.Assertions) > 0 || step.GraphQL != nil
 	if needBody {
-		bodyBytes, err = io.ReadAll(resp.Body)
+		bodyBytes, err = io.ReadAll(decoded)
 		written = int64(len(bodyBytes))
 	} else {
-		written, _ = io.Copy(io.Discard, resp.Body)
+		written, err = io.Copy(io.Discard, decoded)
 	}
 
 	// 4. Extract Variables
@@ -440,17 +611,132 @@ func (e *Engine) executeCompiledStep(ctx context.Context, step models.Step, cs c
 		assertionErr = validator.ValidateAssertions(bodyBytes, step.Assertions)
 	}
 
+	// 6. Detect GraphQL errors (reported with HTTP 200 by most servers)
+	var resultErr error
+	switch {
+	case err != nil:
+		resultErr = &models.BodyReadError{Err: err}
+	case step.GraphQL != nil:
+		resultErr = CheckGraphQLErrors(bodyBytes)
+	}
+
 	return models.Result{
 		Timestamp:      start,
 		Latency:        latency,
 		Status:         resp.StatusCode,
 		Bytes:          written,
+		WireBytes:      wire.n,
+		Error:          resultErr,
 		AssertionError: assertionErr,
 		StepName:       step.Name,
 		Protocol:       protocol,
 	}
 }
 
+// consumeSSE reads an SSE response event by event, recording time-to-first-event and
+// inter-event gaps. Event assertions and extraction run against each event payload;
+// the first assertion failure is kept for the result.
+func (e *Engine) consumeSSE(resp *http.Response, step models.Step, session map[string]string, start time.Time) models.Result {
+	result := models.Result{
+		Timestamp: start,
+		Status:    resp.StatusCode,
+		StepName:  step.Name,
+		Protocol:  resp.Proto,
+		Streamed:  true,
+	}
+
+	// Header extraction still applies to streamed responses.
+	for varName, path := range step.Extract {
+		if strings.HasPrefix(path, "header:") {
+			if val := resp.Header.Get(strings.TrimPrefix(path, "header:")); val != "" {
+				session[varName] = val
+			}
+		}
+	}
+
+	wire := &countingReader{r: resp.Body}
+	decoded, release := DecodeBody(resp.Header.Get("Content-Encoding"), wire)
+	defer release()
+	body := &countingReader{r: decoded}
+
+	var lastEvent time.Time
+	err := ReadSSE(body, step.SSE, func(ev SSEEvent) error {
+		now := time.Now()
+		if result.Events == 0 {
+			result.TimeToFirstEvent = now.Sub(start)
+		} else {
+			result.EventGaps = append(result.EventGaps, now.Sub(lastEvent))
+		}
+		lastEvent = now
+		result.Events++
+
+		for varName, path := range step.EventExtract {
+			if val := ExtractEventValue(ev, path); val != "" {
+				session[varName] = val
+			}
+		}
+
+		if result.AssertionError == nil && len(step.EventAssertions) > 0 {
+			result.AssertionError = validator.ValidateAssertions([]byte(ev.Data), step.EventAssertions)
+		}
+		return nil
+	})
+
+	result.Latency = time.Since(start)
+	result.Bytes = body.n
+	result.WireBytes = wire.n
+	if err != nil && context.Cause(resp.Request.Context()) != errStreamDuration {
+		result.Error = &models.BodyReadError{Err: err}
+	}
+	return result
+}
+
+// errStreamDuration ends an SSE stream that reached its sse.max_duration.
+var errStreamDuration = errors.New("stream reached max_duration")
+
+// streamContext bounds an SSE request. The client timeout would cut a stream
+// off mid-read and count it as an error, so streams use their own deadline:
+// with sse.max_duration the stream is read until then and ends cleanly,
+// otherwise the request timeout bounds the whole stream as before.
+func (e *Engine) streamContext(ctx context.Context, opts *models.SSEOptions) (context.Context, context.CancelFunc) {
+	if opts == nil || opts.MaxDuration <= 0 {
+		return context.WithTimeout(ctx, e.client.Timeout)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	timer := time.AfterFunc(opts.MaxDuration, func() { cancel(errStreamDuration) })
+	return ctx, func() {
+		timer.Stop()
+		cancel(nil)
+	}
+}
+
+// ExtractEventValue resolves an event_extract rule against a single SSE event.
+// "sse:data", "sse:event" and "sse:id" read the raw event fields; anything else
+// is treated as a JSON path into the event payload.
+func ExtractEventValue(ev SSEEvent, path string) string {
+	switch path {
+	case "sse:data":
+		return ev.Data
+	case "sse:event":
+		return ev.Event
+	case "sse:id":
+		return ev.ID
+	}
+	return gjson.Get(ev.Data, path).String()
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
 // executeCompiledStepWithRetry wraps executeCompiledStep with the same retry logic.
 func (e *Engine) executeCompiledStepWithRetry(ctx context.Context, step models.Step, cs compiledStep, session map[string]string) models.Result {
 	var result models.Result
@@ -481,4 +767,3 @@ func (e *Engine) executeCompiledStepWithRetry(ctx context.Context, step models.S
 
 	return result
 }
-
@@ -0,0 +1,80 @@
+package attacker
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// StreamBody is a pre-compiled streamed request body. Every request reads the same
+// source — a shared descriptor for disk files (via ReadAt) or a shared read-only
+// buffer for generated bytes — so nothing is copied into memory per request.
+type StreamBody struct {
+	file    *os.File
+	data    []byte
+	size    int64
+	chunked bool
+	source  string
+}
+
+// CompileBodyStream opens or generates the body source once. Disk files must be
+// released with Close.
+func CompileBodyStream(bs *models.BodyStream) (*StreamBody, error) {
+	sb := &StreamBody{chunked: bs.Chunked}
+	if bs.RandomBytes > 0 {
+		sb.data = RandomPayload(bs.RandomBytes)
+		sb.size = bs.RandomBytes
+		sb.source = "random bytes"
+		return sb, nil
+	}
+
+	file, err := os.Open(bs.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open body stream '%s': %w", bs.Path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat body stream '%s': %w", bs.Path, err)
+	}
+	sb.file = file
+	sb.size = info.Size()
+	sb.source = bs.Path
+	return sb, nil
+}
+
+// Reader returns a fresh reader over the body and the Content-Length to send.
+// The length is -1 for chunked bodies, which makes the transport use chunked
+// transfer encoding on HTTP/1.1.
+func (sb *StreamBody) Reader() (io.Reader, int64) {
+	var r io.Reader
+	if sb.file != nil {
+		r = io.NewSectionReader(sb.file, 0, sb.size)
+	} else {
+		r = bytes.NewReader(sb.data)
+	}
+	if sb.chunked {
+		// Hide the concrete type so the transport cannot infer a length.
+		return io.MultiReader(r), -1
+	}
+	return r, sb.size
+}
+
+// Describe returns a short human-readable summary of the body (used by debug mode).
+func (sb *StreamBody) Describe() string {
+	mode := "Content-Length"
+	if sb.chunked {
+		mode = "chunked"
+	}
+	return fmt.Sprintf("[streamed body: %s, %d bytes, %s]", sb.source, sb.size, mode)
+}
+
+// Close releases the file descriptor held for disk-backed bodies.
+func (sb *StreamBody) Close() {
+	if sb != nil && sb.file != nil {
+		sb.file.Close()
+	}
+}
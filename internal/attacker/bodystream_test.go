@@ -0,0 +1,164 @@
+package attacker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// receivedBody is what the server saw of one streamed request.
+type receivedBody struct {
+	contentLength    int64
+	transferEncoding []string
+	body             []byte
+}
+
+// streamServer records every request body it receives.
+func streamServer(t *testing.T) (*httptest.Server, func() []receivedBody) {
+	t.Helper()
+	var (
+		mu   sync.Mutex
+		seen []receivedBody
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		seen = append(seen, receivedBody{r.ContentLength, r.TransferEncoding, body})
+		mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []receivedBody {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]receivedBody(nil), seen...)
+	}
+}
+
+// sendStream posts sb through the engine n times.
+func sendStream(ctx context.Context, url string, sb *StreamBody, n int) error {
+	e := NewEngine()
+	e.client = &http.Client{}
+	e.acceptEncoding = DefaultAcceptEncoding
+	step := models.Step{Name: "upload", URL: url, Method: "PUT"}
+	cs := compiledStep{url: CompileTemplate(url), body: CompileTemplate(""), stream: sb, headers: map[string]*CompiledTemplate{}, vars: map[string]*CompiledTemplate{}}
+	for range n {
+		res := e.executeCompiledStep(ctx, step, cs, map[string]string{})
+		if res.Error != nil || res.Status != http.StatusOK {
+			return fmt.Errorf("request failed: status %d, %v", res.Status, res.Error)
+		}
+	}
+	return nil
+}
+
+func TestBodyStream(t *testing.T) {
+	payload := bytes.Repeat([]byte("0123456789abcdef"), 64*1024) // 1 MiB
+	path := filepath.Join(t.TempDir(), "payload.bin")
+	if err := os.WriteFile(path, payload, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		bs      models.BodyStream
+		size    int
+		chunked bool
+	}{
+		{"file with content-length", models.BodyStream{Path: path}, len(payload), false},
+		{"file chunked", models.BodyStream{Path: path, Chunked: true}, len(payload), true},
+		{"random with content-length", models.BodyStream{RandomBytes: 300000}, 300000, false},
+		{"random chunked", models.BodyStream{RandomBytes: 300000, Chunked: true}, 300000, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sb, err := CompileBodyStream(&tt.bs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sb.Close()
+
+			srv, received := streamServer(t)
+			// Every request reads the source from the start again.
+			if err := sendStream(t.Context(), srv.URL, sb, 3); err != nil {
+				t.Fatal(err)
+			}
+
+			got := received()
+			if len(got) != 3 {
+				t.Fatalf("server received %d requests, want 3", len(got))
+			}
+			for i, r := range got {
+				if tt.chunked {
+					if r.contentLength != -1 || len(r.transferEncoding) != 1 || r.transferEncoding[0] != "chunked" {
+						t.Errorf("request %d: Content-Length %d, Transfer-Encoding %v; want chunked", i, r.contentLength, r.transferEncoding)
+					}
+				} else if r.contentLength != int64(tt.size) || len(r.transferEncoding) != 0 {
+					t.Errorf("request %d: Content-Length %d, Transfer-Encoding %v; want Content-Length %d", i, r.contentLength, r.transferEncoding, tt.size)
+				}
+				if len(r.body) != tt.size {
+					t.Fatalf("request %d: received %d bytes, want %d", i, len(r.body), tt.size)
+				}
+				if tt.bs.Path != "" && !bytes.Equal(r.body, payload) {
+					t.Fatalf("request %d: body differs from the file", i)
+				}
+				if i > 0 && !bytes.Equal(r.body, got[0].body) {
+					t.Fatalf("request %d: body differs from the first request's", i)
+				}
+			}
+		})
+	}
+}
+
+// TestBodyStreamConcurrent checks that concurrent requests read the shared
+// descriptor independently.
+func TestBodyStreamConcurrent(t *testing.T) {
+	payload := RandomPayload(256 * 1024)
+	path := filepath.Join(t.TempDir(), "payload.bin")
+	if err := os.WriteFile(path, payload, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sb, err := CompileBodyStream(&models.BodyStream{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sb.Close()
+
+	srv, received := streamServer(t)
+	errs := make(chan error, 4)
+	for range 4 {
+		go func() { errs <- sendStream(t.Context(), srv.URL, sb, 2) }()
+	}
+	for range 4 {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := received()
+	if len(got) != 8 {
+		t.Fatalf("server received %d requests, want 8", len(got))
+	}
+	for i, r := range got {
+		if !bytes.Equal(r.body, payload) {
+			t.Fatalf("request %d: body of %d bytes differs from the file", i, len(r.body))
+		}
+	}
+}
+
+func TestCompileBodyStreamMissingFile(t *testing.T) {
+	if _, err := CompileBodyStream(&models.BodyStream{Path: filepath.Join(t.TempDir(), "missing.bin")}); err == nil {
+		t.Fatal("want an error for a missing file")
+	}
+}
@@ -0,0 +1,168 @@
+package attacker
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Supported content codings for request compression and response decoding.
+const (
+	EncodingGzip    = "gzip"
+	EncodingBrotli  = "br"
+	EncodingZstd    = "zstd"
+	EncodingDeflate = "deflate"
+)
+
+// DefaultAcceptEncoding mirrors what Go's transport advertises on its own. Sayl sets it
+// explicitly so the transport never decompresses transparently; responses are decoded
+// by DecodeBody instead, which lets the engine count wire bytes and decoded bytes separately.
+const DefaultAcceptEncoding = "gzip"
+
+// Encoder and decoder pools — compression state is large, so it is reused across requests.
+var (
+	gzipWriterPool = sync.Pool{New: func() any {
+		w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return w
+	}}
+	brotliWriterPool = sync.Pool{New: func() any {
+		return brotli.NewWriterLevel(nil, 4) // level 4 keeps per-request CPU cost reasonable
+	}}
+	gzipReaderPool   sync.Pool // *gzip.Reader
+	brotliReaderPool sync.Pool // *brotli.Reader
+	zstdReaderPool   sync.Pool // *zstd.Decoder
+
+	// zstd.Encoder.EncodeAll is safe for concurrent use, so one encoder serves all workers.
+	zstdEncoderOnce sync.Once
+	zstdEncoder     *zstd.Encoder
+)
+
+// CompressBody compresses a rendered request body with the given content coding.
+func CompressBody(enc string, body []byte) ([]byte, error) {
+	switch enc {
+	case EncodingGzip:
+		var buf bytes.Buffer
+		buf.Grow(len(body)/2 + 64)
+		w := gzipWriterPool.Get().(*gzip.Writer)
+		defer gzipWriterPool.Put(w)
+		w.Reset(&buf)
+		if _, err := w.Write(body); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case EncodingBrotli:
+		var buf bytes.Buffer
+		buf.Grow(len(body)/2 + 64)
+		w := brotliWriterPool.Get().(*brotli.Writer)
+		defer brotliWriterPool.Put(w)
+		w.Reset(&buf)
+		if _, err := w.Write(body); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case EncodingZstd:
+		zstdEncoderOnce.Do(func() {
+			zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+		})
+		return zstdEncoder.EncodeAll(body, make([]byte, 0, len(body)/2+64)), nil
+	default:
+		return nil, fmt.Errorf("unsupported request encoding '%s'", enc)
+	}
+}
+
+// DecodeBody wraps a response body with a decoder for its Content-Encoding. The returned
+// release func must be called once the body has been consumed so pooled decoders can be
+// reused. Unknown or identity encodings return the body unchanged.
+func DecodeBody(contentEncoding string, body io.Reader) (io.Reader, func()) {
+	noop := func() {}
+	enc := strings.ToLower(strings.TrimSpace(contentEncoding))
+
+	switch enc {
+	case "", "identity":
+		return body, noop
+	case EncodingGzip, "x-gzip":
+		if zr, ok := gzipReaderPool.Get().(*gzip.Reader); ok {
+			if err := zr.Reset(body); err != nil {
+				return emptyOrError(err), noop
+			}
+			return zr, func() { gzipReaderPool.Put(zr) }
+		}
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			return emptyOrError(err), noop
+		}
+		return zr, func() { gzipReaderPool.Put(zr) }
+	case EncodingBrotli:
+		if br, ok := brotliReaderPool.Get().(*brotli.Reader); ok {
+			_ = br.Reset(body)
+			return br, func() { brotliReaderPool.Put(br) }
+		}
+		br := brotli.NewReader(body)
+		return br, func() { brotliReaderPool.Put(br) }
+	case EncodingZstd:
+		if zd, ok := zstdReaderPool.Get().(*zstd.Decoder); ok {
+			if err := zd.Reset(body); err != nil {
+				return emptyOrError(err), noop
+			}
+			return zd, func() { zstdReaderPool.Put(zd) }
+		}
+		zd, err := zstd.NewReader(body, zstd.WithDecoderConcurrency(1), zstd.WithDecoderLowmem(true))
+		if err != nil {
+			return emptyOrError(err), noop
+		}
+		return zd, func() { zstdReaderPool.Put(zd) }
+	case EncodingDeflate:
+		// HTTP "deflate" is the zlib format (RFC 9110 §8.4.1.2), but some servers send raw
+		// DEFLATE data, so it is only expected when the zlib header is missing.
+		br := bufio.NewReader(body)
+		header, err := br.Peek(2)
+		if len(header) == 0 {
+			return emptyOrError(err), noop
+		}
+		if !isZlibHeader(header) {
+			fr := flate.NewReader(br)
+			return fr, func() { fr.Close() }
+		}
+		zr, err := zlib.NewReader(br)
+		if err != nil {
+			return emptyOrError(err), noop
+		}
+		return zr, func() { zr.Close() }
+	default:
+		return body, noop
+	}
+}
+
+// isZlibHeader reports whether b starts with a zlib header: the DEFLATE method and a
+// check value that makes the first two bytes a multiple of 31 (RFC 1950).
+func isZlibHeader(b []byte) bool {
+	return len(b) >= 2 && b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
+
+// emptyOrError turns a decoder initialisation failure into a reader. An empty body
+// (e.g. HEAD or 204 with a Content-Encoding header) is not an error.
+func emptyOrError(err error) io.Reader {
+	if err == io.EOF {
+		return bytes.NewReader(nil)
+	}
+	return &errReader{err: fmt.Errorf("failed to decode response body: %w", err)}
+}
+
+type errReader struct{ err error }
+
+func (r *errReader) Read([]byte) (int, error) { return 0, r.err }
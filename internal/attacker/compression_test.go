@@ -0,0 +1,128 @@
+package attacker
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/zlib"
+	"io"
+	"strings"
+	"testing"
+)
+
+func zlibData(t *testing.T, b []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func rawDeflateData(t *testing.T, b []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func compressed(t *testing.T, enc string, b []byte) []byte {
+	t.Helper()
+	out, err := CompressBody(enc, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestDecodeBody(t *testing.T) {
+	payload := []byte(strings.Repeat(`{"id":42,"name":"sayl"}`, 50))
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+		want     []byte
+		wantErr  bool
+	}{
+		{name: "identity", encoding: "", body: payload, want: payload},
+		{name: "unknown encoding passes through", encoding: "compress", body: payload, want: payload},
+		{name: "gzip", encoding: "gzip", body: compressed(t, EncodingGzip, payload), want: payload},
+		{name: "x-gzip", encoding: "x-gzip", body: compressed(t, EncodingGzip, payload), want: payload},
+		{name: "brotli", encoding: "br", body: compressed(t, EncodingBrotli, payload), want: payload},
+		{name: "zstd", encoding: "zstd", body: compressed(t, EncodingZstd, payload), want: payload},
+		{name: "deflate is zlib", encoding: "deflate", body: zlibData(t, payload), want: payload},
+		{name: "deflate header case", encoding: " Deflate ", body: zlibData(t, payload), want: payload},
+		{name: "raw deflate fallback", encoding: "deflate", body: rawDeflateData(t, payload), want: payload},
+		{name: "empty gzip body", encoding: "gzip", body: nil, want: []byte{}},
+		{name: "empty deflate body", encoding: "deflate", body: nil, want: []byte{}},
+		{name: "corrupt gzip", encoding: "gzip", body: []byte("not gzip at all"), wantErr: true},
+		{name: "truncated zlib", encoding: "deflate", body: zlibData(t, payload)[:20], wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, release := DecodeBody(tt.encoding, bytes.NewReader(tt.body))
+			got, err := io.ReadAll(r)
+			release()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %d bytes", len(got))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Fatalf("decoded %d bytes, want %d", len(got), len(tt.want))
+			}
+		})
+	}
+}
+
+func TestDecodeBodyReusesDecoders(t *testing.T) {
+	payload := []byte("hello, pooled decoder")
+	for _, enc := range []string{EncodingGzip, EncodingBrotli, EncodingZstd} {
+		body := compressed(t, enc, payload)
+		for i := 0; i < 3; i++ {
+			r, release := DecodeBody(enc, bytes.NewReader(body))
+			got, err := io.ReadAll(r)
+			release()
+			if err != nil || !bytes.Equal(got, payload) {
+				t.Fatalf("%s round %d: got %q, %v", enc, i, got, err)
+			}
+		}
+	}
+}
+
+func TestIsZlibHeader(t *testing.T) {
+	tests := []struct {
+		header []byte
+		want   bool
+	}{
+		{[]byte{0x78, 0x9c}, true},  // Default compression
+		{[]byte{0x78, 0x01}, true},  // No compression
+		{[]byte{0x78, 0xda}, true},  // Best compression
+		{[]byte{0x78, 0x9d}, false}, // Bad check value
+		{[]byte{0x1f, 0x8b}, false}, // gzip magic
+		{[]byte{0x78}, false},
+	}
+	for _, tt := range tests {
+		if got := isZlibHeader(tt.header); got != tt.want {
+			t.Errorf("isZlibHeader(%x) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+package attacker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Amr-9/sayl/pkg/models"
+	"golang.org/x/time/rate"
+)
+
+// Errors returned by the runtime controls.
+var (
+	ErrNotStarted = errors.New("the test has not started yet")
+	ErrPaused     = errors.New("the test is already paused")
+	ErrNotPaused  = errors.New("the test is not paused")
+	ErrNoStages   = errors.New("the test has no stages")
+	ErrLastStage  = errors.New("the test is past its last stage")
+)
+
+// minRate is the lowest target rate the controls and the stages can set; a zero
+// limit would block the workers for good.
+const minRate = 1.0
+
+// control holds the runtime state of an attack: the run clock, the pause gate
+// and the manual rate adjustment. The run clock measures the test time the
+// schedule follows. It stops while the test is paused and jumps to the end of a
+// stage when the stage is skipped, so both the stages and the run deadline
+// continue where they left off.
+type control struct {
+	mu       sync.Mutex
+	started  bool
+	start    time.Time
+	shift    time.Duration // Time skipped minus time spent paused
+	pausedAt time.Time     // Zero while running
+	resume   chan struct{} // Closed on resume; nil while running
+	stages   []models.Stage
+	base     float64 // Rate asked for by the configuration or the current stage
+	offset   float64 // Manual adjustment added to base
+
+	paused  atomic.Bool   // Read by the workers without taking mu
+	changed chan struct{} // Signalled when the run clock stops, resumes or jumps
+	limiter *rate.Limiter
+}
+
+// begin starts the run clock; the stages, if any, set the rate from here on.
+func (c *control) begin(cfg models.Config, limiter *rate.Limiter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.started = true
+	c.start = time.Now()
+	c.stages = cfg.Stages
+	c.base = float64(limiter.Limit())
+	c.limiter = limiter
+}
+
+// elapsed returns the run clock. Call with mu held.
+func (c *control) elapsed() time.Duration {
+	if !c.started {
+		return 0
+	}
+	now := time.Now()
+	if c.resume != nil {
+		now = c.pausedAt
+	}
+	return now.Sub(c.start) + c.shift
+}
+
+// notify wakes up whoever waits on the run clock, e.g. the run deadline.
+func (c *control) notify() {
+	select {
+	case c.changed <- struct{}{}:
+	default:
+	}
+}
+
+// applyRate sets the limiter to the scheduled rate plus the manual adjustment.
+// Call with mu held.
+func (c *control) applyRate() {
+	c.limiter.SetLimit(rate.Limit(max(c.base+c.offset, minRate)))
+}
+
+// setBase changes the scheduled rate, keeping the manual adjustment.
+func (c *control) setBase(r float64) {
+	c.mu.Lock()
+	c.base = r
+	c.applyRate()
+	c.mu.Unlock()
+}
+
+// wait blocks while the test is paused. It fails once ctx is done.
+func (c *control) wait(ctx context.Context) error {
+	if !c.paused.Load() {
+		return nil
+	}
+	c.mu.Lock()
+	resume := c.resume
+	c.mu.Unlock()
+	if resume == nil {
+		return nil
+	}
+	select {
+	case <-resume:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stageAt returns the index of the stage running at test time t and the time
+// its end is reached; the index equals len(stages) after the last one.
+func stageAt(stages []models.Stage, t time.Duration) (int, time.Duration) {
+	var end time.Duration
+	for i, s := range stages {
+		end += s.Duration
+		if t < end {
+			return i, end
+		}
+	}
+	return len(stages), end
+}
+
+// stageRate returns the rate the stages ask for at test time t. Each stage ramps
+// linearly from the target of the previous one, starting from minRate.
+func stageRate(stages []models.Stage, t time.Duration) float64 {
+	from := minRate
+	for _, s := range stages {
+		to := max(float64(s.Target), minRate)
+		if t < s.Duration {
+			return from + (to-from)*float64(t)/float64(s.Duration)
+		}
+		t -= s.Duration
+		from = to
+	}
+	return from
+}
+
+// Elapsed returns the test time: the time since the attack started, without the
+// time spent paused and with the skipped part of stages added.
+func (e *Engine) Elapsed() time.Duration {
+	e.ctl.mu.Lock()
+	defer e.ctl.mu.Unlock()
+	return e.ctl.elapsed()
+}
+
+// ClockChanged is signalled whenever the test time stops, resumes or jumps.
+func (e *Engine) ClockChanged() <-chan struct{} {
+	return e.ctl.changed
+}
+
+// Paused reports whether the test is paused.
+func (e *Engine) Paused() bool {
+	return e.ctl.paused.Load()
+}
+
+// Pause stops the workers from sending new requests; requests in flight finish.
+// The test time stops until Resume.
+func (e *Engine) Pause() error {
+	c := &e.ctl
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case !c.started:
+		return ErrNotStarted
+	case c.resume != nil:
+		return ErrPaused
+	}
+	c.pausedAt = time.Now()
+	c.resume = make(chan struct{})
+	c.paused.Store(true)
+	c.notify()
+	return nil
+}
+
+// Resume lets the workers continue after Pause.
+func (e *Engine) Resume() error {
+	c := &e.ctl
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.resume == nil {
+		return ErrNotPaused
+	}
+	c.shift -= time.Since(c.pausedAt)
+	close(c.resume)
+	c.resume = nil
+	c.paused.Store(false)
+	c.notify()
+	return nil
+}
+
+// AdjustRate changes the target rate by delta requests per second and returns
+// the new target. With stages, the adjustment is kept on top of the ramp.
+func (e *Engine) AdjustRate(delta float64) (float64, error) {
+	c := &e.ctl
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.started {
+		return 0, ErrNotStarted
+	}
+	c.offset = max(c.offset+delta, minRate-c.base)
+	c.applyRate()
+	return float64(c.limiter.Limit()), nil
+}
+
+// Stage returns the index of the running stage and the number of stages. The
+// index equals the count once the last stage has ended.
+func (e *Engine) Stage() (int, int) {
+	c := &e.ctl
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i, _ := stageAt(c.stages, c.elapsed())
+	return i, len(c.stages)
+}
+
+// SkipStage ends the running stage: the rate jumps to its target and the next
+// stage starts. Skipping the last stage ends the test. It returns the index of
+// the stage that now runs.
+func (e *Engine) SkipStage() (int, error) {
+	c := &e.ctl
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.started {
+		return 0, ErrNotStarted
+	}
+	if len(c.stages) == 0 {
+		return 0, ErrNoStages
+	}
+	i, end := stageAt(c.stages, c.elapsed())
+	if i == len(c.stages) {
+		return 0, ErrLastStage
+	}
+	c.shift += end - c.elapsed()
+	c.base = stageRate(c.stages, end)
+	c.applyRate()
+	c.notify()
+	return i + 1, nil
+}
+
+// runStages follows the stages on the test time until ctx is done.
+func (e *Engine) runStages(ctx context.Context, stages []models.Stage) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.ctl.setBase(stageRate(stages, e.Elapsed()))
+		}
+	}
+}
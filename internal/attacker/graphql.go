@@ -0,0 +1,45 @@
+package attacker
+
+import (
+	"fmt"
+
+	"github.com/tidwall/gjson"
+)
+
+// GraphQLError reports a GraphQL response that carried a non-empty "errors" array.
+// GraphQL servers usually answer with HTTP 200 in this case, so the status code alone
+// would count the request as a success.
+type GraphQLError struct {
+	Message string // Message of the first error in the array
+	Code    string // extensions.code of the first error, if present
+	Count   int    // Number of errors in the array
+}
+
+func (e *GraphQLError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = "unknown error"
+	}
+	if e.Code != "" {
+		return fmt.Sprintf("graphql error [%s]: %s", e.Code, msg)
+	}
+	return fmt.Sprintf("graphql error: %s", msg)
+}
+
+// CheckGraphQLErrors returns a *GraphQLError if the response body contains a
+// non-empty top-level "errors" array, or nil otherwise.
+func CheckGraphQLErrors(body []byte) error {
+	errs := gjson.GetBytes(body, "errors")
+	if !errs.IsArray() {
+		return nil
+	}
+	list := errs.Array()
+	if len(list) == 0 {
+		return nil
+	}
+	return &GraphQLError{
+		Message: list[0].Get("message").String(),
+		Code:    list[0].Get("extensions.code").String(),
+		Count:   len(list),
+	}
+}
@@ -0,0 +1,69 @@
+package attacker
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckGraphQLErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    *GraphQLError // nil = no error
+		wantMsg string
+	}{
+		{"data only", `{"data":{"user":{"id":"1"}}}`, nil, ""},
+		{"empty errors", `{"data":{"user":null},"errors":[]}`, nil, ""},
+		{"null errors", `{"data":{"user":null},"errors":null}`, nil, ""},
+		{"errors not an array", `{"errors":"oops"}`, nil, ""},
+		{"empty body", ``, nil, ""},
+		{"non-json body", `<html><body>502 Bad Gateway: errors upstream</body></html>`, nil, ""},
+		{"plain text", `errors: [1, 2]`, nil, ""},
+		{"nested errors field", `{"data":{"errors":[{"message":"not top-level"}]}}`, nil, ""},
+		{
+			"single error",
+			`{"errors":[{"message":"Cannot query field \"nme\" on type \"User\"."}]}`,
+			&GraphQLError{Message: `Cannot query field "nme" on type "User".`, Count: 1},
+			`graphql error: Cannot query field "nme" on type "User".`,
+		},
+		{
+			"extensions code",
+			`{"errors":[{"message":"Not logged in","extensions":{"code":"UNAUTHENTICATED"}}],"data":null}`,
+			&GraphQLError{Message: "Not logged in", Code: "UNAUTHENTICATED", Count: 1},
+			"graphql error [UNAUTHENTICATED]: Not logged in",
+		},
+		{
+			"data alongside errors",
+			`{"data":{"user":{"id":"1","orders":null}},"errors":[{"message":"orders timed out","path":["user","orders"],"extensions":{"code":"TIMEOUT"}},{"message":"second"}]}`,
+			&GraphQLError{Message: "orders timed out", Code: "TIMEOUT", Count: 2},
+			"graphql error [TIMEOUT]: orders timed out",
+		},
+		{
+			"error without message",
+			`{"errors":[{"locations":[{"line":1,"column":2}]}]}`,
+			&GraphQLError{Count: 1},
+			"graphql error: unknown error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckGraphQLErrors([]byte(tt.body))
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("got %v, want no error", err)
+				}
+				return
+			}
+			var gqlErr *GraphQLError
+			if !errors.As(err, &gqlErr) {
+				t.Fatalf("got %v, want a *GraphQLError", err)
+			}
+			if *gqlErr != *tt.want {
+				t.Fatalf("got %+v, want %+v", *gqlErr, *tt.want)
+			}
+			if err.Error() != tt.wantMsg {
+				t.Fatalf("message %q, want %q", err.Error(), tt.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package attacker
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// Multipart is a pre-compiled multipart/form-data body. Field values and filenames are
+// templated per request; file contents are never copied into memory per request —
+// disk files are read through a shared descriptor and random payloads from a shared
+// read-only buffer.
+type Multipart struct {
+	fields []multipartField
+	files  []multipartFile
+}
+
+type multipartField struct {
+	name  string
+	value *CompiledTemplate
+}
+
+type multipartFile struct {
+	field       string
+	filename    *CompiledTemplate
+	contentType string
+	file        *os.File // set for disk files; read with ReadAt so it is safe to share
+	data        []byte   // set for generated random payloads
+	size        int64
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// CompileMultipart prepares a multipart body for per-request execution.
+// Disk files are opened once and must be released with Close.
+func CompileMultipart(mb *models.MultipartBody) (*Multipart, error) {
+	m := &Multipart{}
+	for _, f := range mb.Fields {
+		m.fields = append(m.fields, multipartField{name: f.Name, value: CompileTemplate(f.Value)})
+	}
+
+	for _, f := range mb.Files {
+		part := multipartFile{field: f.Field, contentType: f.ContentType}
+
+		filename := f.Filename
+		if f.RandomBytes > 0 {
+			part.data = RandomPayload(f.RandomBytes)
+			part.size = f.RandomBytes
+			if filename == "" {
+				filename = f.Field + ".bin"
+			}
+		} else {
+			file, err := os.Open(f.Path)
+			if err != nil {
+				m.Close()
+				return nil, fmt.Errorf("failed to open multipart file '%s': %w", f.Path, err)
+			}
+			info, err := file.Stat()
+			if err != nil {
+				file.Close()
+				m.Close()
+				return nil, fmt.Errorf("failed to stat multipart file '%s': %w", f.Path, err)
+			}
+			part.file = file
+			part.size = info.Size()
+			if filename == "" {
+				filename = filepath.Base(f.Path)
+			}
+			if part.contentType == "" {
+				part.contentType = mime.TypeByExtension(filepath.Ext(f.Path))
+			}
+		}
+		if part.contentType == "" {
+			part.contentType = "application/octet-stream"
+		}
+		part.filename = CompileTemplate(filename)
+		m.files = append(m.files, part)
+	}
+	return m, nil
+}
+
+// Build renders the body for a single request with a fresh random boundary.
+// Returns the body reader, its exact length and the Content-Type header value.
+func (m *Multipart) Build(vp *VariableProcessor, session map[string]string) (io.Reader, int64, string) {
+	// Part headers and field values go into one buffer. The buffer is only ever
+	// appended to, so slices taken from it stay valid while file contents are
+	// interleaved as separate readers.
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	readers := make([]io.Reader, 0, 2*len(m.files)+1)
+	var length int64
+	mark := 0
+	flush := func() {
+		if buf.Len() > mark {
+			readers = append(readers, bytes.NewReader(buf.Bytes()[mark:buf.Len()]))
+			length += int64(buf.Len() - mark)
+			mark = buf.Len()
+		}
+	}
+
+	for _, f := range m.fields {
+		_ = w.WriteField(f.name, f.value.Execute(vp, session))
+	}
+
+	for i := range m.files {
+		f := &m.files[i]
+		h := make(textproto.MIMEHeader, 2)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			quoteEscaper.Replace(f.field), quoteEscaper.Replace(f.filename.Execute(vp, session))))
+		h.Set("Content-Type", f.contentType)
+		_, _ = w.CreatePart(h)
+		flush()
+
+		if f.file != nil {
+			readers = append(readers, io.NewSectionReader(f.file, 0, f.size))
+		} else {
+			readers = append(readers, bytes.NewReader(f.data))
+		}
+		length += f.size
+	}
+
+	_ = w.Close()
+	flush()
+
+	return io.MultiReader(readers...), length, w.FormDataContentType()
+}
+
+// Describe returns a short human-readable summary of the parts (used by debug mode).
+func (m *Multipart) Describe() []string {
+	var out []string
+	for _, f := range m.fields {
+		out = append(out, fmt.Sprintf("field %q", f.name))
+	}
+	for _, f := range m.files {
+		out = append(out, fmt.Sprintf("file %q (%s, %d bytes)", f.field, f.contentType, f.size))
+	}
+	return out
+}
+
+// Close releases the file descriptors held for disk-backed parts.
+func (m *Multipart) Close() {
+	if m == nil {
+		return
+	}
+	for _, f := range m.files {
+		if f.file != nil {
+			f.file.Close()
+		}
+	}
+}
+
+// RandomPayload generates n bytes of random data once, to be shared read-only by all requests.
+func RandomPayload(n int64) []byte {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return b
+}
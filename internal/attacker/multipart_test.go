@@ -0,0 +1,184 @@
+package attacker
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// receivedPart is a part as parsed on the server side.
+type receivedPart struct {
+	field, filename, contentType string
+	body                         []byte
+}
+
+func TestMultipartRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	avatar := bytes.Repeat([]byte{0x89, 'P', 'N', 'G', '\r', '\n', 0}, 10000)
+	avatarPath := filepath.Join(dir, "avatar.png")
+	if err := os.WriteFile(avatarPath, avatar, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mp, err := CompileMultipart(&models.MultipartBody{
+		Fields: []models.MultipartField{
+			{Name: "user", Value: "{{user}}"},
+			{Name: "note", Value: "line 1\r\nline 2 --boundary-like"},
+		},
+		Files: []models.MultipartFile{
+			{Field: "avatar", Path: avatarPath},
+			{Field: "doc", Path: avatarPath, Filename: `we"ird\name {{user}}.txt`, ContentType: "text/plain"},
+			{Field: "blob", RandomBytes: 4096},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mp.Close()
+
+	var (
+		gotLength   int64
+		gotReceived int64
+		parts       []receivedPart
+		parseErr    error
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotLength = r.ContentLength
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			parseErr = err
+			return
+		}
+		gotReceived = int64(len(body))
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil {
+			parseErr = err
+			return
+		}
+		mr := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+		for {
+			p, err := mr.NextPart()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				parseErr = err
+				return
+			}
+			data, err := io.ReadAll(p)
+			if err != nil {
+				parseErr = err
+				return
+			}
+			parts = append(parts, receivedPart{p.FormName(), p.FileName(), p.Header.Get("Content-Type"), data})
+		}
+	}))
+	defer srv.Close()
+
+	vp := NewVariableProcessor()
+	boundaries := map[string]bool{}
+	for _, user := range []string{"alice", "bob"} {
+		parts, parseErr = nil, nil
+		body, length, contentType := mp.Build(vp, map[string]string{"user": user})
+		req, err := http.NewRequest("POST", srv.URL, body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.ContentLength = length
+		req.Header.Set("Content-Type", contentType)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		_, params, _ := mime.ParseMediaType(contentType)
+		boundaries[params["boundary"]] = true
+
+		if parseErr != nil {
+			t.Fatalf("server could not parse the body: %v", parseErr)
+		}
+		if gotLength != length || gotReceived != length {
+			t.Fatalf("Content-Length %d, received %d bytes, precomputed %d", gotLength, gotReceived, length)
+		}
+
+		want := []receivedPart{
+			{"user", "", "", []byte(user)},
+			{"note", "", "", []byte("line 1\r\nline 2 --boundary-like")},
+			{"avatar", "avatar.png", "image/png", avatar},
+			{"doc", `we"ird\name ` + user + `.txt`, "text/plain", avatar},
+			{"blob", "blob.bin", "application/octet-stream", nil},
+		}
+		if len(parts) != len(want) {
+			t.Fatalf("received %d parts, want %d", len(parts), len(want))
+		}
+		for i, w := range want {
+			p := parts[i]
+			if p.field != w.field || p.filename != w.filename || p.contentType != w.contentType {
+				t.Errorf("part %d: %q %q %q, want %q %q %q", i, p.field, p.filename, p.contentType, w.field, w.filename, w.contentType)
+			}
+			if w.body != nil && !bytes.Equal(p.body, w.body) {
+				t.Errorf("part %d (%s): body of %d bytes differs from the %d expected", i, w.field, len(p.body), len(w.body))
+			}
+		}
+		if len(parts[4].body) != 4096 {
+			t.Errorf("random part of %d bytes, want 4096", len(parts[4].body))
+		}
+	}
+	if len(boundaries) != 2 {
+		t.Error("requests shared a boundary")
+	}
+}
+
+// TestMultipartConcurrentBuild checks that bodies built at the same time read the
+// shared file descriptor independently.
+func TestMultipartConcurrentBuild(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.bin")
+	content := RandomPayload(64 * 1024)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	mp, err := CompileMultipart(&models.MultipartBody{Files: []models.MultipartFile{{Field: "f", Path: path}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mp.Close()
+
+	vp := NewVariableProcessor()
+	errs := make(chan error, 8)
+	for range 8 {
+		go func() {
+			body, length, contentType := mp.Build(vp, map[string]string{})
+			data, err := io.ReadAll(body)
+			if err == nil && int64(len(data)) != length {
+				err = io.ErrShortBuffer
+			}
+			if err == nil {
+				_, params, _ := mime.ParseMediaType(contentType)
+				var p *multipart.Part
+				p, err = multipart.NewReader(bytes.NewReader(data), params["boundary"]).NextPart()
+				if err == nil {
+					var got []byte
+					got, err = io.ReadAll(p)
+					if err == nil && !bytes.Equal(got, content) {
+						err = io.ErrUnexpectedEOF
+					}
+				}
+			}
+			errs <- err
+		}()
+	}
+	for range 8 {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
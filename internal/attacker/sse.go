@@ -0,0 +1,143 @@
+package attacker
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// SSEEvent is a single dispatched Server-Sent Event.
+type SSEEvent struct {
+	Event string // Event type ("message" when the stream does not set one)
+	Data  string // Payload; multiple data lines are joined with "\n"
+	ID    string // Last event ID seen on the stream
+}
+
+// errStopStream is returned by an event handler to stop reading without reporting an error.
+var errStopStream = errors.New("stop stream")
+
+// maxSSELine bounds a single line of an event stream, so a stream that never
+// sends a line break cannot grow a worker's buffer without limit.
+const maxSSELine = 8 << 20
+
+// sseLines returns a bufio.SplitFunc for the line endings of an event stream:
+// CRLF, LF or a lone CR. A line is returned as soon as its CR arrives; an LF
+// that turns out to follow it is skipped, so events are dispatched without
+// waiting for the next byte.
+func sseLines() bufio.SplitFunc {
+	afterCR := false
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		start := 0
+		if afterCR && len(data) > 0 {
+			afterCR = false
+			if data[0] == '\n' {
+				start = 1
+			}
+		}
+		if i := bytes.IndexAny(data[start:], "\r\n"); i >= 0 {
+			i += start
+			afterCR = data[i] == '\r'
+			return i + 1, data[start:i], nil
+		}
+		if atEOF && len(data) > start {
+			return len(data), data[start:], nil
+		}
+		return start, nil, nil
+	}
+}
+
+// ReadSSE parses an event stream incrementally and calls onEvent for every dispatched event.
+// Reading stops when the stream ends, when one of the stop conditions in opts is met,
+// or when onEvent returns an error. Returns nil on a clean stop.
+func ReadSSE(r io.Reader, opts *models.SSEOptions, onEvent func(SSEEvent) error) error {
+	var (
+		eventType string
+		lastID    string
+		data      strings.Builder
+		hasData   bool
+		count     int
+	)
+
+	dispatch := func() error {
+		if !hasData {
+			eventType = ""
+			return nil
+		}
+		ev := SSEEvent{Event: eventType, Data: data.String(), ID: lastID}
+		if ev.Event == "" {
+			ev.Event = "message"
+		}
+		eventType = ""
+		data.Reset()
+		hasData = false
+		count++
+
+		if err := onEvent(ev); err != nil {
+			return err
+		}
+		if opts != nil {
+			if opts.MaxEvents > 0 && count >= opts.MaxEvents {
+				return errStopStream
+			}
+			if opts.UntilEvent != "" && ev.Event == opts.UntilEvent {
+				return errStopStream
+			}
+			if opts.UntilContains != "" && strings.Contains(ev.Data, opts.UntilContains) {
+				return errStopStream
+			}
+		}
+		return nil
+	}
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 16*1024), maxSSELine)
+	sc.Split(sseLines())
+	for sc.Scan() {
+		line := sc.Bytes()
+		if len(line) == 0 {
+			if err := dispatch(); err != nil {
+				if err == errStopStream {
+					return nil
+				}
+				return err
+			}
+			continue
+		}
+		if line[0] == ':' { // Lines starting with ':' are comments
+			continue
+		}
+		field, value := line, []byte(nil)
+		if idx := bytes.IndexByte(line, ':'); idx != -1 {
+			field = line[:idx]
+			value = line[idx+1:]
+			if len(value) > 0 && value[0] == ' ' {
+				value = value[1:]
+			}
+		}
+		switch string(field) {
+		case "event":
+			eventType = string(value)
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.Write(value)
+			hasData = true
+		case "id":
+			lastID = string(value)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		if err == bufio.ErrTooLong {
+			return fmt.Errorf("event stream line longer than %d MiB", maxSSELine>>20)
+		}
+		return err
+	}
+	// An event without its terminating blank line is incomplete and is discarded.
+	return nil
+}
@@ -0,0 +1,238 @@
+package attacker
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// readEvents parses stream with ReadSSE and returns the dispatched events.
+func readEvents(t *testing.T, r io.Reader, opts *models.SSEOptions) ([]SSEEvent, error) {
+	t.Helper()
+	var events []SSEEvent
+	err := ReadSSE(r, opts, func(ev SSEEvent) error {
+		events = append(events, ev)
+		return nil
+	})
+	return events, err
+}
+
+func TestReadSSE(t *testing.T) {
+	long := strings.Repeat("x", 100*1024) // Longer than the initial read buffer
+	tests := []struct {
+		name   string
+		stream string
+		want   []SSEEvent
+	}{
+		{"single event", "data: hello\n\n", []SSEEvent{{Event: "message", Data: "hello"}}},
+		{"multi-line data", "data: line 1\ndata: line 2\ndata:\ndata: line 4\n\n", []SSEEvent{{Event: "message", Data: "line 1\nline 2\n\nline 4"}}},
+		{"event type and id", "event: token\nid: 7\ndata: {\"t\":1}\n\n", []SSEEvent{{Event: "token", Data: `{"t":1}`, ID: "7"}}},
+		{"id carries over", "id: 1\ndata: a\n\ndata: b\n\n", []SSEEvent{{Event: "message", Data: "a", ID: "1"}, {Event: "message", Data: "b", ID: "1"}}},
+		{"event type resets", "event: ping\ndata: a\n\ndata: b\n\n", []SSEEvent{{Event: "ping", Data: "a"}, {Event: "message", Data: "b"}}},
+		{"comments", ": keep-alive\ndata: a\n: more\ndata: b\n\n:\n\n", []SSEEvent{{Event: "message", Data: "a\nb"}}},
+		{"no space after colon", "data:tight\n\n", []SSEEvent{{Event: "message", Data: "tight"}}},
+		{"only the first space is stripped", "data:  two\n\n", []SSEEvent{{Event: "message", Data: " two"}}},
+		{"field without colon", "data\n\n", []SSEEvent{{Event: "message", Data: ""}}},
+		{"unknown fields", "retry: 1000\nfoo: bar\ndata: a\n\n", []SSEEvent{{Event: "message", Data: "a"}}},
+		{"blank lines without data", "\n\nevent: x\n\n", nil},
+		{"crlf", "event: a\r\ndata: 1\r\ndata: 2\r\n\r\ndata: 3\r\n\r\n", []SSEEvent{{Event: "a", Data: "1\n2"}, {Event: "message", Data: "3"}}},
+		{"cr", "event: a\rdata: 1\rdata: 2\r\rdata: 3\r\r", []SSEEvent{{Event: "a", Data: "1\n2"}, {Event: "message", Data: "3"}}},
+		{"mixed endings", "data: 1\r\ndata: 2\rdata: 3\n\r\n", []SSEEvent{{Event: "message", Data: "1\n2\n3"}}},
+		{"over-long line", "data: " + long + "\n\ndata: after\n\n", []SSEEvent{{Event: "message", Data: long}, {Event: "message", Data: "after"}}},
+		{"incomplete last event", "data: a\n\ndata: b\n", []SSEEvent{{Event: "message", Data: "a"}}},
+		{"no trailing newline", "data: a\n\ndata: b", []SSEEvent{{Event: "message", Data: "a"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readEvents(t, strings.NewReader(tt.stream), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d events %+v, want %d", len(got), got, len(tt.want))
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("event %d: got %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// oneByteReader returns the stream a byte at a time, splitting every CRLF.
+type oneByteReader struct{ r io.Reader }
+
+func (o oneByteReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return o.r.Read(p[:1])
+}
+
+func TestReadSSESplitCRLF(t *testing.T) {
+	got, err := readEvents(t, oneByteReader{strings.NewReader("data: 1\r\ndata: 2\r\n\r\ndata: 3\r\n\r\n")}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Data != "1\n2" || got[1].Data != "3" {
+		t.Fatalf("events %+v", got)
+	}
+}
+
+// TestReadSSECRDispatch checks that an event ending in CR is dispatched before
+// the next byte arrives, as a live stream may not send one for a while.
+func TestReadSSECRDispatch(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	got := make(chan SSEEvent, 1)
+	go ReadSSE(pr, nil, func(ev SSEEvent) error {
+		got <- ev
+		return nil
+	})
+	if _, err := pw.Write([]byte("data: now\r\r")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ev := <-got:
+		if ev.Data != "now" {
+			t.Fatalf("event %+v", ev)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("event not dispatched until more data arrives")
+	}
+}
+
+func TestReadSSELineLimit(t *testing.T) {
+	stream := io.MultiReader(strings.NewReader("data: "), io.LimitReader(neverNewline{}, maxSSELine+1))
+	if _, err := readEvents(t, stream, nil); err == nil || !strings.Contains(err.Error(), "longer than") {
+		t.Fatalf("error %v, want the line limit", err)
+	}
+}
+
+// neverNewline is an endless stream without line breaks.
+type neverNewline struct{}
+
+func (neverNewline) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = 'x'
+	}
+	return len(p), nil
+}
+
+func TestReadSSEStopConditions(t *testing.T) {
+	stream := "data: 1\n\nevent: progress\ndata: 2\n\ndata: [DONE]\n\nevent: end\ndata: 4\n\ndata: 5\n\n"
+	tests := []struct {
+		name string
+		opts *models.SSEOptions
+		want int
+	}{
+		{"until the stream ends", nil, 5},
+		{"max events", &models.SSEOptions{MaxEvents: 2}, 2},
+		{"max events beyond the stream", &models.SSEOptions{MaxEvents: 10}, 5},
+		{"until event", &models.SSEOptions{UntilEvent: "end"}, 4},
+		{"until contains", &models.SSEOptions{UntilContains: "[DONE]"}, 3},
+		{"first condition wins", &models.SSEOptions{MaxEvents: 4, UntilEvent: "progress"}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readEvents(t, strings.NewReader(stream), tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != tt.want {
+				t.Fatalf("read %d events, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+// sseEngine returns an engine whose clients are set up the way Attack does it.
+func sseEngine(timeout time.Duration) *Engine {
+	e := NewEngine()
+	e.client = &http.Client{Timeout: timeout}
+	e.streamClient = &http.Client{}
+	e.acceptEncoding = DefaultAcceptEncoding
+	return e
+}
+
+// endlessStream serves one event every interval until the client goes away.
+func endlessStream(interval time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for i := 0; ; i++ {
+			if _, err := fmt.Fprintf(w, "id: %d\ndata: {\"n\":%d}\n\n", i, i); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+				return
+			case <-time.After(interval):
+			}
+		}
+	}))
+}
+
+func runSSEStep(t *testing.T, e *Engine, url string, opts *models.SSEOptions) (models.Result, map[string]string) {
+	t.Helper()
+	step := models.Step{Name: "stream", URL: url, Method: "GET", Stream: models.StreamSSE, SSE: opts, EventExtract: map[string]string{"last": "n"}}
+	cs := compiledStep{url: CompileTemplate(url), body: CompileTemplate(""), headers: map[string]*CompiledTemplate{}, vars: map[string]*CompiledTemplate{}}
+	session := map[string]string{}
+	return e.executeCompiledStep(t.Context(), step, cs, session), session
+}
+
+func TestSSEStepMaxEvents(t *testing.T) {
+	srv := endlessStream(time.Millisecond)
+	defer srv.Close()
+
+	res, session := runSSEStep(t, sseEngine(5*time.Second), srv.URL, &models.SSEOptions{MaxEvents: 5})
+	if res.Error != nil {
+		t.Fatal(res.Error)
+	}
+	if !res.Streamed || res.Events != 5 || len(res.EventGaps) != 4 || res.TimeToFirstEvent <= 0 {
+		t.Fatalf("streamed %v, %d events, %d gaps, first after %s", res.Streamed, res.Events, len(res.EventGaps), res.TimeToFirstEvent)
+	}
+	if session["last"] != "4" {
+		t.Fatalf("extracted %q, want the last event's value", session["last"])
+	}
+}
+
+// TestSSEStepMaxDuration checks that max_duration ends a stream cleanly, and that
+// the stream is not cut off by the client timeout meanwhile.
+func TestSSEStepMaxDuration(t *testing.T) {
+	srv := endlessStream(20 * time.Millisecond)
+	defer srv.Close()
+
+	res, _ := runSSEStep(t, sseEngine(100*time.Millisecond), srv.URL, &models.SSEOptions{MaxDuration: 400 * time.Millisecond})
+	if res.Error != nil {
+		t.Fatalf("stream ended with %v", res.Error)
+	}
+	if res.Latency < 400*time.Millisecond || res.Latency > 2*time.Second {
+		t.Fatalf("stream read for %s, want about 400ms", res.Latency)
+	}
+	if res.Events < 5 {
+		t.Fatalf("only %d events in 400ms", res.Events)
+	}
+}
+
+// TestSSEStepTimeout checks that without max_duration the request timeout still
+// bounds a stream that never ends, and that hitting it is an error.
+func TestSSEStepTimeout(t *testing.T) {
+	srv := endlessStream(20 * time.Millisecond)
+	defer srv.Close()
+
+	res, _ := runSSEStep(t, sseEngine(200*time.Millisecond), srv.URL, nil)
+	if res.Error == nil {
+		t.Fatal("a stream cut off by the timeout counted as a success")
+	}
+	if res.Latency > 2*time.Second || res.Events == 0 {
+		t.Fatalf("read %d events for %s", res.Events, res.Latency)
+	}
+}
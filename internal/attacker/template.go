@@ -100,10 +100,20 @@ func (ct *CompiledTemplate) Execute(vp *VariableProcessor, session map[string]st
 	return sb.String()
 }
 
+// IsStatic reports whether the template renders the same string on every request.
+func (ct *CompiledTemplate) IsStatic() bool {
+	return !ct.hasVars
+}
+
 // compiledStep holds pre-compiled templates for a single scenario step.
 type compiledStep struct {
-	url     *CompiledTemplate
-	body    *CompiledTemplate
-	headers map[string]*CompiledTemplate
-	vars    map[string]*CompiledTemplate
+	url       *CompiledTemplate
+	body      *CompiledTemplate
+	multipart *Multipart  // set instead of body for multipart/form-data steps
+	stream    *StreamBody // set instead of body for streamed bodies (no templating)
+	// compressedBody holds the pre-compressed body when request compression is on
+	// and the body template is static, so it is compressed once instead of per request.
+	compressedBody []byte
+	headers        map[string]*CompiledTemplate
+	vars           map[string]*CompiledTemplate
 }
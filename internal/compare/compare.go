@@ -0,0 +1,248 @@
+// Package compare computes the differences between two test reports and flags
+// regressions that exceed configurable tolerances.
+package compare
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// Metric kinds determine how a value is compared and formatted.
+const (
+	KindLatency = iota // milliseconds; higher is worse
+	KindRate           // requests per second; lower is worse
+	KindPercent        // percent; compared in percentage points
+	KindNumber         // informational counts
+)
+
+// Tolerances are the allowed changes before a metric counts as a regression.
+// A negative tolerance disables the check.
+type Tolerances struct {
+	Latency   float64 // Max increase of p50/p90/p95/p99, in percent of the baseline
+	RPS       float64 // Max decrease of RPS, in percent of the baseline
+	ErrorRate float64 // Max increase of the error rate, in percentage points
+}
+
+// DefaultTolerances returns the tolerances used by `sayl diff` when none are given.
+func DefaultTolerances() Tolerances {
+	return Tolerances{Latency: 10, RPS: 10, ErrorRate: 1}
+}
+
+// Delta is the change of a single metric between the two runs.
+type Delta struct {
+	Metric     string
+	Kind       int
+	Baseline   float64
+	Candidate  float64
+	Change     float64 // Candidate - Baseline
+	ChangePct  float64 // Relative change in percent (0 when the baseline is 0)
+	Limit      string  // Tolerance applied, e.g. "+10%" (empty when not checked)
+	Regression bool
+}
+
+// StatusDelta is the change of one status code between the two runs.
+type StatusDelta struct {
+	Code         string
+	Baseline     int
+	Candidate    int
+	BaselinePct  float64 // Share of all requests
+	CandidatePct float64 // Share of all requests
+}
+
+// StepDelta holds the metric changes of a scenario step present in both runs.
+type StepDelta struct {
+	Name    string
+	Metrics []Delta
+}
+
+// Comparison is the full result of comparing a candidate run against a baseline.
+type Comparison struct {
+	BaselineName  string
+	CandidateName string
+	Baseline      models.Report
+	Candidate     models.Report
+	Tolerances    Tolerances
+	Metrics       []Delta
+	Statuses      []StatusDelta
+	Steps         []StepDelta
+	Regressions   int
+}
+
+// EmbeddedReportTag opens the script element holding the report JSON in HTML
+// reports written with embedding enabled.
+const EmbeddedReportTag = `<script type="application/json" id="sayl-report">`
+
+// Load reads a report.json file, or an HTML report with the report JSON embedded.
+func Load(path string) (models.Report, error) {
+	var rep models.Report
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rep, fmt.Errorf("failed to read report '%s': %w", path, err)
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '<' {
+		_, after, ok := bytes.Cut(data, []byte(EmbeddedReportTag))
+		if !ok {
+			return rep, fmt.Errorf("HTML report '%s' has no embedded JSON (write it with --embed-json)", path)
+		}
+		data, _, _ = bytes.Cut(after, []byte("</script>"))
+	}
+	if err := json.Unmarshal(data, &rep); err != nil {
+		return rep, fmt.Errorf("failed to parse report '%s': %w", path, err)
+	}
+	return rep, nil
+}
+
+// Compare computes the deltas between baseline and candidate and counts regressions.
+func Compare(baseline, candidate models.Report, tol Tolerances) Comparison {
+	c := Comparison{
+		Baseline:   baseline,
+		Candidate:  candidate,
+		Tolerances: tol,
+	}
+
+	c.Metrics = []Delta{
+		latency("P50", baseline.P50, candidate.P50, tol),
+		latency("P90", baseline.P90, candidate.P90, tol),
+		latency("P95", baseline.P95, candidate.P95, tol),
+		latency("P99", baseline.P99, candidate.P99, tol),
+		newDelta("Max", KindLatency, ms(baseline.Max), ms(candidate.Max)),
+		rps(baseline.RPS, candidate.RPS, tol),
+		errorRate(pct(baseline.FailureCount, baseline.TotalRequests), pct(candidate.FailureCount, candidate.TotalRequests), tol),
+		newDelta("Requests", KindNumber, float64(baseline.TotalRequests), float64(candidate.TotalRequests)),
+		newDelta("Throughput (MB/s)", KindNumber, baseline.Throughput, candidate.Throughput),
+	}
+
+	c.Statuses = statusDeltas(baseline, candidate)
+
+	for _, bs := range baseline.Steps {
+		for _, cs := range candidate.Steps {
+			if bs.Name != cs.Name {
+				continue
+			}
+			c.Steps = append(c.Steps, StepDelta{
+				Name: bs.Name,
+				Metrics: []Delta{
+					latency("P95", bs.P95, cs.P95, tol),
+					latency("P99", bs.P99, cs.P99, tol),
+					rps(bs.RPS, cs.RPS, tol),
+					errorRate(pct(bs.Failures, bs.Requests), pct(cs.Failures, cs.Requests), tol),
+				},
+			})
+		}
+	}
+
+	for _, d := range c.Metrics {
+		if d.Regression {
+			c.Regressions++
+		}
+	}
+	for _, st := range c.Steps {
+		for _, d := range st.Metrics {
+			if d.Regression {
+				c.Regressions++
+			}
+		}
+	}
+	return c
+}
+
+func newDelta(metric string, kind int, baseline, candidate float64) Delta {
+	d := Delta{
+		Metric:    metric,
+		Kind:      kind,
+		Baseline:  baseline,
+		Candidate: candidate,
+		Change:    candidate - baseline,
+	}
+	if baseline != 0 {
+		d.ChangePct = (candidate - baseline) / baseline * 100
+	}
+	return d
+}
+
+// latency flags an increase beyond tol.Latency percent.
+func latency(metric string, baseline, candidate time.Duration, tol Tolerances) Delta {
+	d := newDelta(metric, KindLatency, ms(baseline), ms(candidate))
+	if tol.Latency >= 0 && baseline > 0 {
+		d.Limit = fmt.Sprintf("+%g%%", tol.Latency)
+		d.Regression = d.ChangePct > tol.Latency
+	}
+	return d
+}
+
+// rps flags a decrease beyond tol.RPS percent.
+func rps(baseline, candidate float64, tol Tolerances) Delta {
+	d := newDelta("RPS", KindRate, baseline, candidate)
+	if tol.RPS >= 0 && baseline > 0 {
+		d.Limit = fmt.Sprintf("-%g%%", tol.RPS)
+		d.Regression = d.ChangePct < -tol.RPS
+	}
+	return d
+}
+
+// errorRate flags an increase beyond tol.ErrorRate percentage points.
+func errorRate(baseline, candidate float64, tol Tolerances) Delta {
+	d := newDelta("Error rate", KindPercent, baseline, candidate)
+	if tol.ErrorRate >= 0 {
+		d.Limit = fmt.Sprintf("+%g pts", tol.ErrorRate)
+		d.Regression = d.Change > tol.ErrorRate
+	}
+	return d
+}
+
+// statusDeltas lists every status code seen in either run, sorted by code.
+func statusDeltas(baseline, candidate models.Report) []StatusDelta {
+	codes := make(map[string]bool)
+	for code := range baseline.StatusCodes {
+		codes[code] = true
+	}
+	for code := range candidate.StatusCodes {
+		codes[code] = true
+	}
+
+	out := make([]StatusDelta, 0, len(codes))
+	for code := range codes {
+		b, c := baseline.StatusCodes[code], candidate.StatusCodes[code]
+		out = append(out, StatusDelta{
+			Code:         code,
+			Baseline:     b,
+			Candidate:    c,
+			BaselinePct:  pct(int64(b), baseline.TotalRequests),
+			CandidatePct: pct(int64(c), candidate.TotalRequests),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Code < out[j].Code })
+	return out
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// pct returns part as a percentage of total.
+func pct(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
+// ParseTolerance parses a tolerance flag such as "10%", "10" or "off".
+func ParseTolerance(s string) (float64, error) {
+	if s == "off" {
+		return -1, nil
+	}
+	v, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(s, "%")), 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid tolerance '%s' (use a non-negative number such as 10%%, or 'off')", s)
+	}
+	return v, nil
+}
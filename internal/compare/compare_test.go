@@ -0,0 +1,212 @@
+package compare
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// sampleReport returns a run of 1000 requests with failures failed ones.
+func sampleReport(p95 time.Duration, rps float64, failures int64) models.Report {
+	return models.Report{
+		TotalRequests: 1000,
+		SuccessCount:  1000 - failures,
+		FailureCount:  failures,
+		RPS:           rps,
+		P50:           p95 / 2,
+		P90:           p95 * 9 / 10,
+		P95:           p95,
+		P99:           p95 * 2,
+		Max:           p95 * 4,
+		StatusCodes:   map[string]int{"200": int(1000 - failures), "500": int(failures)},
+	}
+}
+
+func findDelta(t *testing.T, deltas []Delta, metric string) Delta {
+	t.Helper()
+	for _, d := range deltas {
+		if d.Metric == metric {
+			return d
+		}
+	}
+	t.Fatalf("no %s delta", metric)
+	return Delta{}
+}
+
+func TestCompareRegressions(t *testing.T) {
+	base := sampleReport(100*time.Millisecond, 200, 5)
+	tests := []struct {
+		name      string
+		candidate models.Report
+		tol       Tolerances
+		regressed []string // Metrics flagged as regressions
+	}{
+		{"unchanged", base, DefaultTolerances(), nil},
+		{"latency within tolerance", sampleReport(109*time.Millisecond, 200, 5), DefaultTolerances(), nil},
+		{"latency over tolerance", sampleReport(111*time.Millisecond, 200, 5), DefaultTolerances(), []string{"P50", "P90", "P95", "P99"}},
+		{"latency improved", sampleReport(50*time.Millisecond, 200, 5), DefaultTolerances(), nil},
+		{"latency check off", sampleReport(300*time.Millisecond, 200, 5), Tolerances{Latency: -1, RPS: 10, ErrorRate: 1}, nil},
+		{"rps within tolerance", sampleReport(100*time.Millisecond, 181, 5), DefaultTolerances(), nil},
+		{"rps over tolerance", sampleReport(100*time.Millisecond, 179, 5), DefaultTolerances(), []string{"RPS"}},
+		{"rps increased", sampleReport(100*time.Millisecond, 400, 5), DefaultTolerances(), nil},
+		{"error rate within tolerance", sampleReport(100*time.Millisecond, 200, 15), DefaultTolerances(), nil},
+		{"error rate over tolerance", sampleReport(100*time.Millisecond, 200, 16), DefaultTolerances(), []string{"Error rate"}},
+		{"error rate zero tolerance", sampleReport(100*time.Millisecond, 200, 6), Tolerances{Latency: 10, RPS: 10, ErrorRate: 0}, []string{"Error rate"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Compare(base, tt.candidate, tt.tol)
+			var got []string
+			for _, d := range c.Metrics {
+				if d.Regression {
+					got = append(got, d.Metric)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.regressed, ",") {
+				t.Fatalf("regressions %v, want %v", got, tt.regressed)
+			}
+			if c.Regressions != len(tt.regressed) {
+				t.Fatalf("counted %d regressions, want %d", c.Regressions, len(tt.regressed))
+			}
+		})
+	}
+}
+
+func TestCompareDeltas(t *testing.T) {
+	c := Compare(sampleReport(100*time.Millisecond, 200, 5), sampleReport(150*time.Millisecond, 100, 20), DefaultTolerances())
+
+	p95 := findDelta(t, c.Metrics, "P95")
+	if p95.Baseline != 100 || p95.Candidate != 150 || p95.Change != 50 || p95.ChangePct != 50 || p95.Limit != "+10%" {
+		t.Errorf("P95 delta %+v", p95)
+	}
+	rps := findDelta(t, c.Metrics, "RPS")
+	if rps.ChangePct != -50 || rps.Limit != "-10%" {
+		t.Errorf("RPS delta %+v", rps)
+	}
+	errRate := findDelta(t, c.Metrics, "Error rate")
+	if errRate.Baseline != 0.5 || errRate.Candidate != 2 || errRate.Limit != "+1 pts" {
+		t.Errorf("error rate delta %+v", errRate)
+	}
+	if max := findDelta(t, c.Metrics, "Max"); max.Limit != "" || max.Regression {
+		t.Errorf("Max is informational, got %+v", max)
+	}
+
+	// A zero baseline has no relative change and is never a latency or rps regression.
+	c = Compare(models.Report{}, sampleReport(100*time.Millisecond, 200, 0), DefaultTolerances())
+	if d := findDelta(t, c.Metrics, "P95"); d.ChangePct != 0 || d.Limit != "" || d.Regression {
+		t.Errorf("P95 against an empty baseline: %+v", d)
+	}
+}
+
+func TestCompareStatuses(t *testing.T) {
+	base := sampleReport(100*time.Millisecond, 200, 0)
+	base.StatusCodes = map[string]int{"200": 1000}
+	cand := sampleReport(100*time.Millisecond, 200, 100)
+	cand.StatusCodes = map[string]int{"200": 900, "503": 60, "429": 40}
+
+	got := Compare(base, cand, DefaultTolerances()).Statuses
+	want := []StatusDelta{
+		{Code: "200", Baseline: 1000, Candidate: 900, BaselinePct: 100, CandidatePct: 90},
+		{Code: "429", Baseline: 0, Candidate: 40, BaselinePct: 0, CandidatePct: 4},
+		{Code: "503", Baseline: 0, Candidate: 60, BaselinePct: 0, CandidatePct: 6},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("statuses %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("status %d: %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCompareSteps(t *testing.T) {
+	step := func(name string, p95 time.Duration, failures int64) models.StepStats {
+		return models.StepStats{Name: name, Requests: 100, Failures: failures, RPS: 20, P95: p95, P99: p95}
+	}
+	base := sampleReport(100*time.Millisecond, 200, 0)
+	base.Steps = []models.StepStats{step("login", 50*time.Millisecond, 0), step("search", 80*time.Millisecond, 0)}
+	cand := sampleReport(100*time.Millisecond, 200, 0)
+	cand.Steps = []models.StepStats{step("search", 120*time.Millisecond, 0), step("checkout", time.Second, 50)}
+
+	c := Compare(base, cand, DefaultTolerances())
+	// Only steps present in both runs are compared.
+	if len(c.Steps) != 1 || c.Steps[0].Name != "search" {
+		t.Fatalf("steps %+v, want only search", c.Steps)
+	}
+	if !findDelta(t, c.Steps[0].Metrics, "P95").Regression || !findDelta(t, c.Steps[0].Metrics, "P99").Regression {
+		t.Fatalf("search latency regression not flagged: %+v", c.Steps[0].Metrics)
+	}
+	// Step regressions count towards the total.
+	if c.Regressions != 2 {
+		t.Fatalf("counted %d regressions, want 2", c.Regressions)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	const reportJSON = `{"target_url":"http://api.local","total_requests":42,"p95":5000000}`
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"json", reportJSON, ""},
+		{"json with whitespace", "\n  " + reportJSON + "\n", ""},
+		{"html with embedded json", "<!DOCTYPE html>\n<html><body>\n" + EmbeddedReportTag + reportJSON + "</script>\n<script>draw()</script></body></html>", ""},
+		{"html without embedded json", "<!DOCTYPE html><html><body><script>draw()</script></body></html>", "no embedded JSON"},
+		{"invalid json", `{"total_requests":`, "failed to parse"},
+		{"html with invalid json", EmbeddedReportTag + `{"total_requests":</script>`, "failed to parse"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "report")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			rep, err := Load(path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rep.TargetURL != "http://api.local" || rep.TotalRequests != 42 || rep.P95 != 5*time.Millisecond {
+				t.Fatalf("loaded %+v", rep)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err == nil || !strings.Contains(err.Error(), "failed to read") {
+		t.Fatalf("missing file: %v", err)
+	}
+}
+
+func TestParseTolerance(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64
+		wantErr bool
+	}{
+		{"10%", 10, false},
+		{"2.5", 2.5, false},
+		{" 5 %", 5, false},
+		{"0", 0, false},
+		{"off", -1, false},
+		{"-5%", 0, true},
+		{"ten", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseTolerance(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseTolerance(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
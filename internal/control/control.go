@@ -0,0 +1,233 @@
+// Package control serves a small HTTP API to steer a running test from scripts:
+// pause and resume it, change its target rate and skip to the next stage. It is
+// the HTTP counterpart of the dashboard's control keys.
+//
+// The API is meant for scripts, not browsers. Requests a web page could send on
+// its own are refused: cross-origin requests, Host names other than localhost or
+// an IP address (DNS rebinding), and POSTs without Content-Type: application/json,
+// which browsers only send cross-origin after a preflight the API never allows.
+// An optional token is required as "Authorization: Bearer <token>".
+package control
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Amr-9/sayl/internal/runner"
+)
+
+// Source is the run being controlled. *runner.Runner implements it.
+type Source interface {
+	Paused() bool
+	TargetRate() float64
+	Stage() (int, int)
+	Elapsed() time.Duration
+	Pause() error
+	Resume() error
+	AdjustRate(delta float64) (float64, error)
+	SkipStage() (int, error)
+}
+
+// Server exposes the control endpoints for the run it is attached to.
+type Server struct {
+	listener net.Listener
+	server   *http.Server
+	token    string // Required bearer token; empty for none
+
+	mu  sync.RWMutex
+	src Source
+}
+
+// status is the state returned by every endpoint.
+type status struct {
+	Paused     bool    `json:"paused"`
+	TargetRate float64 `json:"target_rate"`
+	Stage      int     `json:"stage,omitempty"`  // 1-based; 0 without stages
+	Stages     int     `json:"stages,omitempty"` // Number of stages
+	Elapsed    float64 `json:"elapsed"`          // Test time in seconds, without pauses
+}
+
+// Listen binds addr (e.g. "127.0.0.1:6565") and starts serving in the background.
+// A non-empty token must be sent with every request. The endpoints are:
+//
+//	GET  /status           current state
+//	POST /pause            stop sending new requests
+//	POST /resume           continue a paused test
+//	POST /rate?delta=N     change the target rate by N req/s (N may be negative)
+//	POST /rate?rate=N      set the target rate to N req/s
+//	POST /stage/next       skip to the next stage
+func Listen(addr, token string) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start control API on '%s': %w", addr, err)
+	}
+
+	s := &Server{listener: ln, token: token}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", s.handle(func(Source) error { return nil }))
+	mux.HandleFunc("POST /pause", s.handle(Source.Pause))
+	mux.HandleFunc("POST /resume", s.handle(Source.Resume))
+	mux.HandleFunc("POST /stage/next", s.handle(func(src Source) error {
+		_, err := src.SkipStage()
+		return err
+	}))
+	mux.HandleFunc("POST /rate", s.handleRate)
+	s.server = &http.Server{Handler: s.guard(mux), ReadHeaderTimeout: 5 * time.Second}
+	go s.server.Serve(ln)
+	return s, nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Loopback reports whether the server only accepts connections from this machine.
+func (s *Server) Loopback() bool {
+	addr, ok := s.listener.Addr().(*net.TCPAddr)
+	return ok && addr.IP.IsLoopback()
+}
+
+// Attach makes the server control r. It implements runner.Observer.
+func (s *Server) Attach(r *runner.Runner) {
+	s.SetSource(r)
+}
+
+// SetSource makes the server control src.
+func (s *Server) SetSource(src Source) {
+	s.mu.Lock()
+	s.src = src
+	s.mu.Unlock()
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	return s.server.Close()
+}
+
+// guard refuses the requests described in the package documentation before they
+// reach the endpoints.
+func (s *Server) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorized := false
+		if s.token != "" {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) != 1 {
+				writeError(w, http.StatusUnauthorized, "missing or wrong token; send it as 'Authorization: Bearer' followed by the token")
+				return
+			}
+			authorized = true
+		}
+		if origin := r.Header.Get("Origin"); origin != "" && !sameOrigin(origin, r.Host) {
+			writeError(w, http.StatusForbidden, "cross-origin requests are not allowed")
+			return
+		}
+		if !authorized && !localHost(r.Host) {
+			writeError(w, http.StatusForbidden, "the Host header must be localhost or an IP address unless a token is set")
+			return
+		}
+		if r.Method == http.MethodPost && !authorized {
+			if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct != "application/json" {
+				writeError(w, http.StatusUnsupportedMediaType, "POST requests must send 'Content-Type: application/json'")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// sameOrigin reports whether an Origin header names the host the request was sent to.
+func sameOrigin(origin, host string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && u.Host != "" && strings.EqualFold(u.Host, host)
+}
+
+// localHost reports whether a Host header is "localhost" or an IP address, which a
+// page served from another domain cannot send, even after rebinding its DNS name.
+func localHost(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.Trim(host, "[]")
+	return strings.EqualFold(host, "localhost") || net.ParseIP(host) != nil
+}
+
+func (s *Server) source() Source {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.src
+}
+
+// handle runs action on the attached run and answers with the resulting state.
+// A refused action (e.g. resuming a test that is not paused) is a 409.
+func (s *Server) handle(action func(Source) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		src := s.source()
+		if src == nil {
+			writeError(w, http.StatusServiceUnavailable, "no test is running")
+			return
+		}
+		if err := action(src); err != nil {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeStatus(w, src)
+	}
+}
+
+func (s *Server) handleRate(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var delta, target float64
+	var err error
+	switch {
+	case q.Has("delta"):
+		delta, err = strconv.ParseFloat(q.Get("delta"), 64)
+	case q.Has("rate"):
+		target, err = strconv.ParseFloat(q.Get("rate"), 64)
+		if err == nil && target <= 0 {
+			err = fmt.Errorf("rate must be greater than 0")
+		}
+	default:
+		err = fmt.Errorf("expected ?delta=N or ?rate=N")
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.handle(func(src Source) error {
+		if q.Has("rate") {
+			delta = target - src.TargetRate()
+		}
+		_, err := src.AdjustRate(delta)
+		return err
+	})(w, r)
+}
+
+func writeStatus(w http.ResponseWriter, src Source) {
+	st := status{
+		Paused:     src.Paused(),
+		TargetRate: src.TargetRate(),
+		Elapsed:    src.Elapsed().Seconds(),
+	}
+	if stage, count := src.Stage(); count > 0 {
+		st.Stage, st.Stages = min(stage+1, count), count
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(st)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
@@ -102,11 +102,14 @@ func RunDebugMode(cfg *models.Config) error {
 	if len(steps) == 0 {
 		// Create a single step from the main config
 		steps = []models.Step{{
-			Name:    "Main Request",
-			URL:     cfg.URL,
-			Method:  cfg.Method,
-			Headers: cfg.Headers,
-			Body:    string(cfg.Body),
+			Name:       "Main Request",
+			URL:        cfg.URL,
+			Method:     cfg.Method,
+			Headers:    cfg.Headers,
+			Body:       string(cfg.Body),
+			GraphQL:    cfg.GraphQL,
+			Multipart:  cfg.Multipart,
+			BodyStream: cfg.BodyStream,
 		}}
 	}
 
@@ -163,22 +166,66 @@ func executeDebugStep(client *http.Client, vp *attacker.VariableProcessor, step
 		method = "GET"
 	}
 	bodyStr := vp.Process(step.Body, session)
+	var body io.Reader = bytes.NewBufferString(bodyStr)
+	var contentType, compressNote string
+	contentLength := int64(-1)
+	if step.BodyStream != nil {
+		sb, err := attacker.CompileBodyStream(step.BodyStream)
+		if err != nil {
+			return false, err
+		}
+		defer sb.Close()
+		body, contentLength = sb.Reader()
+		bodyStr = sb.Describe()
+	} else if step.Multipart != nil {
+		mp, err := attacker.CompileMultipart(step.Multipart)
+		if err != nil {
+			return false, err
+		}
+		defer mp.Close()
+		body, contentLength, contentType = mp.Build(vp, session)
+		bodyStr = fmt.Sprintf("[multipart/form-data, %d bytes]\n- %s", contentLength, strings.Join(mp.Describe(), "\n- "))
+	} else if cfg.CompressRequest != "" && bodyStr != "" {
+		compressed, err := attacker.CompressBody(cfg.CompressRequest, []byte(bodyStr))
+		if err != nil {
+			return false, fmt.Errorf("failed to compress request body: %w", err)
+		}
+		body = bytes.NewReader(compressed)
+		contentLength = int64(len(compressed))
+		compressNote = fmt.Sprintf("Body sent %s-compressed: %d → %d bytes", cfg.CompressRequest, len(bodyStr), len(compressed))
+	}
 
 	// Create request
-	req, err := http.NewRequest(method, url, bytes.NewBufferString(bodyStr))
+	req, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return false, fmt.Errorf("failed to create request: %w", err)
 	}
+	if contentLength >= 0 {
+		req.ContentLength = contentLength
+	}
 
 	// Set default and custom headers - same as real attacker
 	req.Header.Set("User-Agent", "Sayl/1.0 (Debug Mode)")
 	req.Header.Set("Accept", "*/*")
+	if step.Stream == models.StreamSSE {
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set("Cache-Control", "no-cache")
+	}
 	for k, v := range step.Headers {
 		req.Header.Set(k, vp.Process(v, session))
 	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if compressNote != "" {
+		req.Header.Set("Content-Encoding", cfg.CompressRequest)
+	}
 
 	// Print Request
 	printRequest(req, bodyStr)
+	if compressNote != "" {
+		fmt.Printf("%s%s%s\n", colorDim, compressNote, colorReset)
+	}
 
 	// 2. Execute Request
 	start := time.Now()
@@ -191,6 +238,10 @@ func executeDebugStep(client *http.Client, vp *attacker.VariableProcessor, step
 	}
 	defer resp.Body.Close()
 
+	if step.Stream == models.StreamSSE && resp.StatusCode < 300 {
+		return executeDebugStream(resp, step, session, cfg, start)
+	}
+
 	// 3. Read Response Body
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -242,9 +293,83 @@ func executeDebugStep(client *http.Client, vp *attacker.VariableProcessor, step
 		isSuccess = cfg.SuccessCodes[resp.StatusCode]
 	}
 
+	// GraphQL responses with an "errors" array fail even on HTTP 200
+	if step.GraphQL != nil {
+		if gqlErr := attacker.CheckGraphQLErrors(bodyBytes); gqlErr != nil {
+			fmt.Printf("  %s❌ GraphQL: %v%s\n", colorRed, gqlErr, colorReset)
+			isSuccess = false
+		} else {
+			fmt.Printf("  %s✅ GraphQL: no errors%s\n", colorGreen, colorReset)
+		}
+	}
+
 	return isSuccess, nil
 }
 
+// executeDebugStream prints each SSE event as it arrives, along with stream timings
+// and per-event assertion results.
+func executeDebugStream(resp *http.Response, step models.Step, session map[string]string, cfg *models.Config, start time.Time) (bool, error) {
+	fmt.Printf("\n%s[STREAM]%s\n", colorBold, colorReset)
+	fmt.Printf("%sProtocol:%s %s  %sStatus:%s %d %s(Headers after: %s)%s\n",
+		colorDim, colorReset, resp.Proto,
+		colorDim, colorReset, resp.StatusCode,
+		colorDim, time.Since(start).Round(time.Millisecond), colorReset)
+
+	extractedVars := make(map[string]string)
+	assertionsOK := true
+	count := 0
+	last := start
+	err := attacker.ReadSSE(resp.Body, step.SSE, func(ev attacker.SSEEvent) error {
+		now := time.Now()
+		count++
+		timing := fmt.Sprintf("+%s", now.Sub(last).Round(time.Millisecond))
+		if count == 1 {
+			timing = fmt.Sprintf("first event after %s", now.Sub(start).Round(time.Millisecond))
+		}
+		last = now
+
+		fmt.Printf("  %s#%d%s %s%s%s %s(%s)%s\n", colorBold, count, colorReset,
+			colorYellow, ev.Event, colorReset, colorDim, timing, colorReset)
+		printFormattedJSON(truncate(ev.Data, 500), "     ")
+
+		for varName, path := range step.EventExtract {
+			if val := attacker.ExtractEventValue(ev, path); val != "" {
+				session[varName] = val
+				extractedVars[varName] = val
+			}
+		}
+
+		if len(step.EventAssertions) > 0 {
+			if aerr := validator.ValidateAssertions([]byte(ev.Data), step.EventAssertions); aerr != nil {
+				assertionsOK = false
+				fmt.Printf("     %s❌ %v%s\n", colorRed, aerr, colorReset)
+			}
+		}
+		return nil
+	})
+
+	fmt.Printf("\n  %s%d events in %s%s\n", colorDim, count, time.Since(start).Round(time.Millisecond), colorReset)
+	if err != nil {
+		fmt.Printf("  %s❌ Stream error: %v%s\n", colorRed, err, colorReset)
+	}
+
+	if len(step.EventExtract) > 0 {
+		printExtractedVariables(extractedVars, step.EventExtract)
+	}
+
+	fmt.Printf("\n%s[🛡️ ASSERTIONS]%s\n", colorBold, colorReset)
+	printStatusAssertion(resp.StatusCode, cfg.SuccessCodes)
+	if len(step.EventAssertions) > 0 && assertionsOK {
+		fmt.Printf("  %s✅ Event assertions:%s Passed for all %d events\n", colorGreen, colorReset, count)
+	}
+
+	isSuccess := resp.StatusCode >= 200 && resp.StatusCode < 400
+	if len(cfg.SuccessCodes) > 0 {
+		isSuccess = cfg.SuccessCodes[resp.StatusCode]
+	}
+	return isSuccess && err == nil, nil
+}
+
 // printStepHeader prints the step header
 func printStepHeader(stepNum int, name string) {
 	printSeparator()
@@ -0,0 +1,212 @@
+// Package metrics serves live run metrics in the Prometheus text exposition format,
+// so a long-running test can be scraped next to the metrics of the system under test.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Amr-9/sayl/internal/runner"
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// Source provides the live data of a run. *runner.Runner implements it.
+type Source interface {
+	Snapshot() models.Report
+	InFlight() int64
+	TargetRate() float64
+	CircuitOpen() bool
+}
+
+// Server exposes /metrics for the run it is attached to.
+type Server struct {
+	listener net.Listener
+	server   *http.Server
+
+	mu  sync.RWMutex
+	src Source
+}
+
+// Listen binds addr (e.g. ":9100") and starts serving /metrics in the background.
+// Until a run is attached, only sayl_up is reported.
+func Listen(addr string) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start metrics endpoint on '%s': %w", addr, err)
+	}
+
+	s := &Server{listener: ln}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	s.server = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go s.server.Serve(ln)
+	return s, nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Attach makes the server report r. It implements runner.Observer.
+func (s *Server) Attach(r *runner.Runner) {
+	s.SetSource(r)
+}
+
+// SetSource makes the server report src.
+func (s *Server) SetSource(src Source) {
+	s.mu.Lock()
+	s.src = src
+	s.mu.Unlock()
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	return s.server.Close()
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	s.mu.RLock()
+	src := s.src
+	s.mu.RUnlock()
+
+	var e exposition
+	e.family("sayl_up", "gauge", "1 when a test run is attached to this endpoint.")
+	if src == nil {
+		e.sample("sayl_up", nil, 0)
+	} else {
+		e.sample("sayl_up", nil, 1)
+		e.run(src)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(e.buf.Bytes())
+}
+
+// exposition builds a response in the Prometheus text format.
+type exposition struct {
+	buf bytes.Buffer
+}
+
+func (e *exposition) run(src Source) {
+	rep := src.Snapshot()
+
+	e.counter("sayl_requests_total", "Requests completed.", float64(rep.TotalRequests))
+	e.counter("sayl_requests_failed_total", "Requests that failed (network error, unexpected status or assertion).", float64(rep.FailureCount))
+	e.counter("sayl_assertion_failures_total", "Requests that failed an assertion.", float64(rep.AssertionFailures))
+	e.counter("sayl_response_bytes_total", "Response body bytes received after decompression.", float64(rep.TotalBytes))
+	e.counter("sayl_response_wire_bytes_total", "Response body bytes received on the wire.", float64(rep.TotalWireBytes))
+
+	e.family("sayl_responses_total", "counter", "Requests completed, by status code (\"Timeout\" for timeouts, \"0\" for other network errors).")
+	for _, code := range sortedKeys(rep.StatusCodes) {
+		e.sample("sayl_responses_total", []string{"status", code}, float64(rep.StatusCodes[code]))
+	}
+
+	e.family("sayl_request_latency_seconds", "summary", "Request latency over the run so far, at the configured percentiles (quantile 1 is the maximum).")
+	e.latency("sayl_request_latency_seconds", nil, rep.LatencyPercentiles(), rep.Max, rep.Mean, responses(rep.StatusCodes))
+
+	if len(rep.Steps) > 0 {
+		e.family("sayl_step_requests_total", "counter", "Requests completed, by scenario step.")
+		for _, st := range rep.Steps {
+			e.sample("sayl_step_requests_total", []string{"step", st.Name}, float64(st.Requests))
+		}
+		e.family("sayl_step_requests_failed_total", "counter", "Requests that failed, by scenario step.")
+		for _, st := range rep.Steps {
+			e.sample("sayl_step_requests_failed_total", []string{"step", st.Name}, float64(st.Failures))
+		}
+		e.family("sayl_step_latency_seconds", "summary", "Request latency by scenario step, at the configured percentiles (quantile 1 is the maximum).")
+		for _, st := range rep.Steps {
+			ps := st.Percentiles
+			if len(ps) == 0 {
+				ps = []models.PercentileValue{{P: 50, Value: st.P50}, {P: 75, Value: st.P75}, {P: 90, Value: st.P90}, {P: 95, Value: st.P95}, {P: 99, Value: st.P99}}
+			}
+			e.latency("sayl_step_latency_seconds", []string{"step", st.Name}, ps, st.Max, st.Mean, responses(st.StatusCodes))
+		}
+	}
+
+	e.gauge("sayl_in_flight_requests", "Requests currently being executed.", float64(src.InFlight()))
+	e.gauge("sayl_target_rate", "Current target rate in scenario iterations per second (follows the stages while ramping).", src.TargetRate())
+	e.gauge("sayl_rps", "Average requests per second since the start of the run.", rep.RPS)
+
+	open := 0.0
+	if src.CircuitOpen() {
+		open = 1
+	}
+	e.gauge("sayl_circuit_breaker_open", "1 when the circuit breaker (load.stop_if) has stopped the run.", open)
+}
+
+func (e *exposition) counter(name, help string, v float64) {
+	e.family(name, "counter", help)
+	e.sample(name, nil, v)
+}
+
+func (e *exposition) gauge(name, help string, v float64) {
+	e.family(name, "gauge", help)
+	e.sample(name, nil, v)
+}
+
+// latency writes the samples of a latency summary. Only requests that received
+// a response have a latency, so count excludes network errors and timeouts.
+func (e *exposition) latency(name string, labels []string, ps []models.PercentileValue, max, mean time.Duration, count int64) {
+	quantile := func(q float64) []string {
+		// Ten digits keep 99.9/100 from printing as 0.9990000000000001.
+		return append(labels[:len(labels):len(labels)], "quantile", strconv.FormatFloat(q, 'g', 10, 64))
+	}
+	for _, p := range ps {
+		e.sample(name, quantile(p.P/100), p.Value.Seconds())
+	}
+	e.sample(name, quantile(1), max.Seconds())
+	e.sample(name+"_sum", labels, mean.Seconds()*float64(count))
+	e.sample(name+"_count", labels, float64(count))
+}
+
+func (e *exposition) family(name, typ, help string) {
+	fmt.Fprintf(&e.buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// sample writes one line; labels are name/value pairs.
+func (e *exposition) sample(name string, labels []string, v float64) {
+	e.buf.WriteString(name)
+	if len(labels) > 0 {
+		e.buf.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				e.buf.WriteByte(',')
+			}
+			fmt.Fprintf(&e.buf, "%s=\"%s\"", labels[i], labelEscaper.Replace(labels[i+1]))
+		}
+		e.buf.WriteByte('}')
+	}
+	e.buf.WriteByte(' ')
+	e.buf.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+	e.buf.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// responses counts the requests in codes that received an HTTP status.
+func responses(codes map[string]int) int64 {
+	var n int64
+	for code, c := range codes {
+		if status, err := strconv.Atoi(code); err == nil && status >= 100 {
+			n += int64(c)
+		}
+	}
+	return n
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+type fakeSource struct {
+	rep models.Report
+}
+
+func (f fakeSource) Snapshot() models.Report { return f.rep }
+func (f fakeSource) InFlight() int64         { return 3 }
+func (f fakeSource) TargetRate() float64     { return 50 }
+func (f fakeSource) CircuitOpen() bool       { return false }
+
+// scrape serves one request to /metrics and parses the exposition.
+func scrape(t *testing.T, src Source) map[string]*dto.MetricFamily {
+	t.Helper()
+	s := &Server{}
+	if src != nil {
+		s.SetSource(src)
+	}
+	rec := httptest.NewRecorder()
+	s.handleMetrics(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(rec.Body)
+	if err != nil {
+		t.Fatalf("parsing exposition: %v\n%s", err, rec.Body)
+	}
+	return families
+}
+
+func TestExpositionTypes(t *testing.T) {
+	rep := models.Report{
+		TotalRequests: 10,
+		FailureCount:  3,
+		StatusCodes:   map[string]int{"200": 7, "500": 1, "Timeout": 1, "0": 1},
+		Percentiles:   []models.PercentileValue{{P: 50, Value: 20 * time.Millisecond}, {P: 99.9, Value: 90 * time.Millisecond}},
+		Mean:          25 * time.Millisecond,
+		Max:           100 * time.Millisecond,
+		Steps: []models.StepStats{{
+			Name:        `login "v2"`,
+			Requests:    4,
+			Failures:    1,
+			P50:         10 * time.Millisecond,
+			P75:         12 * time.Millisecond,
+			P90:         14 * time.Millisecond,
+			P95:         16 * time.Millisecond,
+			P99:         18 * time.Millisecond,
+			Mean:        11 * time.Millisecond,
+			Max:         20 * time.Millisecond,
+			StatusCodes: map[string]int{"200": 3, "0": 1},
+		}},
+	}
+	families := scrape(t, fakeSource{rep})
+
+	want := map[string]dto.MetricType{
+		"sayl_up":                         dto.MetricType_GAUGE,
+		"sayl_requests_total":             dto.MetricType_COUNTER,
+		"sayl_requests_failed_total":      dto.MetricType_COUNTER,
+		"sayl_responses_total":            dto.MetricType_COUNTER,
+		"sayl_request_latency_seconds":    dto.MetricType_SUMMARY,
+		"sayl_step_requests_total":        dto.MetricType_COUNTER,
+		"sayl_step_requests_failed_total": dto.MetricType_COUNTER,
+		"sayl_step_latency_seconds":       dto.MetricType_SUMMARY,
+		"sayl_in_flight_requests":         dto.MetricType_GAUGE,
+		"sayl_target_rate":                dto.MetricType_GAUGE,
+		"sayl_rps":                        dto.MetricType_GAUGE,
+		"sayl_circuit_breaker_open":       dto.MetricType_GAUGE,
+	}
+	for name, typ := range want {
+		f, ok := families[name]
+		if !ok {
+			t.Errorf("family %s is missing", name)
+			continue
+		}
+		if f.GetType() != typ {
+			t.Errorf("family %s has type %v, want %v", name, f.GetType(), typ)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		step      string
+		count     uint64
+		sum       float64
+		quantiles map[float64]float64
+	}{
+		{
+			name:      "sayl_request_latency_seconds",
+			count:     8, // Timeouts and network errors have no latency
+			sum:       0.2,
+			quantiles: map[float64]float64{0.5: 0.02, 0.999: 0.09, 1: 0.1},
+		},
+		{
+			name:      "sayl_step_latency_seconds",
+			step:      `login "v2"`,
+			count:     3,
+			sum:       0.033,
+			quantiles: map[float64]float64{0.5: 0.01, 0.75: 0.012, 0.9: 0.014, 0.95: 0.016, 0.99: 0.018, 1: 0.02},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := families[tt.name]
+			if f == nil || len(f.Metric) != 1 {
+				t.Fatalf("want one %s series, got %v", tt.name, f)
+			}
+			m := f.Metric[0]
+			if tt.step != "" {
+				if len(m.Label) != 1 || m.Label[0].GetName() != "step" || m.Label[0].GetValue() != tt.step {
+					t.Errorf("labels = %v, want step=%q", m.Label, tt.step)
+				}
+			}
+			s := m.GetSummary()
+			if s.GetSampleCount() != tt.count {
+				t.Errorf("_count = %d, want %d", s.GetSampleCount(), tt.count)
+			}
+			if diff := s.GetSampleSum() - tt.sum; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("_sum = %g, want %g", s.GetSampleSum(), tt.sum)
+			}
+			if len(s.Quantile) != len(tt.quantiles) {
+				t.Fatalf("got %d quantiles, want %d", len(s.Quantile), len(tt.quantiles))
+			}
+			for _, q := range s.Quantile {
+				want, ok := tt.quantiles[q.GetQuantile()]
+				if !ok || q.GetValue() != want {
+					t.Errorf("quantile %g = %g, want %g", q.GetQuantile(), q.GetValue(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestExpositionDetached(t *testing.T) {
+	families := scrape(t, nil)
+	if len(families) != 1 {
+		t.Fatalf("got %d families before a run is attached, want only sayl_up", len(families))
+	}
+	if v := families["sayl_up"].Metric[0].GetGauge().GetValue(); v != 0 {
+		t.Fatalf("sayl_up = %g, want 0", v)
+	}
+}
@@ -0,0 +1,182 @@
+package outputs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// NewInfluxDB streams to the InfluxDB write endpoint in cfg.URL: an http(s) URL such as
+// http://localhost:8086/api/v2/write?org=acme&bucket=load (or /write?db=load for 1.x),
+// or udp://host:port for a Telegraf/InfluxDB UDP listener.
+func NewInfluxDB(cfg *models.InfluxDBConfig, runTags map[string]string) (*Stream, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid InfluxDB URL '%s' (use http://host:8086/api/v2/write?org=...&bucket=... or udp://host:8089)", cfg.URL)
+	}
+
+	var tr transport
+	switch u.Scheme {
+	case "http", "https":
+		tr = &httpTransport{
+			url:    cfg.URL,
+			token:  cfg.Token,
+			client: &http.Client{Timeout: 5 * time.Second},
+		}
+	case "udp":
+		conn, err := net.Dial("udp", u.Host)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open InfluxDB UDP socket '%s': %w", u.Host, err)
+		}
+		tr = &udpTransport{conn: conn}
+	default:
+		return nil, fmt.Errorf("unsupported InfluxDB URL scheme '%s' (use http, https or udp)", u.Scheme)
+	}
+
+	enc := &influxEncoder{tags: influxTags(mergeTags(runTags, cfg.Tags))}
+	return newStream("InfluxDB", enc, tr, cfg.SampleRate), nil
+}
+
+// influxEncoder writes line protocol with nanosecond timestamps:
+//
+//	sayl,test=checkout requests=250i,success=248i,failures=2i,...,p99_ms=41.2 <ts>
+//	sayl_status,status=200,test=checkout count=248i <ts>
+//	sayl_request,status=200,step=login,test=checkout latency_ms=12.5,bytes=512i,success=true <ts>
+type influxEncoder struct {
+	tags string // Escaped run tags, including the leading comma
+}
+
+func (e *influxEncoder) second(buf *bytes.Buffer, s models.SecondStats, ts time.Time) {
+	fmt.Fprintf(buf, "sayl%s requests=%di,success=%di,failures=%di,assertion_failures=%di,avg_latency_ms=%s,p50_ms=%s,p75_ms=%s,p90_ms=%s,p95_ms=%s,p99_ms=%s %d\n",
+		e.tags, s.Requests, s.Success, s.Failures, s.AssertionFailures,
+		influxFloat(s.AvgLatency), influxFloat(ms(s.P50)), influxFloat(ms(s.P75)), influxFloat(ms(s.P90)),
+		influxFloat(ms(s.P95)), influxFloat(ms(s.P99)), ts.UnixNano())
+	for _, code := range sortedKeys(s.StatusCodes) {
+		fmt.Fprintf(buf, "sayl_status%s,status=%s count=%di %d\n", e.tags, influxEscape(code), s.StatusCodes[code], ts.UnixNano())
+	}
+}
+
+func (e *influxEncoder) request(buf *bytes.Buffer, res models.Result, success bool) {
+	status := strconv.Itoa(res.Status)
+	if res.Status == 1 {
+		status = "Timeout"
+	}
+	buf.WriteString("sayl_request")
+	buf.WriteString(e.tags)
+	buf.WriteString(",status=")
+	buf.WriteString(status)
+	if res.StepName != "" {
+		buf.WriteString(",step=")
+		buf.WriteString(influxEscape(res.StepName))
+	}
+	fmt.Fprintf(buf, " latency_ms=%s,bytes=%di,success=%t", influxFloat(ms(res.Latency)), res.Bytes, success)
+	if res.Error != nil {
+		fmt.Fprintf(buf, ",error=\"%s\"", fieldEscaper.Replace(res.Error.Error()))
+	} else if res.AssertionError != nil {
+		fmt.Fprintf(buf, ",error=\"%s\"", fieldEscaper.Replace(res.AssertionError.Error()))
+	}
+	fmt.Fprintf(buf, " %d\n", res.Timestamp.UnixNano())
+}
+
+func influxTags(tags []tag) string {
+	var b strings.Builder
+	for _, t := range tags {
+		if t.value == "" {
+			continue // Empty tag values are not allowed in line protocol
+		}
+		b.WriteByte(',')
+		b.WriteString(influxEscape(t.key))
+		b.WriteByte('=')
+		b.WriteString(influxEscape(t.value))
+	}
+	return b.String()
+}
+
+var (
+	tagEscaper   = strings.NewReplacer(",", `\,`, "=", `\=`, " ", `\ `, "\n", " ")
+	fieldEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ")
+)
+
+// influxEscape escapes a tag key or value.
+func influxEscape(s string) string {
+	return tagEscaper.Replace(s)
+}
+
+func influxFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
+
+// httpTransport posts each batch to the InfluxDB write API.
+type httpTransport struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func (t *httpTransport) send(batch []byte) error {
+	req, err := http.NewRequest(http.MethodPost, t.url, bytes.NewReader(batch))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if t.token != "" {
+		req.Header.Set("Authorization", "Token "+t.token)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("write returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func (t *httpTransport) close() error {
+	t.client.CloseIdleConnections()
+	return nil
+}
+
+// maxDatagram keeps UDP packets below a typical MTU so they are not fragmented.
+const maxDatagram = 1432
+
+// udpTransport sends a batch as datagrams of whole lines.
+type udpTransport struct {
+	conn net.Conn
+}
+
+func (t *udpTransport) send(batch []byte) error {
+	var errs []error
+	for len(batch) > 0 {
+		n := len(batch)
+		if n > maxDatagram {
+			// Cut after the last complete line that fits; a longer line goes alone.
+			if i := bytes.LastIndexByte(batch[:maxDatagram], '\n'); i >= 0 {
+				n = i + 1
+			} else if i := bytes.IndexByte(batch, '\n'); i >= 0 {
+				n = i + 1
+			}
+		}
+		if _, err := t.conn.Write(batch[:n]); err != nil {
+			errs = append(errs, err)
+		}
+		batch = batch[n:]
+	}
+	return errors.Join(errs...)
+}
+
+func (t *udpTransport) close() error {
+	return t.conn.Close()
+}
@@ -0,0 +1,244 @@
+// Package outputs streams live run metrics to time-series backends: InfluxDB (line
+// protocol over HTTP or UDP) and StatsD (UDP). Every completed second of the run is
+// sent as an aggregate; a sample of individual requests can be sent as well.
+package outputs
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Amr-9/sayl/internal/runner"
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+const (
+	flushInterval = time.Second
+	// A bucket is sent once it is this much older than its end, so late results
+	// (e.g. slow requests that started in that second) are included.
+	settleDelay = time.Second
+	// Sampled request points waiting for the next flush; more are dropped.
+	pointQueueSize = 1 << 14
+)
+
+// Source provides the live data of a run. *runner.Runner implements it.
+type Source interface {
+	Snapshot() models.Report
+}
+
+// encoder formats aggregates and request points for one backend.
+type encoder interface {
+	// second appends the lines of one time-series bucket that ended at ts.
+	second(buf *bytes.Buffer, s models.SecondStats, ts time.Time)
+	// request appends the line of one sampled request.
+	request(buf *bytes.Buffer, res models.Result, success bool)
+}
+
+// transport delivers a batch of newline-terminated lines.
+type transport interface {
+	send(batch []byte) error
+	close() error
+}
+
+// Stream periodically sends the time series of the attached run to a backend.
+// It implements runner.ResultObserver.
+type Stream struct {
+	name       string // Backend name used in error messages
+	enc        encoder
+	tr         transport
+	sampleRate float64
+
+	mu         sync.Mutex
+	src        Source
+	start      time.Time
+	lastSecond int // Second of the last bucket sent
+
+	points  chan []byte
+	dropped atomic.Int64
+
+	sendErrors int
+	firstErr   error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newStream(name string, enc encoder, tr transport, sampleRate float64) *Stream {
+	s := &Stream{
+		name:       name,
+		enc:        enc,
+		tr:         tr,
+		sampleRate: sampleRate,
+		points:     make(chan []byte, pointQueueSize),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go s.loop()
+	return s
+}
+
+// Attach streams the metrics of r. It implements runner.Observer.
+func (s *Stream) Attach(r *runner.Runner) {
+	s.SetSource(r)
+}
+
+// SetSource streams the metrics of src from now on. The remaining buckets of the
+// previous source, if any, are sent first.
+func (s *Stream) SetSource(src Source) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.src != nil {
+		s.flush(true)
+	}
+	s.src = src
+	s.start = time.Now()
+	s.lastSecond = 0
+}
+
+// Record queues a sampled request point. It implements runner.ResultObserver.
+func (s *Stream) Record(res models.Result, success bool) {
+	if s.sampleRate <= 0 || rand.Float64() >= s.sampleRate {
+		return
+	}
+	var buf bytes.Buffer
+	s.enc.request(&buf, res, success)
+	select {
+	case s.points <- buf.Bytes():
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// Close sends the remaining buckets, including the last incomplete one, and
+// reports whether any data could not be delivered.
+func (s *Stream) Close() error {
+	close(s.stop)
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flush(true)
+	if err := s.tr.close(); err != nil && s.firstErr == nil {
+		s.firstErr = err
+	}
+
+	if s.firstErr != nil {
+		return fmt.Errorf("%s: %d of the writes failed, first error: %w", s.name, s.sendErrors, s.firstErr)
+	}
+	if n := s.dropped.Load(); n > 0 {
+		return fmt.Errorf("%s: %d sampled request points were dropped (lower sample_rate)", s.name, n)
+	}
+	return nil
+}
+
+func (s *Stream) loop() {
+	defer close(s.done)
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			s.flush(false)
+			s.mu.Unlock()
+		}
+	}
+}
+
+// flush sends the buckets that have settled (all of them when final) and the
+// queued request points. It must be called with mu held.
+func (s *Stream) flush(final bool) {
+	var buf bytes.Buffer
+
+	if s.src != nil {
+		rep := s.src.Snapshot()
+		elapsed := time.Since(s.start)
+		for _, sec := range rep.TimeSeriesData {
+			if sec.Second <= s.lastSecond {
+				continue
+			}
+			end := time.Duration(sec.Second) * time.Second
+			if !final && end+settleDelay > elapsed {
+				break
+			}
+			s.enc.second(&buf, sec, s.start.Add(end))
+			s.lastSecond = sec.Second
+		}
+	}
+
+	for drained := false; !drained; {
+		select {
+		case p := <-s.points:
+			buf.Write(p)
+		default:
+			drained = true
+		}
+	}
+
+	if buf.Len() == 0 {
+		return
+	}
+	if err := s.tr.send(buf.Bytes()); err != nil {
+		s.sendErrors++
+		if s.firstErr == nil {
+			s.firstErr = err
+		}
+	}
+}
+
+// RunTags returns the tags that identify a run in every backend: the test name
+// and, when the run was started from a scenario file, its name.
+func RunTags(name, scenario string) map[string]string {
+	tags := map[string]string{"test": name}
+	if name == "" {
+		tags["test"] = "sayl"
+		if scenario != "" {
+			tags["test"] = scenario
+		}
+	}
+	if scenario != "" {
+		tags["scenario"] = scenario
+	}
+	return tags
+}
+
+// tag is a key/value pair, kept sorted by key.
+type tag struct {
+	key, value string
+}
+
+// mergeTags combines the run tags with the user-configured tags; user tags win.
+func mergeTags(run, extra map[string]string) []tag {
+	merged := make(map[string]string, len(run)+len(extra))
+	for k, v := range run {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	tags := make([]tag, 0, len(merged))
+	for k, v := range merged {
+		tags = append(tags, tag{k, v})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].key < tags[j].key })
+	return tags
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
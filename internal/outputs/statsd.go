@@ -0,0 +1,123 @@
+package outputs
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// DefaultStatsDPrefix is prepended to every StatsD metric name when none is configured.
+const DefaultStatsDPrefix = "sayl."
+
+// NewStatsD streams to the StatsD server at cfg.Address over UDP. Tags use the
+// DogStatsD "|#key:value" extension, understood by Telegraf (datadog_extensions)
+// and the Datadog agent.
+func NewStatsD(cfg *models.StatsDConfig, runTags map[string]string) (*Stream, error) {
+	conn, err := net.Dial("udp", cfg.Address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open StatsD socket '%s': %w", cfg.Address, err)
+	}
+	prefix := cfg.Prefix
+	if prefix == "" {
+		prefix = DefaultStatsDPrefix
+	}
+	enc := &statsdEncoder{
+		prefix:     prefix,
+		tags:       statsdTags(mergeTags(runTags, cfg.Tags)),
+		sampleRate: cfg.SampleRate,
+	}
+	return newStream("StatsD", enc, &udpTransport{conn: conn}, cfg.SampleRate), nil
+}
+
+// statsdEncoder writes counters for the requests of each second, gauges for its
+// latency percentiles, and a timing for each sampled request:
+//
+//	sayl.requests:250|c|#test:checkout
+//	sayl.latency.p99:41.200|g|#test:checkout
+//	sayl.responses:248|c|#test:checkout,status:200
+//	sayl.request.latency:12.500|ms|@0.1|#test:checkout,step:login,status:200
+type statsdEncoder struct {
+	prefix     string
+	tags       string // Run tags without the leading "|#"
+	sampleRate float64
+}
+
+func (e *statsdEncoder) second(buf *bytes.Buffer, s models.SecondStats, _ time.Time) {
+	e.line(buf, "requests", strconv.FormatInt(s.Requests, 10), "c", "")
+	e.line(buf, "success", strconv.FormatInt(s.Success, 10), "c", "")
+	e.line(buf, "failures", strconv.FormatInt(s.Failures, 10), "c", "")
+	e.line(buf, "assertion_failures", strconv.FormatInt(s.AssertionFailures, 10), "c", "")
+	if s.Requests > 0 {
+		e.line(buf, "latency.avg", statsdFloat(s.AvgLatency), "g", "")
+		e.line(buf, "latency.p50", statsdFloat(ms(s.P50)), "g", "")
+		e.line(buf, "latency.p75", statsdFloat(ms(s.P75)), "g", "")
+		e.line(buf, "latency.p90", statsdFloat(ms(s.P90)), "g", "")
+		e.line(buf, "latency.p95", statsdFloat(ms(s.P95)), "g", "")
+		e.line(buf, "latency.p99", statsdFloat(ms(s.P99)), "g", "")
+	}
+	for _, code := range sortedKeys(s.StatusCodes) {
+		e.line(buf, "responses", strconv.Itoa(s.StatusCodes[code]), "c", "status:"+statsdEscape(code))
+	}
+}
+
+func (e *statsdEncoder) request(buf *bytes.Buffer, res models.Result, success bool) {
+	status := strconv.Itoa(res.Status)
+	if res.Status == 1 {
+		status = "Timeout"
+	}
+	extra := "status:" + status
+	if res.StepName != "" {
+		extra = "step:" + statsdEscape(res.StepName) + "," + extra
+	}
+	fmt.Fprintf(buf, "%srequest.latency:%s|ms|@%s", e.prefix, statsdFloat(ms(res.Latency)), strconv.FormatFloat(e.sampleRate, 'g', -1, 64))
+	e.writeTags(buf, extra)
+}
+
+func (e *statsdEncoder) line(buf *bytes.Buffer, name, value, typ, extraTags string) {
+	buf.WriteString(e.prefix)
+	buf.WriteString(name)
+	buf.WriteByte(':')
+	buf.WriteString(value)
+	buf.WriteByte('|')
+	buf.WriteString(typ)
+	e.writeTags(buf, extraTags)
+}
+
+// writeTags ends a line with the run tags and extra.
+func (e *statsdEncoder) writeTags(buf *bytes.Buffer, extra string) {
+	if e.tags != "" || extra != "" {
+		buf.WriteString("|#")
+		buf.WriteString(e.tags)
+		if e.tags != "" && extra != "" {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(extra)
+	}
+	buf.WriteByte('\n')
+}
+
+func statsdTags(tags []tag) string {
+	parts := make([]string, 0, len(tags))
+	for _, t := range tags {
+		if t.value == "" {
+			continue
+		}
+		parts = append(parts, statsdEscape(t.key)+":"+statsdEscape(t.value))
+	}
+	return strings.Join(parts, ",")
+}
+
+var statsdEscaper = strings.NewReplacer(",", "_", "|", "_", "#", "_", "\n", " ")
+
+func statsdEscape(s string) string {
+	return statsdEscaper.Replace(s)
+}
+
+func statsdFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 3, 64)
+}
@@ -0,0 +1,61 @@
+package report
+
+import (
+	"embed"
+	"html/template"
+	"strings"
+	"sync"
+)
+
+// chartJSVersion is the Chart.js release inlined into the HTML reports, and the
+// one loaded from the CDN when the asset is missing.
+const chartJSVersion = "4.4.1"
+
+//go:generate curl -sSfL -o assets/chart.umd.min.js https://cdn.jsdelivr.net/npm/chart.js@4.4.1/dist/chart.umd.min.js
+//go:generate curl -sSfL -o assets/chart.js.LICENSE.md https://cdn.jsdelivr.net/npm/chart.js@4.4.1/LICENSE.md
+
+//go:embed assets
+var assets embed.FS
+
+// templateFuncs are shared by the report and diff templates.
+var templateFuncs = template.FuncMap{"chartjs": chartScript}
+
+// chartJSSource returns the embedded Chart.js build, or nil if this build was
+// made without running go generate.
+var chartJSSource = sync.OnceValue(func() []byte {
+	src, err := assets.ReadFile("assets/chart.umd.min.js")
+	if err != nil || len(src) == 0 {
+		return nil
+	}
+	return src
+})
+
+// chartScript returns the script element that loads Chart.js. The library is
+// inlined so reports open on air-gapped machines and stay complete as CI
+// artifacts; builds without the asset fall back to the CDN.
+var chartScript = sync.OnceValue(func() template.HTML {
+	src := chartJSSource()
+	if src == nil {
+		return template.HTML(`<script src="https://cdn.jsdelivr.net/npm/chart.js@` + chartJSVersion + `/dist/chart.umd.min.js"></script>`)
+	}
+	// A literal "</script" would end the element early.
+	js := strings.ReplaceAll(string(src), "</script", `<\/script`)
+	return template.HTML("<script>" + js + "</script>")
+})
+
+// ChartScript returns the script element that loads Chart.js, for pages that
+// draw charts the way the reports do, such as the live web dashboard.
+func ChartScript() template.HTML {
+	return chartScript()
+}
+
+// ChartJSInlined reports whether Chart.js is embedded in this build. Without it,
+// pages load Chart.js from the CDN and need network access to draw charts.
+func ChartJSInlined() bool {
+	return chartJSSource() != nil
+}
+
+// Stylesheet returns the CSS of the HTML report.
+func Stylesheet() template.CSS {
+	return template.CSS(reportCSS)
+}
@@ -0,0 +1,33 @@
+package report
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestChartJSAsset checks the embedded Chart.js build, or the CDN fallback of a
+// build made without it.
+func TestChartJSAsset(t *testing.T) {
+	license, err := assets.ReadFile("assets/chart.js.LICENSE.md")
+	if err != nil || !strings.Contains(string(license), "MIT License") {
+		t.Fatalf("assets/chart.js.LICENSE.md is missing; run 'go generate ./internal/report'")
+	}
+
+	script := string(ChartScript())
+	if !ChartJSInlined() {
+		t.Logf("assets/chart.umd.min.js is not in this build; reports load Chart.js from the CDN")
+		if !strings.Contains(script, "chart.js@"+chartJSVersion+"/") {
+			t.Fatalf("fallback does not load Chart.js %s: %s", chartJSVersion, script)
+		}
+		return
+	}
+
+	src, _ := assets.ReadFile("assets/chart.umd.min.js")
+	if banner := string(src[:min(len(src), 200)]); !strings.Contains(banner, "Chart.js v"+chartJSVersion) {
+		t.Fatalf("assets/chart.umd.min.js is not Chart.js %s; run 'go generate ./internal/report'", chartJSVersion)
+	}
+	body := strings.TrimSuffix(strings.TrimPrefix(script, "<script>"), "</script>")
+	if strings.Contains(body, "</script") {
+		t.Fatal("the inlined script would end its element early")
+	}
+}
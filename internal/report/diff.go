@@ -0,0 +1,378 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Amr-9/sayl/internal/compare"
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+const diffTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Sayl Comparison Report</title>
+    {{chartjs}}
+    <style>
+` + reportCSS + `        .regression { color: #ff4757; font-weight: bold; }
+        .improvement { color: #00ff88; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⚖️ Sayl Comparison Report</h1>
+            <p>Generated at {{.GeneratedAt}}</p>
+            <div style="margin-top: 20px; padding: 15px; background: rgba(0,0,0,0.2); border-radius: 10px; display: inline-block;">
+                <div style="font-size: 1.1rem; margin-bottom: 5px;">
+                    <span style="color: #00d9ff; font-weight: bold;">Baseline</span> {{.BaselineName}}
+                    &nbsp;→&nbsp;
+                    <span style="color: #ff00ff; font-weight: bold;">Candidate</span> {{.CandidateName}}
+                </div>
+                <div style="margin-top: 10px;">
+                    {{if .Regressions}}<span class="error-badge">{{.Regressions}} regression(s)</span>{{else}}<span class="success-badge">No regressions</span>{{end}}
+                </div>
+            </div>
+        </div>
+
+        <div class="status-table" style="margin-bottom: 40px;{{if .Regressions}} border-color: rgba(255, 71, 87, 0.3);{{end}}">
+            <h3>📊 Metrics</h3>
+            <table>
+                <thead>
+                    <tr><th>Metric</th><th>Baseline</th><th>Candidate</th><th>Change</th><th>Tolerance</th><th>Result</th></tr>
+                </thead>
+                <tbody>
+                    {{range .Metrics}}
+                    <tr>
+                        <td>{{.Metric}}</td><td>{{.Baseline}}</td><td>{{.Candidate}}</td>
+                        <td class="{{.Class}}">{{.Change}}</td><td>{{.Limit}}</td>
+                        <td>{{if .Regression}}<span class="error-badge">Regression</span>{{else if .Limit}}<span class="success-badge">OK</span>{{end}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+
+        {{if .Steps}}
+        <div class="status-table" style="margin-bottom: 40px;">
+            <h3>🧩 Steps</h3>
+            <table>
+                <thead>
+                    <tr><th>Step</th><th>Metric</th><th>Baseline</th><th>Candidate</th><th>Change</th><th>Result</th></tr>
+                </thead>
+                <tbody>
+                    {{range .Steps}}
+                    <tr>
+                        <td>{{.Step}}</td><td>{{.Metric}}</td><td>{{.Baseline}}</td><td>{{.Candidate}}</td>
+                        <td class="{{.Class}}">{{.Change}}</td>
+                        <td>{{if .Regression}}<span class="error-badge">Regression</span>{{else if .Limit}}<span class="success-badge">OK</span>{{end}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+
+        <div class="charts-grid">
+            <div class="chart-container">
+                <h3>📈 Requests Per Second (RPS)</h3>
+                <div class="chart-wrapper"><canvas id="rpsChart"></canvas></div>
+            </div>
+            <div class="chart-container">
+                <h3>⏱️ P95 Latency (ms)</h3>
+                <div class="chart-wrapper"><canvas id="p95Chart"></canvas></div>
+            </div>
+            <div class="chart-container">
+                <h3>⏱️ P99 Latency (ms)</h3>
+                <div class="chart-wrapper"><canvas id="p99Chart"></canvas></div>
+            </div>
+            <div class="chart-container">
+                <h3>❌ Failures per Second</h3>
+                <div class="chart-wrapper"><canvas id="failureChart"></canvas></div>
+            </div>
+        </div>
+
+        <div class="status-table">
+            <h3>🔢 Status Codes</h3>
+            <table>
+                <thead>
+                    <tr><th>Status Code</th><th>Baseline</th><th>Candidate</th><th>Share Change</th></tr>
+                </thead>
+                <tbody>
+                    {{range .Statuses}}
+                    <tr>
+                        <td>{{.Code}}</td>
+                        <td>{{.Baseline}} ({{printf "%.2f" .BaselinePct}}%)</td>
+                        <td>{{.Candidate}} ({{printf "%.2f" .CandidatePct}}%)</td>
+                        <td>{{printf "%+.2f" .ShareChange}} pts</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+
+        <div class="footer">
+            <p>Generated by Sayl - High-Performance Load Testing Tool</p>
+        </div>
+    </div>
+
+    <script>
+        Chart.defaults.color = '#888';
+        Chart.defaults.borderColor = 'rgba(255,255,255,0.1)';
+
+        const timeLabels = [{{.TimeLabels}}];
+        const overlay = (id, baseline, candidate) => new Chart(document.getElementById(id), {
+            type: 'line',
+            data: {
+                labels: timeLabels,
+                datasets: [
+                    { label: 'Baseline', data: baseline, borderColor: '#00d9ff', tension: 0.4, pointRadius: 2 },
+                    { label: 'Candidate', data: candidate, borderColor: '#ff00ff', tension: 0.4, pointRadius: 2 }
+                ]
+            },
+            options: {
+                responsive: true,
+                maintainAspectRatio: false,
+                spanGaps: true,
+                plugins: {
+                    legend: { position: 'top', labels: { usePointStyle: true } }
+                },
+                scales: {
+                    y: { beginAtZero: true, grid: { color: 'rgba(255,255,255,0.05)' } },
+                    x: { grid: { color: 'rgba(255,255,255,0.05)' } }
+                }
+            }
+        });
+
+        overlay('rpsChart', [{{.BaselineRPS}}], [{{.CandidateRPS}}]);
+        overlay('p95Chart', [{{.BaselineP95}}], [{{.CandidateP95}}]);
+        overlay('p99Chart', [{{.BaselineP99}}], [{{.CandidateP99}}]);
+        overlay('failureChart', [{{.BaselineFailures}}], [{{.CandidateFailures}}]);
+    </script>
+</body>
+</html>`
+
+// DiffRow is a formatted metric row of the comparison report.
+type DiffRow struct {
+	Step       string
+	Metric     string
+	Baseline   string
+	Candidate  string
+	Change     string
+	Limit      string
+	Regression bool
+	Class      string // "regression", "improvement" or ""
+}
+
+// DiffStatusRow is a status code row of the comparison report.
+type DiffStatusRow struct {
+	compare.StatusDelta
+	ShareChange float64
+}
+
+// DiffTemplateData holds all data for the comparison HTML template.
+type DiffTemplateData struct {
+	GeneratedAt       string
+	BaselineName      string
+	CandidateName     string
+	Regressions       int
+	Metrics           []DiffRow
+	Steps             []DiffRow
+	Statuses          []DiffStatusRow
+	TimeLabels        template.JS
+	BaselineRPS       template.JS
+	CandidateRPS      template.JS
+	BaselineP95       template.JS
+	CandidateP95      template.JS
+	BaselineP99       template.JS
+	CandidateP99      template.JS
+	BaselineFailures  template.JS
+	CandidateFailures template.JS
+}
+
+// GenerateDiffHTML creates a side-by-side comparison report with overlaid time series.
+func GenerateDiffHTML(c compare.Comparison, filename string) error {
+	tmpl, err := template.New("diff").Funcs(templateFuncs).Parse(diffTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	data := DiffTemplateData{
+		GeneratedAt:   time.Now().Format("2006-01-02 15:04:05"),
+		BaselineName:  c.BaselineName,
+		CandidateName: c.CandidateName,
+		Regressions:   c.Regressions,
+	}
+	for _, d := range c.Metrics {
+		data.Metrics = append(data.Metrics, diffRow("", d))
+	}
+	for _, st := range c.Steps {
+		for _, d := range st.Metrics {
+			data.Steps = append(data.Steps, diffRow(st.Name, d))
+		}
+	}
+	for _, st := range c.Statuses {
+		data.Statuses = append(data.Statuses, DiffStatusRow{StatusDelta: st, ShareChange: st.CandidatePct - st.BaselinePct})
+	}
+
+	// Both runs are plotted against the elapsed second, so runs of different
+	// lengths share one axis; the shorter run simply ends early.
+	n := max(seriesSeconds(c.Baseline), seriesSeconds(c.Candidate))
+	labels := make([]string, n)
+	for i := range labels {
+		labels[i] = fmt.Sprintf("'%ds'", i+1)
+	}
+	data.TimeLabels = template.JS(strings.Join(labels, ","))
+
+	rps := func(r models.Report) func(s models.SecondStats) string {
+		return func(s models.SecondStats) string {
+			return fmt.Sprintf("%.1f", float64(s.Requests)/float64(pointSeconds(r, s)))
+		}
+	}
+	p95 := func(s models.SecondStats) string { return fmt.Sprintf("%.2f", float64(s.P95.Microseconds())/1000) }
+	p99 := func(s models.SecondStats) string { return fmt.Sprintf("%.2f", float64(s.P99.Microseconds())/1000) }
+	failures := func(s models.SecondStats) string { return fmt.Sprintf("%d", s.Failures) }
+
+	data.BaselineRPS = series(c.Baseline, rps(c.Baseline))
+	data.CandidateRPS = series(c.Candidate, rps(c.Candidate))
+	data.BaselineP95 = series(c.Baseline, p95)
+	data.CandidateP95 = series(c.Candidate, p95)
+	data.BaselineP99 = series(c.Baseline, p99)
+	data.CandidateP99 = series(c.Candidate, p99)
+	data.BaselineFailures = series(c.Baseline, failures)
+	data.CandidateFailures = series(c.Candidate, failures)
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	return tmpl.Execute(file, data)
+}
+
+// series formats one value per time series point of r. Points wider than a
+// second (re-bucketed reports, downsampled history) are expanded so every point
+// lines up with the per-second axis.
+func series(r models.Report, value func(models.SecondStats) string) template.JS {
+	var out []string
+	for _, s := range r.TimeSeriesData {
+		v := value(s)
+		for range pointSeconds(r, s) {
+			out = append(out, v)
+		}
+	}
+	return template.JS(strings.Join(out, ","))
+}
+
+// seriesSeconds is the number of seconds the time series of r covers.
+func seriesSeconds(r models.Report) int {
+	n := 0
+	for _, s := range r.TimeSeriesData {
+		n += pointSeconds(r, s)
+	}
+	return n
+}
+
+func diffRow(step string, d compare.Delta) DiffRow {
+	row := DiffRow{
+		Step:       step,
+		Metric:     d.Metric,
+		Baseline:   formatDiffValue(d.Kind, d.Baseline),
+		Candidate:  formatDiffValue(d.Kind, d.Candidate),
+		Change:     formatDiffChange(d),
+		Limit:      d.Limit,
+		Regression: d.Regression,
+	}
+	switch {
+	case d.Regression:
+		row.Class = "regression"
+	case d.Limit != "" && isImprovement(d):
+		row.Class = "improvement"
+	}
+	return row
+}
+
+// isImprovement reports whether a checked metric moved in the good direction.
+func isImprovement(d compare.Delta) bool {
+	if d.Kind == compare.KindRate {
+		return d.Change > 0
+	}
+	return d.Change < 0
+}
+
+func formatDiffValue(kind int, v float64) string {
+	switch kind {
+	case compare.KindLatency:
+		return formatDuration(time.Duration(v * float64(time.Millisecond)))
+	case compare.KindRate:
+		return fmt.Sprintf("%.1f", v)
+	case compare.KindPercent:
+		return fmt.Sprintf("%.2f%%", v)
+	default:
+		if v == float64(int64(v)) {
+			return fmt.Sprintf("%d", int64(v))
+		}
+		return fmt.Sprintf("%.2f", v)
+	}
+}
+
+func formatDiffChange(d compare.Delta) string {
+	if d.Kind == compare.KindPercent {
+		return fmt.Sprintf("%+.2f pts", d.Change)
+	}
+	if d.Baseline == 0 {
+		if d.Candidate == 0 {
+			return "0%"
+		}
+		return "new"
+	}
+	return fmt.Sprintf("%+.1f%%", d.ChangePct)
+}
+
+// PrintConsoleDiff prints the comparison as a table with regressions marked.
+func PrintConsoleDiff(c compare.Comparison) {
+	fmt.Printf("\n⚖️  Comparison: %s → %s\n", c.BaselineName, c.CandidateName)
+	fmt.Println("=================")
+
+	printRows := func(deltas []compare.Delta) {
+		fmt.Printf("  %-18s %12s %12s %12s\n", "Metric", "Baseline", "Candidate", "Change")
+		for _, d := range deltas {
+			line := fmt.Sprintf("  %-18s %12s %12s %12s", d.Metric,
+				formatDiffValue(d.Kind, d.Baseline), formatDiffValue(d.Kind, d.Candidate), formatDiffChange(d))
+			if d.Regression {
+				line += fmt.Sprintf("   ❌ regression (limit %s)", d.Limit)
+			}
+			fmt.Println(line)
+		}
+	}
+
+	printRows(c.Metrics)
+	fmt.Println()
+
+	if len(c.Statuses) > 0 {
+		fmt.Println("🔢 Status Codes")
+		for _, st := range c.Statuses {
+			fmt.Printf("  [%s]:\t%d (%.2f%%) → %d (%.2f%%)\t%+.2f pts\n",
+				st.Code, st.Baseline, st.BaselinePct, st.Candidate, st.CandidatePct, st.CandidatePct-st.BaselinePct)
+		}
+		fmt.Println()
+	}
+
+	for _, st := range c.Steps {
+		fmt.Printf("🧩 Step: %s\n", st.Name)
+		printRows(st.Metrics)
+		fmt.Println()
+	}
+
+	if c.Regressions > 0 {
+		fmt.Printf("❌ %d regression(s) beyond tolerances\n", c.Regressions)
+	} else {
+		fmt.Println("✅ No regressions beyond tolerances")
+	}
+}
@@ -0,0 +1,161 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// JUnit XML schema as understood by Jenkins, GitLab, GitHub test reporters and others.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// GenerateJUnit writes the run as JUnit XML: every threshold is a test case, and so
+// are the assertions of every step (or of the single target when there are no steps).
+// A run stopped by the circuit breaker adds a failing test case.
+func GenerateJUnit(r models.Report, filename string) error {
+	suites := junitTestSuites{
+		Name: "sayl",
+		Time: fmt.Sprintf("%.3f", elapsed(r).Seconds()),
+	}
+
+	if len(r.Thresholds) > 0 {
+		suite := junitTestSuite{Name: "thresholds"}
+		for _, th := range r.Thresholds {
+			tc := junitTestCase{Name: th.Expr, ClassName: "sayl.thresholds", SystemOut: "actual: " + th.Actual}
+			if !th.Passed {
+				tc.Failure = &junitFailure{
+					Message: fmt.Sprintf("threshold %s failed (actual: %s)", th.Expr, th.Actual),
+					Type:    "threshold",
+				}
+			}
+			suite.add(tc)
+		}
+		suites.add(suite)
+	}
+
+	assertions := junitTestSuite{Name: "assertions"}
+	if len(r.Steps) > 0 {
+		for _, st := range r.Steps {
+			assertions.add(assertionCase(st.Name, st.Requests, st.AssertionFailures, st.AssertionErrors))
+		}
+	} else {
+		assertions.add(assertionCase(r.TargetURL, r.TotalRequests, r.AssertionFailures, r.AssertionErrors))
+	}
+	suites.add(assertions)
+
+	if r.CircuitBroken {
+		suite := junitTestSuite{Name: "run"}
+		suite.add(junitTestCase{
+			Name:      "circuit breaker",
+			ClassName: "sayl.run",
+			Failure:   &junitFailure{Message: "test stopped early: " + r.CircuitBreakReason, Type: "circuit_breaker"},
+		})
+		suites.add(suite)
+	}
+
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JUnit report: %w", err)
+	}
+	data = append([]byte(xml.Header), data...)
+	data = append(data, '\n')
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write JUnit report '%s': %w", filename, err)
+	}
+	return nil
+}
+
+// assertionCase is a test case that fails when any request of the step failed an
+// assertion; the failure lists the distinct messages, most frequent first.
+func assertionCase(name string, requests, failures int64, messages map[string]int) junitTestCase {
+	tc := junitTestCase{
+		Name:      name + " assertions",
+		ClassName: "sayl.assertions",
+		SystemOut: fmt.Sprintf("%d requests, %d assertion failures", requests, failures),
+	}
+	if failures == 0 {
+		return tc
+	}
+
+	var text strings.Builder
+	for _, msg := range byCount(messages) {
+		fmt.Fprintf(&text, "%dx %s\n", messages[msg], msg)
+	}
+
+	tc.Failure = &junitFailure{
+		Message: fmt.Sprintf("%d of %d requests failed assertions", failures, requests),
+		Type:    "assertion",
+		Text:    text.String(),
+	}
+	return tc
+}
+
+func (s *junitTestSuite) add(tc junitTestCase) {
+	s.Cases = append(s.Cases, tc)
+	s.Tests++
+	if tc.Failure != nil {
+		s.Failures++
+	}
+}
+
+func (s *junitTestSuites) add(suite junitTestSuite) {
+	s.Suites = append(s.Suites, suite)
+	s.Tests += suite.Tests
+	s.Failures += suite.Failures
+}
+
+// byCount returns the keys of m, most frequent first.
+func byCount(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if m[keys[i]] != m[keys[j]] {
+			return m[keys[i]] > m[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// elapsed is the actual run time, falling back to the configured duration for
+// reports that predate the elapsed field.
+func elapsed(r models.Report) time.Duration {
+	if r.Elapsed > 0 {
+		return r.Elapsed
+	}
+	return r.Duration
+}
@@ -0,0 +1,141 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Amr-9/sayl/internal/threshold"
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// maxMarkdownErrors limits the error lists so a summary stays readable in a PR comment.
+const maxMarkdownErrors = 10
+
+// GenerateMarkdown writes a GitHub-flavoured Markdown summary of the run, suitable
+// for $GITHUB_STEP_SUMMARY or a pull request comment.
+func GenerateMarkdown(r models.Report, filename string) error {
+	if err := os.WriteFile(filename, []byte(Markdown(r)), 0644); err != nil {
+		return fmt.Errorf("failed to write Markdown report '%s': %w", filename, err)
+	}
+	return nil
+}
+
+// Markdown renders the run summary as GitHub-flavoured Markdown.
+func Markdown(r models.Report) string {
+	var b strings.Builder
+
+	verdict := "✅ Passed"
+	switch {
+	case r.CircuitBroken:
+		verdict = "⛔ Stopped by circuit breaker: " + r.CircuitBreakReason
+	case !threshold.Passed(r.Thresholds):
+		verdict = "❌ Thresholds failed"
+	case r.Interrupted:
+		verdict = "⚠️ Interrupted (partial results)"
+	}
+	fmt.Fprintf(&b, "## Sayl load test: %s\n\n", verdict)
+	if target := strings.TrimSpace(r.Method + " " + r.TargetURL); target != "" {
+		fmt.Fprintf(&b, "**Target:** `%s` · **Duration:** %s · **Workers:** %d\n\n", target, elapsed(r).Round(time.Millisecond), r.Concurrency)
+	}
+
+	header := "| Requests | RPS | Success | Failures |"
+	align := "| ---: | ---: | ---: | ---: |"
+	row := fmt.Sprintf("| %d | %.1f | %.2f%% | %d |", r.TotalRequests, r.RPS, r.SuccessRate, r.FailureCount)
+	for _, p := range r.LatencyPercentiles() {
+		header += " " + strings.ToUpper(p.Label()) + " |"
+		align += " ---: |"
+		row += " " + formatDuration(p.Value) + " |"
+	}
+	fmt.Fprintf(&b, "%s Max |\n%s ---: |\n%s %s |\n\n", header, align, row, formatDuration(r.Max))
+
+	if len(r.Thresholds) > 0 {
+		b.WriteString("### Thresholds\n\n")
+		b.WriteString("| | Threshold | Actual |\n| :---: | :--- | ---: |\n")
+		for _, th := range r.Thresholds {
+			mark := "✅"
+			if !th.Passed {
+				mark = "❌"
+			}
+			fmt.Fprintf(&b, "| %s | `%s` | %s |\n", mark, mdEscape(th.Expr), th.Actual)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.Steps) > 0 {
+		b.WriteString("### Steps\n\n")
+		b.WriteString("| Step | Requests | Success | Assertion failures | P50 | P95 | P99 |\n")
+		b.WriteString("| :--- | ---: | ---: | ---: | ---: | ---: | ---: |\n")
+		for _, st := range r.Steps {
+			fmt.Fprintf(&b, "| %s | %d | %.2f%% | %d | %s | %s | %s |\n",
+				mdEscape(st.Name), st.Requests, st.SuccessRate, st.AssertionFailures,
+				formatDuration(st.P50), formatDuration(st.P95), formatDuration(st.P99))
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.StatusCodes) > 0 {
+		codes := make([]string, 0, len(r.StatusCodes))
+		for code := range r.StatusCodes {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		b.WriteString("### Status Codes\n\n| Status | Count | Share |\n| :--- | ---: | ---: |\n")
+		for _, code := range codes {
+			share := 0.0
+			if r.TotalRequests > 0 {
+				share = float64(r.StatusCodes[code]) / float64(r.TotalRequests) * 100
+			}
+			fmt.Fprintf(&b, "| %s | %d | %.1f%% |\n", code, r.StatusCodes[code], share)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.Errors) > 0 {
+		b.WriteString("### Errors\n\n| Count | Class | Example |\n| ---: | :--- | :--- |\n")
+		for _, class := range byCount(r.Errors) {
+			example := ""
+			if samples := r.ErrorSamples[class]; len(samples) > 0 {
+				example = "`" + mdEscape(samples[0]) + "`"
+			}
+			fmt.Fprintf(&b, "| %d | %s | %s |\n", r.Errors[class], mdEscape(class), example)
+		}
+		b.WriteString("\n")
+	}
+	writeCountList(&b, "Assertion Failures", r.AssertionErrors)
+
+	if len(r.Annotations) > 0 {
+		b.WriteString("### Manual Control\n\n| Time | Action | Change |\n| ---: | :--- | :--- |\n")
+		for _, a := range r.Annotations {
+			fmt.Fprintf(&b, "| %ds | %s | %s |\n", a.Second, a.Action, mdEscape(a.Label))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// writeCountList writes the most frequent messages of m as a table.
+func writeCountList(b *strings.Builder, title string, m map[string]int) {
+	if len(m) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "### %s\n\n| Count | Message |\n| ---: | :--- |\n", title)
+	keys := byCount(m)
+	for i, msg := range keys {
+		if i == maxMarkdownErrors {
+			fmt.Fprintf(b, "| | … and %d more |\n", len(keys)-maxMarkdownErrors)
+			break
+		}
+		fmt.Fprintf(b, "| %d | %s |\n", m[msg], mdEscape(msg))
+	}
+	b.WriteString("\n")
+}
+
+var mdEscaper = strings.NewReplacer("|", `\|`, "\n", " ", "`", "'")
+
+// mdEscape keeps a value inside its table cell.
+func mdEscape(s string) string {
+	return mdEscaper.Replace(s)
+}
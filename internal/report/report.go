@@ -1,8 +1,11 @@
 package report
 
 import (
+	"encoding/json"
 	"fmt"
 	"html/template"
+	"io"
+	"math"
 	"os"
 	"sort"
 	"strings"
@@ -11,15 +14,8 @@ import (
 	"github.com/Amr-9/sayl/pkg/models"
 )
 
-const htmlTemplate = `<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Sayl Load Test Report</title>
-    <script src="https://cdn.jsdelivr.net/npm/chart.js"></script>
-    <style>
-        * {
+// reportCSS is shared by the run report and the comparison report.
+const reportCSS = `        * {
             margin: 0;
             padding: 0;
             box-sizing: border-box;
@@ -114,6 +110,26 @@ const htmlTemplate = `<!DOCTYPE html>
             position: relative;
             height: 300px;
         }
+        .config-echo summary {
+            cursor: pointer;
+            color: #00d9ff;
+            font-size: 1.3rem;
+            font-weight: bold;
+        }
+        .config-echo pre {
+            margin-top: 20px;
+            padding: 20px;
+            background: rgba(0,0,0,0.3);
+            border-radius: 10px;
+            overflow-x: auto;
+            font-size: 0.85rem;
+            line-height: 1.5;
+            color: #ddd;
+        }
+        #heatmapChart {
+            width: 100%;
+            height: 100%;
+        }
         .status-table {
             background: rgba(255,255,255,0.05);
             border-radius: 20px;
@@ -165,7 +181,17 @@ const htmlTemplate = `<!DOCTYPE html>
             color: #666;
             font-size: 0.9rem;
         }
-    </style>
+`
+
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Sayl Load Test Report</title>
+    {{chartjs}}
+    <style>
+` + reportCSS + `    </style>
 </head>
 <body>
     <div class="container">
@@ -178,12 +204,24 @@ const htmlTemplate = `<!DOCTYPE html>
                     <a href="{{.TargetURL}}" style="color: #fff; text-decoration: none; border-bottom: 1px dotted #00ff88;" target="_blank">{{.TargetURL}}</a>
                 </div>
                 <div style="color: #888; font-size: 0.9rem;">
-                    Duration: <span style="color: #00ff88">{{.TestDuration}}</span> • 
+                    Duration: <span style="color: #00ff88">{{.TestDuration}}</span>{{if .Interrupted}} <span class="error-badge">Interrupted after {{.Elapsed}}</span>{{end}} • 
                     Concurrency: <span style="color: #00ff88">{{.Concurrency}}</span> workers
                 </div>
+                {{if .ReportJSON}}
+                <div style="margin-top: 8px; font-size: 0.9rem;">
+                    <a id="downloadJSON" href="#" download="report.json" style="color: #00d9ff;">⬇ Download report.json</a>
+                </div>
+                {{end}}
             </div>
         </div>
 
+        {{if .CircuitBroken}}
+        <div class="status-table" style="margin-bottom: 40px; border-color: rgba(255, 71, 87, 0.3);">
+            <h3 style="color: #ff4757;">⛔ Stopped by circuit breaker</h3>
+            <p style="color: #ff6b81; font-family: monospace;">{{.CircuitBreakReason}}</p>
+        </div>
+        {{end}}
+
         <div class="summary-grid">
             <div class="summary-card">
                 <div class="value">{{.TotalRequests}}</div>
@@ -201,23 +239,107 @@ const htmlTemplate = `<!DOCTYPE html>
                 <div class="value">{{.Min}}</div>
                 <div class="label">Min Latency</div>
             </div>
+            {{range .Latencies}}
             <div class="summary-card">
-                <div class="value">{{.P50}}</div>
-                <div class="label">P50 Latency</div>
-            </div>
-            <div class="summary-card">
-                <div class="value">{{.P99}}</div>
-                <div class="label">P99 Latency</div>
+                <div class="value">{{.Value}}</div>
+                <div class="label">{{.Label}} Latency</div>
             </div>
+            {{end}}
             <div class="summary-card">
                 <div class="value">{{.Max}}</div>
                 <div class="label">Max Latency</div>
             </div>
+            <div class="summary-card">
+                <div class="value">{{.Mean}}</div>
+                <div class="label">Mean ± {{.StdDev}}</div>
+            </div>
             <div class="summary-card">
                 <div class="value">{{.SuccessCount}}</div>
                 <div class="label">Successful</div>
             </div>
+            {{if .AssertionFailures}}
+            <div class="summary-card">
+                <div class="value" style="color: #ff6b81;">{{.AssertionFailures}}</div>
+                <div class="label">Assertion Failures</div>
+            </div>
+            {{end}}
+            <div class="summary-card">
+                <div class="value">{{.TotalBytes}}</div>
+                <div class="label">Received · {{printf "%.2f" .Throughput}} MB/s</div>
+            </div>
+            <div class="summary-card">
+                <div class="value">{{.WireBytes}}</div>
+                <div class="label">On the Wire · {{printf "%.2f" .WireThroughput}} MB/s</div>
+            </div>
+        </div>
+
+        {{if .Thresholds}}
+        <div class="status-table" style="margin-bottom: 40px;{{if .ThresholdsFailed}} border-color: rgba(255, 71, 87, 0.3);{{end}}">
+            <h3>🎯 Thresholds {{if .ThresholdsFailed}}<span class="error-badge">{{.ThresholdsFailed}} failed</span>{{else}}<span class="success-badge">All passed</span>{{end}}</h3>
+            <table>
+                <thead>
+                    <tr>
+                        <th>Threshold</th>
+                        <th>Actual</th>
+                        <th>Result</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Thresholds}}
+                    <tr>
+                        <td style="font-family: monospace;">{{.Expr}}</td>
+                        <td>{{.Actual}}</td>
+                        <td>
+                            {{if .Passed}}
+                            <span class="success-badge">Pass</span>
+                            {{else}}
+                            <span class="error-badge">Fail</span>
+                            {{end}}
+                        </td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
         </div>
+        {{end}}
+
+        {{if .Steps}}
+        <div class="status-table" style="margin-bottom: 40px;">
+            <h3>🔗 Steps</h3>
+            <table>
+                <thead>
+                    <tr>
+                        <th>Step</th>
+                        <th>Requests</th>
+                        <th>Success Rate</th>
+                        <th>RPS</th>
+                        <th>P50</th>
+                        <th>P95</th>
+                        <th>P99</th>
+                        <th>Max</th>
+                        <th>Status Codes</th>
+                        <th>Top Error</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Steps}}
+                    <tr>
+                        <td style="font-weight: bold;">{{.Name}}</td>
+                        <td>{{.Requests}}</td>
+                        <td>{{if ge .SuccessRate 99.0}}<span class="success-badge">{{printf "%.2f" .SuccessRate}}%</span>{{else}}<span class="error-badge">{{printf "%.2f" .SuccessRate}}%</span>{{end}}</td>
+                        <td>{{printf "%.1f" .RPS}}</td>
+                        <td>{{.P50}}</td>
+                        <td>{{.P95}}</td>
+                        <td>{{.P99}}</td>
+                        <td>{{.Max}}</td>
+                        <td style="font-family: monospace;">{{.StatusCodes}}</td>
+                        <td style="color: #ff6b81; font-family: monospace;">{{.TopError}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
 
         <div class="charts-grid">
             <div class="chart-container">
@@ -244,6 +366,34 @@ const htmlTemplate = `<!DOCTYPE html>
                     <canvas id="statusChart"></canvas>
                 </div>
             </div>
+            {{if .HistLabels}}
+            <div class="chart-container">
+                <h3>📊 Latency Distribution (log scale)</h3>
+                <div class="chart-wrapper">
+                    <canvas id="histogramChart"></canvas>
+                </div>
+            </div>
+            <div class="chart-container">
+                <h3>🔥 Latency Heatmap (requests/s)</h3>
+                <div class="chart-wrapper">
+                    <canvas id="heatmapChart"></canvas>
+                </div>
+            </div>
+            {{end}}
+            {{if .Steps}}
+            <div class="chart-container">
+                <h3>🔗 RPS per Step</h3>
+                <div class="chart-wrapper">
+                    <canvas id="stepRpsChart"></canvas>
+                </div>
+            </div>
+            <div class="chart-container">
+                <h3>🔗 P95 Latency per Step (ms)</h3>
+                <div class="chart-wrapper">
+                    <canvas id="stepLatencyChart"></canvas>
+                </div>
+            </div>
+            {{end}}
         </div>
 
         <div class="status-table">
@@ -276,18 +426,90 @@ const htmlTemplate = `<!DOCTYPE html>
             </table>
         </div>
 
+        {{if .Protocols}}
+        <div class="status-table" style="margin-top: 30px;">
+            <h3>🌐 Protocols</h3>
+            <table>
+                <thead>
+                    <tr>
+                        <th>Protocol</th>
+                        <th>Count</th>
+                        <th>Percentage</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Protocols}}
+                    <tr>
+                        <td style="font-family: monospace;">{{.Name}}</td>
+                        <td>{{.Count}}</td>
+                        <td>{{printf "%.2f" .Percentage}}%</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+
+        {{if .Annotations}}
+        <div class="status-table" style="margin-top: 30px; border-color: rgba(255, 187, 0, 0.3);">
+            <h3 style="color: #ffbb00;">🎛️ Manual Control</h3>
+            <table>
+                <thead>
+                    <tr>
+                        <th>Time</th>
+                        <th>Action</th>
+                        <th>Change</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .Annotations}}
+                    <tr>
+                        <td>{{.Time}}</td>
+                        <td style="font-family: monospace;">{{.Action}}</td>
+                        <td>{{.Label}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+
         {{if .Errors}}
         <div class="status-table" style="margin-top: 30px; border-color: rgba(255, 71, 87, 0.3);">
             <h3 style="color: #ff4757;">⚠️ Error Distribution</h3>
             <table>
                 <thead>
                     <tr>
-                        <th style="color: #ff4757;">Error Message</th>
+                        <th style="color: #ff4757;">Error Class</th>
                         <th style="color: #ff4757;">Count</th>
+                        <th style="color: #ff4757;">Sample Messages</th>
                     </tr>
                 </thead>
                 <tbody>
                     {{range .Errors}}
+                    <tr>
+                        <td style="color: #ff6b81; font-family: monospace;">{{.Class}}</td>
+                        <td>{{.Count}}</td>
+                        <td style="color: #ff6b81; font-family: monospace;">{{range .Samples}}<div>{{.}}</div>{{end}}</td>
+                    </tr>
+                    {{end}}
+                </tbody>
+            </table>
+        </div>
+        {{end}}
+
+        {{if .AssertionErrors}}
+        <div class="status-table" style="margin-top: 30px; border-color: rgba(255, 71, 87, 0.3);">
+            <h3 style="color: #ff4757;">🧪 Assertion Failures</h3>
+            <table>
+                <thead>
+                    <tr>
+                        <th style="color: #ff4757;">Assertion</th>
+                        <th style="color: #ff4757;">Count</th>
+                    </tr>
+                </thead>
+                <tbody>
+                    {{range .AssertionErrors}}
                     <tr>
                         <td style="color: #ff6b81; font-family: monospace;">{{.Message}}</td>
                         <td>{{.Count}}</td>
@@ -298,11 +520,23 @@ const htmlTemplate = `<!DOCTYPE html>
         </div>
         {{end}}
 
+        {{if .Config}}
+        <div class="status-table config-echo" style="margin-top: 30px;">
+            <details>
+                <summary>⚙️ Configuration</summary>
+                <pre>{{.Config}}</pre>
+            </details>
+        </div>
+        {{end}}
+
         <div class="footer">
             <p>Generated by Sayl - High-Performance Load Testing Tool</p>
         </div>
     </div>
 
+    {{if .ReportJSON}}
+    <script type="application/json" id="sayl-report">{{.ReportJSON}}</script>
+    {{end}}
     <script>
         // Chart.js global configuration
         Chart.defaults.color = '#888';
@@ -311,13 +545,36 @@ const htmlTemplate = `<!DOCTYPE html>
         // Time series data
         const timeLabels = [{{.TimeLabels}}];
         const rpsData = [{{.RPSData}}];
-        const p50Data = [{{.P50Data}}];
-        const p90Data = [{{.P90Data}}];
-        const p95Data = [{{.P95Data}}];
-        const p99Data = [{{.P99Data}}];
+        const latencySeries = {{.LatencySeries}};
+        const latencyColors = ['#00ff88', '#00d9ff', '#ffbb00', '#ff6b6b', '#ff00ff', '#b388ff', '#ffffff'];
         const successData = [{{.SuccessData}}];
         const failureData = [{{.FailureData}}];
 
+        // Manual control actions (pause, rate changes, stage skips) as dashed lines
+        const annotationMarks = {{if .AnnotationMarks}}{{.AnnotationMarks}}{{else}}[]{{end}};
+        const annotationLines = {
+            id: 'annotationLines',
+            afterDatasetsDraw(chart) {
+                const { ctx, chartArea, scales } = chart;
+                ctx.save();
+                ctx.strokeStyle = 'rgba(255,187,0,0.8)';
+                ctx.fillStyle = '#ffbb00';
+                ctx.setLineDash([4, 4]);
+                ctx.font = '11px sans-serif';
+                const stacked = {}; // Labels of actions in the same second are stacked
+                annotationMarks.forEach(m => {
+                    const x = scales.x.getPixelForValue(m.x);
+                    const row = stacked[m.x] = (stacked[m.x] || 0) + 1;
+                    ctx.beginPath();
+                    ctx.moveTo(x, chartArea.top);
+                    ctx.lineTo(x, chartArea.bottom);
+                    ctx.stroke();
+                    ctx.fillText(m.label, x + 4, chartArea.top + 12 * row);
+                });
+                ctx.restore();
+            }
+        };
+
         // RPS Chart
         new Chart(document.getElementById('rpsChart'), {
             type: 'line',
@@ -334,6 +591,7 @@ const htmlTemplate = `<!DOCTYPE html>
                     pointHoverRadius: 6
                 }]
             },
+            plugins: [annotationLines],
             options: {
                 responsive: true,
                 maintainAspectRatio: false,
@@ -352,13 +610,11 @@ const htmlTemplate = `<!DOCTYPE html>
             type: 'line',
             data: {
                 labels: timeLabels,
-                datasets: [
-                    { label: 'P50', data: p50Data, borderColor: '#00ff88', tension: 0.4, pointRadius: 2 },
-                    { label: 'P90', data: p90Data, borderColor: '#ffbb00', tension: 0.4, pointRadius: 2 },
-                    { label: 'P95', data: p95Data, borderColor: '#ff6b6b', tension: 0.4, pointRadius: 2 },
-                    { label: 'P99', data: p99Data, borderColor: '#ff00ff', tension: 0.4, pointRadius: 2 }
-                ]
+                datasets: latencySeries.map((s, i) => ({
+                    label: s.label, data: s.data, borderColor: latencyColors[i % latencyColors.length], tension: 0.4, pointRadius: 2
+                }))
             },
+            plugins: [annotationLines],
             options: {
                 responsive: true,
                 maintainAspectRatio: false,
@@ -413,6 +669,123 @@ const htmlTemplate = `<!DOCTYPE html>
                 }
             }
         });
+        {{if .HistLabels}}
+
+        // Latency histogram: the bins are log spaced, so equal-width bars give a log axis
+        new Chart(document.getElementById('histogramChart'), {
+            type: 'bar',
+            data: {
+                labels: {{.HistLabels}},
+                datasets: [{
+                    label: 'Requests',
+                    data: {{.HistData}},
+                    backgroundColor: 'rgba(0,217,255,0.6)',
+                    borderColor: '#00d9ff',
+                    borderWidth: 1,
+                    barPercentage: 1,
+                    categoryPercentage: 1
+                }]
+            },
+            options: {
+                responsive: true,
+                maintainAspectRatio: false,
+                plugins: {
+                    legend: { display: false },
+                    tooltip: { callbacks: { title: items => '≤ ' + items[0].label } }
+                },
+                scales: {
+                    y: { beginAtZero: true, grid: { color: 'rgba(255,255,255,0.05)' } },
+                    x: { grid: { display: false } }
+                }
+            }
+        });
+
+        // Latency heatmap: one column per time series point, one row per histogram
+        // bin, colored by requests/s on a log scale
+        const heatmap = {{.Heatmap}};
+        (function () {
+            const canvas = document.getElementById('heatmapChart');
+            const ctx = canvas.getContext('2d');
+            const cols = heatmap.cells.length, rows = heatmap.rows.length;
+            const peak = heatmap.cells.reduce((m, col) => col.reduce((a, v) => Math.max(a, v), m), 0);
+            const pad = { left: 64, right: 8, top: 4, bottom: 22 };
+            let cw = 0, ch = 0;
+
+            function draw() {
+                const dpr = window.devicePixelRatio || 1;
+                const w = canvas.clientWidth, h = canvas.clientHeight;
+                canvas.width = w * dpr;
+                canvas.height = h * dpr;
+                ctx.setTransform(dpr, 0, 0, dpr, 0, 0);
+                cw = (w - pad.left - pad.right) / Math.max(cols, 1);
+                ch = (h - pad.top - pad.bottom) / rows;
+
+                heatmap.cells.forEach((col, x) => col.forEach((v, y) => {
+                    if (!v) return;
+                    const t = Math.log1p(v) / Math.log1p(peak);
+                    ctx.fillStyle = 'hsl(' + (200 - 160 * t) + ', 100%, ' + (25 + 35 * t) + '%)';
+                    ctx.fillRect(pad.left + x * cw, pad.top + (rows - 1 - y) * ch, Math.ceil(cw), Math.ceil(ch));
+                }));
+
+                ctx.fillStyle = '#888';
+                ctx.font = '11px sans-serif';
+                ctx.textAlign = 'right';
+                ctx.textBaseline = 'middle';
+                const rowStep = Math.ceil(14 / ch);
+                for (let y = 0; y < rows; y += rowStep) {
+                    ctx.fillText(heatmap.rows[y], pad.left - 6, pad.top + (rows - 1 - y + 0.5) * ch);
+                }
+                ctx.textAlign = 'center';
+                ctx.textBaseline = 'top';
+                const colStep = Math.ceil(60 / cw);
+                for (let x = 0; x < cols; x += colStep) {
+                    ctx.fillText(timeLabels[x], pad.left + (x + 0.5) * cw, h - pad.bottom + 6);
+                }
+            }
+
+            canvas.addEventListener('mousemove', e => {
+                const x = Math.floor((e.offsetX - pad.left) / cw);
+                const y = rows - 1 - Math.floor((e.offsetY - pad.top) / ch);
+                const v = heatmap.cells[x] && heatmap.cells[x][y];
+                canvas.title = v === undefined ? '' : timeLabels[x] + ' · ≤ ' + heatmap.rows[y] + ' · ' + v + ' req/s';
+            });
+            window.addEventListener('resize', draw);
+            draw();
+        })();
+        {{end}}
+        {{if .ReportJSON}}
+
+        // The report JSON is bundled with the page; offer it as a download
+        document.getElementById('downloadJSON').addEventListener('click', e => {
+            const json = document.getElementById('sayl-report').textContent;
+            e.currentTarget.href = URL.createObjectURL(new Blob([json], { type: 'application/json' }));
+        });
+        {{end}}
+        {{if .Steps}}
+
+        // Per-step charts (gaps where a step had no requests in that second)
+        const stepColors = ['#00d9ff', '#00ff88', '#ffbb00', '#ff6b6b', '#ff00ff', '#6c5ce7', '#fd79a8', '#55efc4'];
+        const stepChart = (id, datasets) => new Chart(document.getElementById(id), {
+            type: 'line',
+            data: {
+                labels: timeLabels,
+                datasets: datasets.map((d, i) => ({ ...d, borderColor: stepColors[i % stepColors.length], tension: 0.4, pointRadius: 2, spanGaps: false }))
+            },
+            options: {
+                responsive: true,
+                maintainAspectRatio: false,
+                plugins: {
+                    legend: { position: 'top', labels: { usePointStyle: true } }
+                },
+                scales: {
+                    y: { beginAtZero: true, grid: { color: 'rgba(255,255,255,0.05)' } },
+                    x: { grid: { color: 'rgba(255,255,255,0.05)' } }
+                }
+            }
+        });
+        stepChart('stepRpsChart', {{.StepRPSSeries}});
+        stepChart('stepLatencyChart', {{.StepP95Series}});
+        {{end}}
     </script>
 </body>
 </html>`
@@ -427,59 +800,139 @@ type StatusCodeRow struct {
 
 // ErrorRow represents a row in the errors table
 type ErrorRow struct {
+	Class   string
+	Count   int
+	Samples []string
+}
+
+// AssertionRow represents a row in the assertion failures table
+type AssertionRow struct {
 	Message string
 	Count   int
 }
 
+// ProtocolRow represents a row in the protocols table
+type ProtocolRow struct {
+	Name       string
+	Count      int
+	Percentage float64
+}
+
+// AnnotationRow represents a manual control action in the annotations table
+type AnnotationRow struct {
+	Time   string
+	Action string
+	Label  string
+}
+
+// StepRow represents a row in the steps table
+type StepRow struct {
+	Name        string
+	Requests    int64
+	SuccessRate float64
+	RPS         float64
+	P50         string
+	P95         string
+	P99         string
+	Max         string
+	StatusCodes string
+	TopError    string
+}
+
+// LatencyCard is one percentile summary card of the HTML report.
+type LatencyCard struct {
+	Label string
+	Value string
+}
+
+// stepSeries is one Chart.js line dataset, used by the per-step and latency charts.
+type stepSeries struct {
+	Label string     `json:"label"`
+	Data  []*float64 `json:"data"` // nil where the step had no requests
+}
+
 // TemplateData holds all data for the HTML template
 type TemplateData struct {
 	GeneratedAt      string
 	TargetURL        string
 	Method           string
 	TestDuration     string
+	Elapsed          string
+	Interrupted      bool
 	Concurrency      int
 	TotalRequests    int64
 	SuccessCount     int64
 	FailureCount     int64
 	SuccessRate      float64
 	RPS              float64
-	P50              string
-	P90              string
-	P95              string
-	P99              string
+	TotalBytes       string
+	Throughput       float64
+	WireBytes        string
+	WireThroughput   float64
+	Latencies        []LatencyCard
 	Max              string
 	Min              string
+	Mean             string
+	StdDev           string
 	StatusCodesTable []StatusCodeRow
 	Errors           []ErrorRow
+	AssertionErrors  []AssertionRow
+	Protocols        []ProtocolRow
+	Thresholds       []models.ThresholdResult
+	ThresholdsFailed int
 	TimeLabels       template.JS
 	RPSData          template.JS
-	P50Data          template.JS
-	P90Data          template.JS
-	P95Data          template.JS
-	P99Data          template.JS
+	LatencySeries    template.JS
 	SuccessData      template.JS
 	FailureData      template.JS
 	StatusLabels     template.JS
 	StatusData       template.JS
+	Steps            []StepRow
+	StepRPSSeries    template.JS
+	StepP95Series    template.JS
+	HistLabels       template.JS
+	HistData         template.JS
+	Heatmap          template.JS
+	ReportJSON       template.JS // The whole report, when HTMLOptions.EmbedJSON is set
+
+	AssertionFailures  int64
+	CircuitBroken      bool
+	CircuitBreakReason string
+	Config             string // Redacted YAML of the run
+
+	Annotations     []AnnotationRow
+	AnnotationMarks template.JS // Chart markers of the annotations: time series index and label
+}
+
+// HTMLOptions controls optional content of the HTML report.
+type HTMLOptions struct {
+	EmbedJSON bool // Bundle the report JSON, so the file alone can be re-analysed
 }
 
 // GenerateHTML creates an HTML report file with charts
-func GenerateHTML(report models.Report, filename string) error {
-	tmpl, err := template.New("report").Parse(htmlTemplate)
+func GenerateHTML(report models.Report, filename string, opts HTMLOptions) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	return RenderHTML(file, report, opts)
+}
+
+// RenderHTML writes the HTML report to w.
+func RenderHTML(w io.Writer, report models.Report, opts HTMLOptions) error {
+	tmpl, err := template.New("report").Funcs(templateFuncs).Parse(htmlTemplate)
 	if err != nil {
 		return fmt.Errorf("failed to parse template: %w", err)
 	}
 
 	// Build time series arrays
-	var timeLabels, rpsData, p50Data, p90Data, p95Data, p99Data, successData, failureData []string
+	var timeLabels, rpsData, successData, failureData []string
 
 	for _, s := range report.TimeSeriesData {
 		timeLabels = append(timeLabels, fmt.Sprintf("'%ds'", s.Second))
-		rpsData = append(rpsData, fmt.Sprintf("%d", s.Requests))
-		p50Data = append(p50Data, fmt.Sprintf("%.2f", float64(s.P50.Milliseconds())))
-		p90Data = append(p90Data, fmt.Sprintf("%.2f", float64(s.P90.Milliseconds())))
-		p95Data = append(p95Data, fmt.Sprintf("%.2f", float64(s.P95.Milliseconds())))
-		p99Data = append(p99Data, fmt.Sprintf("%.2f", float64(s.P99.Milliseconds())))
+		rpsData = append(rpsData, fmt.Sprintf("%.1f", float64(s.Requests)/float64(pointSeconds(report, s))))
 		successData = append(successData, fmt.Sprintf("%d", s.Success))
 		failureData = append(failureData, fmt.Sprintf("%d", s.Failures))
 	}
@@ -520,55 +973,239 @@ func GenerateHTML(report models.Report, filename string) error {
 
 	// Build errors table
 	var errorRows []ErrorRow
-	for msg, count := range report.Errors {
+	for _, class := range byCount(report.Errors) {
 		errorRows = append(errorRows, ErrorRow{
-			Message: msg,
-			Count:   count,
+			Class:   class,
+			Count:   report.Errors[class],
+			Samples: report.ErrorSamples[class],
+		})
+	}
+
+	var assertionRows []AssertionRow
+	for _, msg := range byCount(report.AssertionErrors) {
+		assertionRows = append(assertionRows, AssertionRow{Message: msg, Count: report.AssertionErrors[msg]})
+	}
+
+	var protocolRows []ProtocolRow
+	for _, proto := range byCount(report.ProtocolCounts) {
+		count := report.ProtocolCounts[proto]
+		protocolRows = append(protocolRows, ProtocolRow{
+			Name:       proto,
+			Count:      count,
+			Percentage: float64(count) / float64(max(report.TotalRequests, 1)) * 100,
 		})
 	}
-	// Sort errors by count desc
-	sort.Slice(errorRows, func(i, j int) bool {
-		return errorRows[i].Count > errorRows[j].Count
-	})
 
 	data := TemplateData{
 		GeneratedAt:      time.Now().Format("2006-01-02 15:04:05"),
 		TargetURL:        report.TargetURL,
 		Method:           report.Method,
 		TestDuration:     report.Duration.String(),
+		Elapsed:          report.Elapsed.Round(time.Millisecond).String(),
+		Interrupted:      report.Interrupted,
 		Concurrency:      report.Concurrency,
 		TotalRequests:    report.TotalRequests,
 		SuccessCount:     report.SuccessCount,
 		FailureCount:     report.FailureCount,
 		SuccessRate:      report.SuccessRate,
 		RPS:              report.RPS,
-		P50:              formatDuration(report.P50),
-		P90:              formatDuration(report.P90),
-		P95:              formatDuration(report.P95),
-		P99:              formatDuration(report.P99),
+		TotalBytes:       formatBytes(report.TotalBytes),
+		Throughput:       report.Throughput,
+		WireBytes:        formatBytes(report.TotalWireBytes),
+		WireThroughput:   report.WireThroughput,
 		Max:              formatDuration(report.Max),
 		Min:              formatDuration(report.Min),
+		Mean:             formatDuration(report.Mean),
+		StdDev:           formatDuration(report.StdDev),
 		StatusCodesTable: statusRows,
 		Errors:           errorRows,
+		AssertionErrors:  assertionRows,
+		Protocols:        protocolRows,
+		Thresholds:       report.Thresholds,
 		TimeLabels:       template.JS(strings.Join(timeLabels, ",")),
 		RPSData:          template.JS(strings.Join(rpsData, ",")),
-		P50Data:          template.JS(strings.Join(p50Data, ",")),
-		P90Data:          template.JS(strings.Join(p90Data, ",")),
-		P95Data:          template.JS(strings.Join(p95Data, ",")),
-		P99Data:          template.JS(strings.Join(p99Data, ",")),
+		LatencySeries:    latencySeriesJS(report),
 		SuccessData:      template.JS(strings.Join(successData, ",")),
 		FailureData:      template.JS(strings.Join(failureData, ",")),
 		StatusLabels:     template.JS(strings.Join(statusLabels, ",")),
 		StatusData:       template.JS(strings.Join(statusData, ",")),
+
+		AssertionFailures:  report.AssertionFailures,
+		CircuitBroken:      report.CircuitBroken,
+		CircuitBreakReason: report.CircuitBreakReason,
+		Config:             report.Config,
 	}
 
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+	if opts.EmbedJSON {
+		// encoding/json escapes <, > and &, so the report cannot end the script element.
+		raw, err := json.Marshal(report)
+		if err != nil {
+			return fmt.Errorf("failed to encode report: %w", err)
+		}
+		data.ReportJSON = template.JS(raw)
 	}
-	defer file.Close()
 
-	return tmpl.Execute(file, data)
+	if len(report.Annotations) > 0 {
+		data.Annotations, data.AnnotationMarks = annotationsJS(report)
+	}
+
+	if lo, hi, ok := histogramRange(report); ok {
+		data.HistLabels, data.HistData, data.Heatmap = histogramJS(report, lo, hi)
+	}
+
+	for _, p := range report.LatencyPercentiles() {
+		data.Latencies = append(data.Latencies, LatencyCard{Label: strings.ToUpper(p.Label()), Value: formatDuration(p.Value)})
+	}
+
+	for _, th := range report.Thresholds {
+		if !th.Passed {
+			data.ThresholdsFailed++
+		}
+	}
+
+	if len(report.Steps) > 0 {
+		for _, st := range report.Steps {
+			row := StepRow{
+				Name:        st.Name,
+				Requests:    st.Requests,
+				SuccessRate: st.SuccessRate,
+				RPS:         st.RPS,
+				P50:         formatDuration(st.P50),
+				P95:         formatDuration(st.P95),
+				P99:         formatDuration(st.P99),
+				Max:         formatDuration(st.Max),
+				StatusCodes: formatStatusCounts(st.StatusCodes),
+			}
+			if msgs := byCount(st.Errors); len(msgs) > 0 {
+				row.TopError = fmt.Sprintf("%s (×%d)", msgs[0], st.Errors[msgs[0]])
+			}
+			data.Steps = append(data.Steps, row)
+		}
+		data.StepRPSSeries = stepSeriesJS(report, func(sec models.SecondStats, s models.StepSecondStats) float64 {
+			return float64(s.Requests) / float64(pointSeconds(report, sec))
+		})
+		data.StepP95Series = stepSeriesJS(report, func(_ models.SecondStats, s models.StepSecondStats) float64 {
+			return float64(s.P95.Microseconds()) / 1000
+		})
+	}
+
+	return tmpl.Execute(w, data)
+}
+
+// stepSeriesJS builds one dataset per step from the per-step time series.
+func stepSeriesJS(r models.Report, value func(models.SecondStats, models.StepSecondStats) float64) template.JS {
+	series := make([]stepSeries, 0, len(r.Steps))
+	for _, st := range r.Steps {
+		ds := stepSeries{Label: st.Name, Data: make([]*float64, len(r.TimeSeriesData))}
+		for i, sec := range r.TimeSeriesData {
+			if ss, ok := sec.Steps[st.Name]; ok {
+				v := math.Round(value(sec, ss)*100) / 100
+				ds.Data[i] = &v
+			}
+		}
+		series = append(series, ds)
+	}
+	// encoding/json escapes <, > and &, so the result is safe inside a script tag.
+	out, _ := json.Marshal(series)
+	return template.JS(out)
+}
+
+// latencySeriesJS builds one latency chart dataset per configured percentile, in
+// milliseconds.
+func latencySeriesJS(r models.Report) template.JS {
+	pcts := r.LatencyPercentiles()
+	series := make([]stepSeries, len(pcts))
+	for i, p := range pcts {
+		series[i] = stepSeries{Label: strings.ToUpper(p.Label()), Data: make([]*float64, len(r.TimeSeriesData))}
+	}
+	for j, sec := range r.TimeSeriesData {
+		for _, v := range sec.LatencyPercentiles() {
+			for i, p := range pcts {
+				if p.P == v.P {
+					ms := math.Round(float64(v.Value.Microseconds())/10) / 100
+					series[i].Data[j] = &ms
+				}
+			}
+		}
+	}
+	out, _ := json.Marshal(series)
+	return template.JS(out)
+}
+
+// heatmap is the data of the latency heatmap: one column of cells per time series
+// point, with the requests per second that fell into each row's bin.
+type heatmap struct {
+	Rows  []string    `json:"rows"`
+	Cells [][]float64 `json:"cells"`
+}
+
+// annotationsJS lists the manual control actions and places each of them on the
+// time series point of its bucket.
+func annotationsJS(r models.Report) ([]AnnotationRow, template.JS) {
+	type mark struct {
+		X     int    `json:"x"`
+		Label string `json:"label"`
+	}
+	rows := make([]AnnotationRow, 0, len(r.Annotations))
+	marks := make([]mark, 0, len(r.Annotations))
+	for _, a := range r.Annotations {
+		rows = append(rows, AnnotationRow{Time: fmt.Sprintf("%ds", a.Second), Action: a.Action, Label: a.Label})
+		x := sort.Search(len(r.TimeSeriesData), func(i int) bool { return r.TimeSeriesData[i].Second >= a.Second })
+		marks = append(marks, mark{X: min(x, max(len(r.TimeSeriesData)-1, 0)), Label: a.Label})
+	}
+	raw, _ := json.Marshal(marks)
+	return rows, template.JS(raw)
+}
+
+// histogramRange returns the bins from the first to the last non-empty one of the
+// report histogram. Both latency distribution charts show only that range.
+func histogramRange(r models.Report) (lo, hi int, ok bool) {
+	h := r.LatencyHistogram
+	for lo < len(h) && h[lo] == 0 {
+		lo++
+	}
+	if lo == len(h) {
+		return 0, 0, false
+	}
+	return lo, len(h) - 1, true
+}
+
+// histogramJS builds the labels and counts of the latency histogram chart, and
+// the heatmap, for bins lo to hi.
+func histogramJS(r models.Report, lo, hi int) (labels, counts, heat template.JS) {
+	bounds := r.HistogramBounds
+	if len(bounds) == 0 {
+		bounds = models.LatencyBounds
+	}
+	hm := heatmap{Rows: make([]string, 0, hi-lo+1), Cells: make([][]float64, len(r.TimeSeriesData))}
+	for i := lo; i <= hi && i < len(bounds); i++ {
+		hm.Rows = append(hm.Rows, bounds[i].String())
+	}
+	for j, sec := range r.TimeSeriesData {
+		col := make([]float64, len(hm.Rows))
+		for i := range col {
+			if n := lo + i; n < len(sec.Histogram) {
+				col[i] = math.Round(float64(sec.Histogram[n])/float64(pointSeconds(r, sec))*100) / 100
+			}
+		}
+		hm.Cells[j] = col
+	}
+
+	// encoding/json escapes <, > and &, so the results are safe inside a script tag.
+	l, _ := json.Marshal(hm.Rows)
+	c, _ := json.Marshal(r.LatencyHistogram[lo : lo+len(hm.Rows)])
+	h, _ := json.Marshal(hm)
+	return template.JS(l), template.JS(c), template.JS(h)
+}
+
+// pointSeconds is the number of seconds a time series point covers. Re-bucketed
+// reports ('sayl report -bucket 10s') hold several seconds per point, and the
+// downsampled history of long runs carries its own width.
+func pointSeconds(r models.Report, s models.SecondStats) int {
+	if s.Seconds > 0 {
+		return s.Seconds
+	}
+	return max(r.BucketSeconds, 1)
 }
 
 func formatDuration(d time.Duration) string {
@@ -587,21 +1224,59 @@ func PrintConsoleReport(r models.Report) {
 	fmt.Println("===============")
 
 	fmt.Printf("  Totals:\t%d reqs, %s bytes\n", r.TotalRequests, formatBytes(r.TotalBytes))
+	fmt.Printf("  Wire:\t\t%s (%.2f MB/s wire, %.2f MB/s decoded)\n", formatBytes(r.TotalWireBytes), r.WireThroughput, r.Throughput)
 	fmt.Printf("  Success:\t%d (%.2f%%)\n", r.SuccessCount, r.SuccessRate)
 	fmt.Printf("  Failures:\t%d\n", r.FailureCount)
 	fmt.Printf("  RPS:\t\t%.2f\n", r.RPS)
-	fmt.Printf("  Duration:\t%s\n", r.Duration)
+	if r.Interrupted {
+		fmt.Printf("  Duration:\t%s of %s (⚠️  interrupted, partial results)\n", r.Elapsed.Round(time.Millisecond), r.Duration)
+	} else {
+		fmt.Printf("  Duration:\t%s\n", r.Duration)
+	}
+	if r.CircuitBroken {
+		fmt.Printf("  Stopped:\t⛔ %s\n", r.CircuitBreakReason)
+	}
 	fmt.Println()
 
 	fmt.Println("📉 Latency Distribution")
-	fmt.Printf("  Min: %s\n", formatDuration(r.Min))
-	fmt.Printf("  P50: %s\n", formatDuration(r.P50))
-	fmt.Printf("  P90: %s\n", formatDuration(r.P90))
-	fmt.Printf("  P95: %s\n", formatDuration(r.P95))
-	fmt.Printf("  P99: %s\n", formatDuration(r.P99))
-	fmt.Printf("  Max: %s\n", formatDuration(r.Max))
+	fmt.Printf("  %-7s %s\n", "Min:", formatDuration(r.Min))
+	for _, p := range r.LatencyPercentiles() {
+		fmt.Printf("  %-7s %s\n", strings.ToUpper(p.Label())+":", formatDuration(p.Value))
+	}
+	fmt.Printf("  %-7s %s\n", "Max:", formatDuration(r.Max))
+	if r.Mean > 0 {
+		fmt.Printf("  %-7s %s ± %s\n", "Mean:", formatDuration(r.Mean), formatDuration(r.StdDev))
+	}
 	fmt.Println()
 
+	if len(r.Steps) > 0 {
+		fmt.Println("🔗 Steps")
+		fmt.Printf("  %-20s %8s %8s %8s %9s %9s %9s  %s\n", "Step", "Reqs", "Success", "RPS", "P50", "P95", "P99", "Status")
+		for _, st := range r.Steps {
+			fmt.Printf("  %-20s %8d %7.1f%% %8.1f %9s %9s %9s  %s\n",
+				truncate(st.Name, 20), st.Requests, st.SuccessRate, st.RPS,
+				formatDuration(st.P50), formatDuration(st.P95), formatDuration(st.P99), formatStatusCounts(st.StatusCodes))
+		}
+		// The most frequent error of each step; all errors are listed below.
+		for _, st := range r.Steps {
+			if msgs := byCount(st.Errors); len(msgs) > 0 {
+				fmt.Printf("  ❌ %s: %s ×%d\n", st.Name, msgs[0], st.Errors[msgs[0]])
+			}
+		}
+		fmt.Println()
+	}
+
+	if st := r.Stream; st != nil {
+		fmt.Println("📡 Stream Metrics (SSE)")
+		fmt.Printf("  Streams:\t%d (%d without events)\n", st.Streams, st.EmptyStreams)
+		fmt.Printf("  Events:\t%d (%.1f/s)\n", st.Events, st.EventsPerSec)
+		fmt.Printf("  First Event:\tP50 %s  P90 %s  P99 %s  Max %s\n",
+			formatDuration(st.TTFEP50), formatDuration(st.TTFEP90), formatDuration(st.TTFEP99), formatDuration(st.TTFEMax))
+		fmt.Printf("  Event Gap:\tP50 %s  P90 %s  P99 %s  Max %s\n",
+			formatDuration(st.GapP50), formatDuration(st.GapP90), formatDuration(st.GapP99), formatDuration(st.GapMax))
+		fmt.Println()
+	}
+
 	if len(r.StatusCodes) > 0 {
 		fmt.Println("🔢 Status Codes")
 		// Sort codes
@@ -624,14 +1299,19 @@ func PrintConsoleReport(r models.Report) {
 
 	if len(r.Errors) > 0 {
 		fmt.Println("❌ Errors")
-		i := 0
-		for msg, count := range r.Errors {
+		classes := byCount(r.Errors)
+		for i, class := range classes {
 			if i >= 10 {
-				fmt.Printf("  ... and %d more error types\n", len(r.Errors)-10)
+				fmt.Printf("  ... and %d more error types\n", len(classes)-10)
 				break
 			}
-			fmt.Printf("  - %s: %d\n", msg, count)
-			i++
+			fmt.Printf("  - %s: %d\n", class, r.Errors[class])
+			for j, sample := range r.ErrorSamples[class] {
+				if j >= 2 {
+					break
+				}
+				fmt.Printf("      %s\n", truncate(sample, 100))
+			}
 		}
 		fmt.Println()
 	}
@@ -644,6 +1324,63 @@ func PrintConsoleReport(r models.Report) {
 		}
 		fmt.Println()
 	}
+
+	if len(r.Annotations) > 0 {
+		fmt.Println("🎛️  Manual Control")
+		for _, a := range r.Annotations {
+			fmt.Printf("  %6ds  %s\n", a.Second, a.Label)
+		}
+		fmt.Println()
+	}
+
+	if len(r.Thresholds) > 0 {
+		fmt.Println("🎯 Thresholds")
+		failed := 0
+		for _, th := range r.Thresholds {
+			mark := "✅ PASS"
+			if !th.Passed {
+				mark = "❌ FAIL"
+				failed++
+			}
+			fmt.Printf("  %s  %-32s actual: %s\n", mark, th.Expr, th.Actual)
+		}
+		if failed > 0 {
+			fmt.Printf("  %d of %d thresholds failed\n", failed, len(r.Thresholds))
+		} else {
+			fmt.Println("  All thresholds passed")
+		}
+		fmt.Println()
+	}
+}
+
+// formatStatusCounts renders status codes as "200×950 500×50", most frequent first.
+func formatStatusCounts(codes map[string]int) string {
+	parts := make([]string, 0, len(codes))
+	for _, code := range byCount(codes) {
+		parts = append(parts, fmt.Sprintf("%s×%d", code, codes[code]))
+	}
+	return strings.Join(parts, " ")
+}
+
+// truncate shortens s to at most n runes, marking the cut with an ellipsis.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// FormatProgress returns a compact one-line status for headless runs:
+// elapsed time, total requests, current RPS, p50/p95/p99 and error rate.
+func FormatProgress(r models.Report, elapsed time.Duration, currentRPS float64) string {
+	errorRate := 0.0
+	if r.TotalRequests > 0 {
+		errorRate = float64(r.FailureCount) / float64(r.TotalRequests) * 100
+	}
+	return fmt.Sprintf("[%6s/%s] reqs=%d rps=%.1f p50=%s p95=%s p99=%s errors=%.2f%%",
+		elapsed.Round(time.Second), r.Duration, r.TotalRequests, currentRPS,
+		formatDuration(r.P50), formatDuration(r.P95), formatDuration(r.P99), errorRate)
 }
 
 func formatBytes(b int64) string {
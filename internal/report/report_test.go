@@ -0,0 +1,163 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Amr-9/sayl/internal/compare"
+	"github.com/Amr-9/sayl/internal/stats"
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// sampleReport runs three seconds of fast and slow requests through a Monitor.
+func sampleReport() models.Report {
+	start := time.Date(2026, 3, 14, 15, 0, 0, 0, time.UTC)
+	clock := start
+	m := stats.NewMonitorAt(start, func() time.Time { return clock }, time.Second)
+	for s := 0; s < 3; s++ {
+		for i := 0; i < 10; i++ {
+			clock = start.Add(time.Duration(s)*time.Second + time.Duration(i)*50*time.Millisecond)
+			latency := 2 * time.Millisecond
+			if i%5 == 0 {
+				latency = 300 * time.Millisecond
+			}
+			m.Add(models.Result{Timestamp: clock.Add(-latency), Latency: latency, Status: 200, StepName: "browse", Protocol: "HTTP/1.1"}, true)
+		}
+	}
+	clock = start.Add(3 * time.Second)
+	rep := m.Snapshot()
+	rep.TargetURL = "http://localhost:8080/orders"
+	rep.Method = "GET"
+	rep.Duration = 3 * time.Second
+	return rep
+}
+
+func render(t *testing.T, rep models.Report, opts HTMLOptions) string {
+	t.Helper()
+	var out bytes.Buffer
+	if err := RenderHTML(&out, rep, opts); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestRenderHTMLLatencyDistribution(t *testing.T) {
+	rep := sampleReport()
+	if len(rep.LatencyHistogram) == 0 {
+		t.Fatal("the sample report has no histogram")
+	}
+	html := render(t, rep, HTMLOptions{})
+	for _, want := range []string{
+		`<canvas id="histogramChart">`,
+		`<canvas id="heatmapChart">`,
+		"Latency Distribution (log scale)",
+		"Latency Heatmap",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report is missing %q", want)
+		}
+	}
+
+	// Without latency data, both sections are left out.
+	rep.LatencyHistogram = nil
+	html = render(t, rep, HTMLOptions{})
+	if strings.Contains(html, `<canvas id="histogramChart">`) || strings.Contains(html, `<canvas id="heatmapChart">`) {
+		t.Error("histogram sections rendered without a histogram")
+	}
+}
+
+func TestHistogramJS(t *testing.T) {
+	rep := sampleReport()
+	lo, hi, ok := histogramRange(rep)
+	if !ok {
+		t.Fatal("no histogram range")
+	}
+	if rep.LatencyHistogram[lo] == 0 || rep.LatencyHistogram[hi] == 0 {
+		t.Fatalf("range %d-%d does not start and end on non-empty bins: %v", lo, hi, rep.LatencyHistogram)
+	}
+
+	labels, counts, heat := histogramJS(rep, lo, hi)
+	var rows []string
+	var bins []int64
+	var hm heatmap
+	for _, v := range []struct {
+		js  string
+		dst any
+	}{{string(labels), &rows}, {string(counts), &bins}, {string(heat), &hm}} {
+		if err := json.Unmarshal([]byte(v.js), v.dst); err != nil {
+			t.Fatalf("invalid chart data %s: %v", v.js, err)
+		}
+	}
+	if len(rows) != hi-lo+1 || len(bins) != len(rows) || len(hm.Rows) != len(rows) {
+		t.Fatalf("%d labels, %d counts and %d heatmap rows for %d bins", len(rows), len(bins), len(hm.Rows), hi-lo+1)
+	}
+	var total int64
+	for _, n := range bins {
+		total += n
+	}
+	if total != rep.TotalRequests {
+		t.Fatalf("histogram holds %d requests, want %d", total, rep.TotalRequests)
+	}
+
+	// Heatmap cells are requests per second, so the columns add up to each point's requests.
+	if len(hm.Cells) != len(rep.TimeSeriesData) {
+		t.Fatalf("%d heatmap columns for %d points", len(hm.Cells), len(rep.TimeSeriesData))
+	}
+	for x, col := range hm.Cells {
+		var sum float64
+		for _, v := range col {
+			sum += v
+		}
+		if want := float64(rep.TimeSeriesData[x].Requests); sum != want {
+			t.Errorf("column %d holds %g req/s, want %g", x, sum, want)
+		}
+	}
+
+	// A point of a re-bucketed report covers several seconds.
+	rep.BucketSeconds = 10
+	_, _, heat = histogramJS(rep, lo, hi)
+	hm = heatmap{}
+	if err := json.Unmarshal([]byte(heat), &hm); err != nil {
+		t.Fatal(err)
+	}
+	for x, col := range hm.Cells {
+		var sum float64
+		for _, v := range col {
+			sum += v
+		}
+		if want := float64(rep.TimeSeriesData[x].Requests) / 10; sum != want {
+			t.Errorf("10s column %d holds %g req/s, want %g", x, sum, want)
+		}
+	}
+}
+
+// TestGenerateHTMLEmbedJSON checks that the report embedded in report.html loads
+// back as the report it was written from.
+func TestGenerateHTMLEmbedJSON(t *testing.T) {
+	rep := sampleReport()
+	rep.Config = "target:\n  url: http://localhost:8080/orders?q=</script>\n"
+	path := filepath.Join(t.TempDir(), "report.html")
+	if err := GenerateHTML(rep, path, HTMLOptions{EmbedJSON: true}); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := compare.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.TotalRequests != rep.TotalRequests || loaded.P95 != rep.P95 || loaded.Config != rep.Config {
+		t.Fatalf("loaded %d requests, p95 %s, config %q", loaded.TotalRequests, loaded.P95, loaded.Config)
+	}
+
+	// Without embedding, there is nothing for sayl diff to load.
+	if err := GenerateHTML(rep, path, HTMLOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); bytes.Contains(data, []byte(compare.EmbeddedReportTag)) {
+		t.Fatal("report JSON embedded without EmbedJSON")
+	}
+}
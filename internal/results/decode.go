@@ -0,0 +1,224 @@
+package results
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// decoder reads records in one of the supported formats.
+type decoder interface {
+	decode(rec *Record) error
+}
+
+// Reader reads a results file written by Writer. The format is detected from
+// the file contents, so files can be renamed freely.
+type Reader struct {
+	path   string
+	format string
+	file   *os.File
+	dec    decoder
+	n      int64 // records read so far, for error messages
+}
+
+// Open opens a results file for reading.
+func Open(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open results file '%s': %w", path, err)
+	}
+	br := bufio.NewReaderSize(file, 1<<20)
+
+	r := &Reader{path: path, file: file}
+	csvPrefix := csvHeader[0] + ","
+	head, _ := br.Peek(max(len(binaryMagic), len(csvPrefix)))
+	switch {
+	case bytes.HasPrefix(head, []byte(binaryMagic)):
+		br.Discard(len(binaryMagic))
+		r.format = FormatBinary
+		r.dec = &binaryDecoder{r: br}
+	case bytes.HasPrefix(head, []byte(csvPrefix)):
+		r.format = FormatCSV
+		r.dec, err = newCSVDecoder(br)
+	default:
+		r.format = FormatJSONL
+		r.dec = &jsonlDecoder{dec: json.NewDecoder(br)}
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read results file '%s': %w", path, err)
+	}
+	return r, nil
+}
+
+// Format returns the detected format of the file.
+func (r *Reader) Format() string {
+	return r.format
+}
+
+// Next reads the next record. It returns io.EOF after the last record.
+func (r *Reader) Next() (Record, error) {
+	var rec Record
+	if err := r.dec.decode(&rec); err != nil {
+		if err == io.EOF {
+			return rec, io.EOF
+		}
+		// A run killed mid-write leaves a truncated last record; treat it as the end.
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return rec, io.EOF
+		}
+		return rec, fmt.Errorf("results file '%s', record %d: %w", r.path, r.n+1, err)
+	}
+	r.n++
+	return rec, nil
+}
+
+// Close closes the underlying file.
+func (r *Reader) Close() error {
+	return r.file.Close()
+}
+
+type jsonlDecoder struct {
+	dec *json.Decoder
+}
+
+func (d *jsonlDecoder) decode(rec *Record) error {
+	return d.dec.Decode(rec)
+}
+
+type csvDecoder struct {
+	r *csv.Reader
+}
+
+func newCSVDecoder(r io.Reader) (*csvDecoder, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(csvHeader)
+	cr.ReuseRecord = true
+	if _, err := cr.Read(); err != nil { // header
+		return nil, err
+	}
+	return &csvDecoder{r: cr}, nil
+}
+
+func (d *csvDecoder) decode(rec *Record) error {
+	row, err := d.r.Read()
+	if err != nil {
+		return err
+	}
+
+	if rec.Timestamp, err = time.Parse(time.RFC3339Nano, row[0]); err != nil {
+		return err
+	}
+	rec.Step = row[1]
+	if rec.Status, err = strconv.Atoi(row[2]); err != nil {
+		return err
+	}
+	if rec.LatencyUs, err = strconv.ParseInt(row[3], 10, 64); err != nil {
+		return err
+	}
+	if rec.Bytes, err = strconv.ParseInt(row[4], 10, 64); err != nil {
+		return err
+	}
+	if rec.WireBytes, err = strconv.ParseInt(row[5], 10, 64); err != nil {
+		return err
+	}
+	rec.Protocol = row[6]
+	rec.Success = row[7] == "true"
+	rec.Timeout = row[8] == "true"
+	rec.Error = row[9]
+	rec.Assertion = row[10]
+	return nil
+}
+
+// maxStringLen guards against corrupt files asking for huge allocations.
+const maxStringLen = 1 << 20
+
+// binaryDecoder reads the format described on binaryEncoder.
+type binaryDecoder struct {
+	r      *bufio.Reader
+	lastTs int64
+	dict   []string
+}
+
+func (d *binaryDecoder) decode(rec *Record) error {
+	delta, err := binary.ReadVarint(d.r)
+	if err != nil {
+		return err // io.EOF at a record boundary ends the file cleanly
+	}
+	d.lastTs += delta
+	rec.Timestamp = time.Unix(0, d.lastTs)
+
+	var fields [4]uint64
+	for i := range fields {
+		if fields[i], err = d.uvarint(); err != nil {
+			return err
+		}
+	}
+	rec.LatencyUs = int64(fields[0])
+	rec.Status = int(fields[1])
+	rec.Bytes = int64(fields[2])
+	rec.WireBytes = int64(fields[3])
+
+	flags, err := d.r.ReadByte()
+	if err != nil {
+		return io.ErrUnexpectedEOF
+	}
+	rec.Success = flags&flagSuccess != 0
+	rec.Timeout = flags&flagTimeout != 0
+
+	for _, s := range []*string{&rec.Step, &rec.Protocol, &rec.Error, &rec.Assertion} {
+		if *s, err = d.string(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uvarint reads a uvarint inside a record, where EOF means the record was truncated.
+func (d *binaryDecoder) uvarint() (uint64, error) {
+	v, err := binary.ReadUvarint(d.r)
+	if err == io.EOF {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return v, err
+}
+
+func (d *binaryDecoder) string() (string, error) {
+	id, err := d.uvarint()
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case id == 0:
+		return "", nil
+	case id >= 2 && id-2 < uint64(len(d.dict)):
+		return d.dict[id-2], nil
+	case id != 1 && id-2 != uint64(len(d.dict)):
+		return "", fmt.Errorf("invalid string id %d", id)
+	}
+
+	n, err := d.uvarint()
+	if err != nil {
+		return "", err
+	}
+	if n > maxStringLen {
+		return "", fmt.Errorf("invalid string length %d", n)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(d.r, buf); err != nil {
+		return "", io.ErrUnexpectedEOF
+	}
+	s := string(buf)
+	if id != 1 {
+		d.dict = append(d.dict, s)
+	}
+	return s, nil
+}
@@ -0,0 +1,155 @@
+package results
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// csvHeader is the first row of CSV results files.
+var csvHeader = []string{"timestamp", "step", "status", "latency_us", "bytes", "wire_bytes", "protocol", "success", "timeout", "error", "assertion"}
+
+// binaryMagic starts every binary results file.
+const binaryMagic = "SAYLRES1"
+
+// maxDictSize caps the number of distinct strings remembered by the binary format.
+const maxDictSize = 4096
+
+// Flag bits of a binary record.
+const (
+	flagSuccess = 1 << iota
+	flagTimeout
+)
+
+type jsonlEncoder struct {
+	enc *json.Encoder
+}
+
+func newJSONLEncoder(w *bufio.Writer) *jsonlEncoder {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &jsonlEncoder{enc: enc}
+}
+
+func (e *jsonlEncoder) encode(rec *Record) error {
+	return e.enc.Encode(rec)
+}
+
+type csvEncoder struct {
+	w   *csv.Writer
+	row []string
+}
+
+func newCSVEncoder(w *bufio.Writer) (*csvEncoder, error) {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return nil, err
+	}
+	return &csvEncoder{w: cw, row: make([]string, len(csvHeader))}, nil
+}
+
+func (e *csvEncoder) encode(rec *Record) error {
+	e.row[0] = rec.Timestamp.Format(time.RFC3339Nano)
+	e.row[1] = rec.Step
+	e.row[2] = strconv.Itoa(rec.Status)
+	e.row[3] = strconv.FormatInt(rec.LatencyUs, 10)
+	e.row[4] = strconv.FormatInt(rec.Bytes, 10)
+	e.row[5] = strconv.FormatInt(rec.WireBytes, 10)
+	e.row[6] = rec.Protocol
+	e.row[7] = strconv.FormatBool(rec.Success)
+	e.row[8] = strconv.FormatBool(rec.Timeout)
+	e.row[9] = rec.Error
+	e.row[10] = rec.Assertion
+	// csv.NewWriter reuses our bufio.Writer (it is large enough), so rows land
+	// directly in the shared buffer and are flushed by Writer.Close.
+	return e.w.Write(e.row)
+}
+
+// binaryEncoder writes a compact varint-based format. Timestamps are stored as
+// deltas from the previous record and repeated strings (step names, protocols,
+// error messages) as dictionary ids, so a typical record takes 10-15 bytes.
+//
+// Record layout:
+//
+//	varint  timestamp delta in ns (the first record is relative to the Unix epoch)
+//	uvarint latency in µs, status, bytes, wire bytes
+//	byte    flags (bit 0 success, bit 1 timeout)
+//	string  step, protocol, error, assertion
+//
+// Strings are a uvarint id: 0 is the empty string, 1 is a literal that is not
+// remembered, and id n >= 2 refers to dictionary entry n-2. The id one past the
+// end of the dictionary introduces a new entry. Literals and new entries are
+// followed by their uvarint length and bytes. The dictionary is capped so unique
+// error messages cannot grow it without bound.
+type binaryEncoder struct {
+	w      *bufio.Writer
+	lastTs int64
+	dict   map[string]uint64
+	tmp    [binary.MaxVarintLen64]byte
+}
+
+func newBinaryEncoder(w *bufio.Writer) (*binaryEncoder, error) {
+	if _, err := w.WriteString(binaryMagic); err != nil {
+		return nil, err
+	}
+	return &binaryEncoder{w: w, dict: make(map[string]uint64)}, nil
+}
+
+func (e *binaryEncoder) encode(rec *Record) error {
+	ts := rec.Timestamp.UnixNano()
+	e.putVarint(ts - e.lastTs)
+	e.lastTs = ts
+
+	e.putUvarint(uint64(rec.LatencyUs))
+	e.putUvarint(uint64(rec.Status))
+	e.putUvarint(uint64(rec.Bytes))
+	e.putUvarint(uint64(rec.WireBytes))
+
+	var flags byte
+	if rec.Success {
+		flags |= flagSuccess
+	}
+	if rec.Timeout {
+		flags |= flagTimeout
+	}
+	e.w.WriteByte(flags)
+
+	e.putString(rec.Step)
+	e.putString(rec.Protocol)
+	e.putString(rec.Error)
+	_, err := e.putString(rec.Assertion)
+	return err
+}
+
+func (e *binaryEncoder) putVarint(v int64) {
+	n := binary.PutVarint(e.tmp[:], v)
+	e.w.Write(e.tmp[:n])
+}
+
+func (e *binaryEncoder) putUvarint(v uint64) {
+	n := binary.PutUvarint(e.tmp[:], v)
+	e.w.Write(e.tmp[:n])
+}
+
+func (e *binaryEncoder) putString(s string) (int, error) {
+	if s == "" {
+		e.putUvarint(0)
+		return 0, nil
+	}
+	if id, ok := e.dict[s]; ok {
+		e.putUvarint(id)
+		return 0, nil
+	}
+	if len(e.dict) >= maxDictSize {
+		e.putUvarint(1)
+	} else {
+		id := uint64(len(e.dict) + 2)
+		e.dict[s] = id
+		e.putUvarint(id)
+	}
+	e.putUvarint(uint64(len(s)))
+	return e.w.WriteString(s)
+}
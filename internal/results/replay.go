@@ -0,0 +1,124 @@
+package results
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/Amr-9/sayl/internal/stats"
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// Filter selects the records used when rebuilding a report.
+type Filter struct {
+	From time.Duration // Skip requests started earlier than this offset from the first request
+	To   time.Duration // Skip requests started at or after this offset (0 = until the end)
+	Step string        // Only keep this step ("" = all steps)
+}
+
+// Result converts a record back into an engine result.
+func (rec Record) Result() models.Result {
+	res := models.Result{
+		Timestamp: rec.Timestamp,
+		Latency:   time.Duration(rec.LatencyUs) * time.Microsecond,
+		Status:    rec.Status,
+		Bytes:     rec.Bytes,
+		WireBytes: rec.WireBytes,
+		StepName:  rec.Step,
+		Protocol:  rec.Protocol,
+	}
+	if rec.Error != "" {
+		res.Error = errors.New(rec.Error)
+	}
+	if rec.Assertion != "" {
+		res.AssertionError = errors.New(rec.Assertion)
+	}
+	// The original error type is lost, so apply the Monitor's timeout grouping here.
+	if rec.Timeout && res.Status == 0 {
+		res.Status = 1
+	}
+	return res
+}
+
+// Replay rebuilds a report from a results file by feeding the selected records
+// through a stats.Monitor, exactly as they were counted during the live run.
+// The time series uses buckets of resolution (one second when zero), and the
+// latency percentiles ps (the default set when empty) are reported.
+func Replay(path string, f Filter, resolution time.Duration, ps []float64) (models.Report, error) {
+	// First pass: the run starts at the earliest request. Records are written in
+	// completion order, so the first record is not necessarily the earliest.
+	var runStart time.Time
+	err := scan(path, func(rec Record) {
+		if runStart.IsZero() || rec.Timestamp.Before(runStart) {
+			runStart = rec.Timestamp
+		}
+	})
+	if err != nil {
+		return models.Report{}, err
+	}
+	if runStart.IsZero() {
+		return models.Report{}, fmt.Errorf("results file '%s' contains no records", path)
+	}
+
+	// Second pass: replay the selected records. The Monitor's clock follows the
+	// completion time of each record, which is when the live run counted it.
+	windowStart := runStart.Add(f.From)
+	var clock, end time.Time
+	monitor := stats.NewMonitorAt(windowStart, func() time.Time { return clock }, resolution)
+	monitor.SetPercentiles(ps)
+
+	matched := 0
+	err = scan(path, func(rec Record) {
+		offset := rec.Timestamp.Sub(runStart)
+		if offset < f.From || (f.To > 0 && offset >= f.To) {
+			return
+		}
+		if f.Step != "" && rec.Step != f.Step {
+			return
+		}
+		res := rec.Result()
+		clock = rec.Timestamp.Add(res.Latency)
+		if clock.After(end) {
+			end = clock
+		}
+		monitor.Add(res, rec.Success)
+		matched++
+	})
+	if err != nil {
+		return models.Report{}, err
+	}
+	if matched == 0 {
+		return models.Report{}, fmt.Errorf("no records in '%s' match the selected window and step", path)
+	}
+
+	// Rates are computed over the selected window, not the whole file.
+	if f.To > 0 && end.After(runStart.Add(f.To)) {
+		end = runStart.Add(f.To)
+	}
+	clock = end
+	rep := monitor.Snapshot()
+	rep.Duration = end.Sub(windowStart)
+	rep.Elapsed = rep.Duration
+	return rep, nil
+}
+
+// scan calls fn for every record in the file.
+func scan(path string, fn func(Record)) error {
+	r, err := Open(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		fn(rec)
+	}
+}
@@ -0,0 +1,132 @@
+package results
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeRun writes a 20-second run with one successful "browse" and one failed
+// "buy" request per second, and returns the path of the results file.
+func writeRun(t *testing.T, format string) string {
+	t.Helper()
+	var recs []Record
+	for s := 0; s < 20; s++ {
+		ts := base.Add(time.Duration(s)*time.Second + 100*time.Millisecond)
+		recs = append(recs,
+			Record{Timestamp: ts, Step: "browse", Status: 200, LatencyUs: 10000, Bytes: 100, Success: true},
+			Record{Timestamp: ts.Add(time.Millisecond), Step: "buy", Status: 500, LatencyUs: 20000, Bytes: 10},
+		)
+	}
+	path := filepath.Join(t.TempDir(), "results."+format)
+	if err := os.WriteFile(path, encodeRecords(t, format, recs), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReplayFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    Filter
+		requests  int64
+		successes int64
+		steps     []string
+	}{
+		{"all", Filter{}, 40, 20, []string{"browse", "buy"}},
+		{"from", Filter{From: 5 * time.Second}, 30, 15, []string{"browse", "buy"}},
+		{"to", Filter{To: 10 * time.Second}, 20, 10, []string{"browse", "buy"}},
+		{"window", Filter{From: 5 * time.Second, To: 10 * time.Second}, 10, 5, []string{"browse", "buy"}},
+		{"step", Filter{Step: "buy"}, 20, 0, []string{"buy"}},
+		{"step in window", Filter{From: 15 * time.Second, Step: "browse"}, 5, 5, []string{"browse"}},
+	}
+	for _, format := range Formats {
+		path := writeRun(t, format)
+		for _, tt := range tests {
+			t.Run(format+"/"+tt.name, func(t *testing.T) {
+				rep, err := Replay(path, tt.filter, 0, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if rep.TotalRequests != tt.requests || rep.SuccessCount != tt.successes {
+					t.Fatalf("got %d requests, %d successes; want %d, %d", rep.TotalRequests, rep.SuccessCount, tt.requests, tt.successes)
+				}
+				var steps []string
+				for _, s := range rep.Steps {
+					steps = append(steps, s.Name)
+				}
+				if len(steps) != len(tt.steps) {
+					t.Fatalf("steps %v, want %v", steps, tt.steps)
+				}
+				for i := range steps {
+					if steps[i] != tt.steps[i] {
+						t.Fatalf("steps %v, want %v", steps, tt.steps)
+					}
+				}
+				var inSeries int64
+				for _, b := range rep.TimeSeriesData {
+					inSeries += b.Requests
+				}
+				if inSeries != tt.requests {
+					t.Fatalf("time series holds %d requests, want %d", inSeries, tt.requests)
+				}
+			})
+		}
+	}
+}
+
+// TestReplayWindowDuration checks that rates are computed over the selected
+// window, which ends at the last completion inside it, rather than the whole file.
+func TestReplayWindowDuration(t *testing.T) {
+	path := writeRun(t, FormatJSONL)
+	rep, err := Replay(path, Filter{From: 5 * time.Second, To: 10 * time.Second}, 0, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The last request of the window starts at 9.1s and ends 21ms later.
+	if want := 4*time.Second + 21*time.Millisecond; rep.Duration != want {
+		t.Fatalf("duration %s, want %s", rep.Duration, want)
+	}
+}
+
+func TestReplayBucket(t *testing.T) {
+	path := writeRun(t, FormatJSONL)
+	rep, err := Replay(path, Filter{}, 10*time.Second, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.BucketSeconds != 10 {
+		t.Fatalf("bucket of %ds, want 10s", rep.BucketSeconds)
+	}
+	if len(rep.TimeSeriesData) == 0 || len(rep.TimeSeriesData) > 2 {
+		t.Fatalf("%d buckets of 10s for a 20s run", len(rep.TimeSeriesData))
+	}
+	var total int64
+	for _, b := range rep.TimeSeriesData {
+		total += b.Requests
+	}
+	if total != rep.TotalRequests {
+		t.Fatalf("buckets hold %d requests, want %d", total, rep.TotalRequests)
+	}
+}
+
+func TestReplayNoMatch(t *testing.T) {
+	path := writeRun(t, FormatJSONL)
+	for name, f := range map[string]Filter{
+		"unknown step": {Step: "checkout"},
+		"past the end": {From: time.Minute},
+	} {
+		if _, err := Replay(path, f, 0, nil); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.jsonl")
+	if err := os.WriteFile(empty, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Replay(empty, Filter{}, 0, nil); err == nil {
+		t.Error("empty file: want an error")
+	}
+}
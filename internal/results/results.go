@@ -0,0 +1,190 @@
+// Package results writes every individual request result to a file for offline
+// analysis. Records are handed to a background goroutine through a buffered channel,
+// so the engine never waits on disk I/O; if the buffer ever fills up, records are
+// dropped and counted instead of slowing the test down.
+package results
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/Amr-9/sayl/internal/stats"
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// Supported output formats.
+const (
+	FormatJSONL  = "jsonl"
+	FormatCSV    = "csv"
+	FormatBinary = "bin"
+)
+
+// Formats lists the supported formats (used for validation and suggestions).
+var Formats = []string{FormatJSONL, FormatCSV, FormatBinary}
+
+// queueSize is the number of records that can be pending before new ones are dropped.
+const queueSize = 1 << 16
+
+// Record is a single request result as written to the results file.
+type Record struct {
+	Timestamp time.Time `json:"ts"`
+	Step      string    `json:"step,omitempty"`
+	Status    int       `json:"status"`
+	LatencyUs int64     `json:"latency_us"`
+	Bytes     int64     `json:"bytes"`
+	WireBytes int64     `json:"wire_bytes"`
+	Protocol  string    `json:"protocol,omitempty"`
+	Success   bool      `json:"success"`
+	Timeout   bool      `json:"timeout,omitempty"`
+	Error     string    `json:"error,omitempty"`
+	Assertion string    `json:"assertion,omitempty"`
+}
+
+// NewRecord converts an engine result into a record.
+func NewRecord(res models.Result, success bool) Record {
+	rec := Record{
+		Timestamp: res.Timestamp,
+		Step:      res.StepName,
+		Status:    res.Status,
+		LatencyUs: res.Latency.Microseconds(),
+		Bytes:     res.Bytes,
+		WireBytes: res.WireBytes,
+		Protocol:  res.Protocol,
+		Success:   success && res.AssertionError == nil,
+	}
+	if res.Error != nil {
+		rec.Error = res.Error.Error()
+		rec.Timeout = stats.IsTimeout(res.Error)
+	}
+	if res.AssertionError != nil {
+		rec.Assertion = res.AssertionError.Error()
+	}
+	return rec
+}
+
+// FormatFromPath infers the format from a file extension, defaulting to JSONL.
+func FormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV
+	case ".bin", ".sayl":
+		return FormatBinary
+	default:
+		return FormatJSONL
+	}
+}
+
+// encoder writes records in one of the supported formats.
+type encoder interface {
+	encode(rec *Record) error
+}
+
+// Writer is a buffered, non-blocking results sink.
+type Writer struct {
+	path   string
+	format string
+	file   *os.File
+	buf    *bufio.Writer
+	enc    encoder
+	queue  chan Record
+	done   chan struct{}
+	err    error // first encoding error; read after done is closed
+
+	written atomic.Int64
+	dropped atomic.Int64
+}
+
+// Create opens path for writing and starts the background encoder.
+// An empty format is inferred from the file extension.
+func Create(path, format string) (*Writer, error) {
+	if format == "" {
+		format = FormatFromPath(path)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create results file '%s': %w", path, err)
+	}
+
+	w := &Writer{
+		path:   path,
+		format: format,
+		file:   file,
+		buf:    bufio.NewWriterSize(file, 1<<20),
+		queue:  make(chan Record, queueSize),
+		done:   make(chan struct{}),
+	}
+
+	switch format {
+	case FormatJSONL:
+		w.enc = newJSONLEncoder(w.buf)
+	case FormatCSV:
+		w.enc, err = newCSVEncoder(w.buf)
+	case FormatBinary:
+		w.enc, err = newBinaryEncoder(w.buf)
+	default:
+		err = fmt.Errorf("unsupported results format '%s' (expected jsonl, csv or bin)", format)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(path)
+		return nil, err
+	}
+
+	go w.loop()
+	return w, nil
+}
+
+// Write queues a result without blocking. If the queue is full the record is dropped.
+func (w *Writer) Write(res models.Result, success bool) {
+	select {
+	case w.queue <- NewRecord(res, success):
+	default:
+		w.dropped.Add(1)
+	}
+}
+
+func (w *Writer) loop() {
+	defer close(w.done)
+	for rec := range w.queue {
+		if w.err != nil {
+			continue // keep draining so Write never blocks
+		}
+		if err := w.enc.encode(&rec); err != nil {
+			w.err = fmt.Errorf("failed to write results file '%s': %w", w.path, err)
+			continue
+		}
+		w.written.Add(1)
+	}
+}
+
+// Close flushes pending records and closes the file. It must be called once,
+// after the last Write.
+func (w *Writer) Close() error {
+	close(w.queue)
+	<-w.done
+
+	err := w.err
+	if ferr := w.buf.Flush(); err == nil && ferr != nil {
+		err = fmt.Errorf("failed to flush results file '%s': %w", w.path, ferr)
+	}
+	if cerr := w.file.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("failed to close results file '%s': %w", w.path, cerr)
+	}
+	return err
+}
+
+// Summary describes what was written, for the final report.
+func (w *Writer) Summary() *models.ResultsLog {
+	return &models.ResultsLog{
+		Path:    w.path,
+		Format:  w.format,
+		Records: w.written.Load(),
+		Dropped: w.dropped.Load(),
+	}
+}
@@ -0,0 +1,240 @@
+package results
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var base = time.Date(2026, 3, 14, 15, 9, 26, 535897932, time.UTC)
+
+func sampleRecords() []Record {
+	return []Record{
+		{Timestamp: base, Step: "login", Status: 200, LatencyUs: 12345, Bytes: 512, WireBytes: 230, Protocol: "HTTP/2.0", Success: true},
+		{Timestamp: base.Add(1500 * time.Microsecond), Step: "login", Status: 200, LatencyUs: 9000, Bytes: 512, WireBytes: 230, Protocol: "HTTP/2.0", Success: true},
+		// Workers finish out of order, so timestamps may go backwards.
+		{Timestamp: base.Add(-3 * time.Millisecond), Step: "search", Status: 503, LatencyUs: 250000, Bytes: 0, WireBytes: 0, Protocol: "HTTP/2.0", Error: "", Assertion: "status 503 not in [200]"},
+		{Timestamp: base.Add(2 * time.Second), Step: "", Status: 0, LatencyUs: 30000000, Timeout: true, Error: "context deadline exceeded (Client.Timeout exceeded while awaiting headers)"},
+		{Timestamp: base.Add(2*time.Second + 1), Step: "search", Status: 0, LatencyUs: 0, Error: "context deadline exceeded (Client.Timeout exceeded while awaiting headers)"},
+		{Timestamp: base.Add(3 * time.Second), Step: "unicode ✓", Status: 201, LatencyUs: 1, Bytes: 1 << 40, WireBytes: 1 << 33, Protocol: "HTTP/1.1", Success: true, Error: "a,b \"quoted\"\nnew line"},
+	}
+}
+
+// encodeRecords writes recs in format and returns the raw file contents.
+func encodeRecords(t *testing.T, format string, recs []Record) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	w := bufio.NewWriter(&out)
+	var enc encoder
+	var err error
+	switch format {
+	case FormatJSONL:
+		enc = newJSONLEncoder(w)
+	case FormatCSV:
+		enc, err = newCSVEncoder(w)
+	case FormatBinary:
+		enc, err = newBinaryEncoder(w)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range recs {
+		if err := enc.encode(&recs[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return out.Bytes()
+}
+
+// readFile writes data to a file, opens it with Open and reads every record.
+func readFile(t *testing.T, data []byte) (string, []Record, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "results")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer r.Close()
+
+	var recs []Record
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return r.Format(), recs, nil
+		}
+		if err != nil {
+			return r.Format(), recs, err
+		}
+		recs = append(recs, rec)
+	}
+}
+
+func sameRecord(a, b Record) bool {
+	if !a.Timestamp.Equal(b.Timestamp) {
+		return false
+	}
+	a.Timestamp, b.Timestamp = time.Time{}, time.Time{}
+	return a == b
+}
+
+func checkRecords(t *testing.T, got, want []Record) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("read %d records, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if !sameRecord(got[i], want[i]) {
+			t.Fatalf("record %d:\n got %+v\nwant %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			want := sampleRecords()
+			detected, got, err := readFile(t, encodeRecords(t, format, want))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if detected != format {
+				t.Fatalf("detected format %q, want %q", detected, format)
+			}
+			checkRecords(t, got, want)
+		})
+	}
+}
+
+func TestRoundTripEmpty(t *testing.T) {
+	for _, format := range Formats {
+		t.Run(format, func(t *testing.T) {
+			_, got, err := readFile(t, encodeRecords(t, format, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 0 {
+				t.Fatalf("read %d records from an empty file", len(got))
+			}
+		})
+	}
+}
+
+func TestBinaryDictionaryCap(t *testing.T) {
+	var want []Record
+	for i := 0; i < maxDictSize+10; i++ {
+		want = append(want, Record{Timestamp: base.Add(time.Duration(i)), Error: fmt.Sprintf("error %d", i)})
+	}
+	// Strings from before the cap are still referenced, later ones are literals.
+	want = append(want,
+		Record{Timestamp: base, Error: "error 0"},
+		Record{Timestamp: base, Error: fmt.Sprintf("error %d", maxDictSize+5)},
+		Record{Timestamp: base, Error: "never seen before"},
+	)
+
+	_, got, err := readFile(t, encodeRecords(t, FormatBinary, want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRecords(t, got, want)
+}
+
+// TestBinaryTruncated cuts a file at every byte: the records before the cut are
+// read back unchanged and the partial last record ends the file.
+func TestBinaryTruncated(t *testing.T) {
+	want := sampleRecords()
+	data := encodeRecords(t, FormatBinary, want)
+
+	// Offsets at which each record ends.
+	var ends []int
+	for i := range want {
+		ends = append(ends, len(encodeRecords(t, FormatBinary, want[:i+1])))
+	}
+
+	for cut := len(binaryMagic); cut < len(data); cut++ {
+		_, got, err := readFile(t, data[:cut])
+		if err != nil {
+			t.Fatalf("cut at %d: %v", cut, err)
+		}
+		complete := 0
+		for complete < len(ends) && ends[complete] <= cut {
+			complete++
+		}
+		if len(got) != complete {
+			t.Fatalf("cut at %d: read %d records, want %d", cut, len(got), complete)
+		}
+		for i := range got {
+			if !sameRecord(got[i], want[i]) {
+				t.Fatalf("cut at %d, record %d:\n got %+v\nwant %+v", cut, i, got[i], want[i])
+			}
+		}
+	}
+}
+
+func TestBinaryCorrupt(t *testing.T) {
+	record := func(fields ...uint64) []byte {
+		b := []byte(binaryMagic)
+		b = binary.AppendVarint(b, 0) // Timestamp delta
+		for _, v := range []uint64{1500, 200, 0, 0} {
+			b = binary.AppendUvarint(b, v) // Latency, status, bytes, wire bytes
+		}
+		b = append(b, flagSuccess)
+		for _, f := range fields {
+			b = binary.AppendUvarint(b, f)
+		}
+		return b
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"unknown dictionary id", record(7), "invalid string id 7"},
+		{"id past the next entry", record(0, 0, 3), "invalid string id 3"},
+		{"huge string", record(2, maxStringLen+1), "invalid string length"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := readFile(t, tt.data)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got error %v, want one containing %q", err, tt.want)
+			}
+			if !strings.Contains(err.Error(), "record 1") {
+				t.Fatalf("error %q does not name the record", err)
+			}
+		})
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	tests := map[string]string{
+		"results.jsonl":     FormatJSONL,
+		"results.json":      FormatJSONL,
+		"results":           FormatJSONL,
+		"results.csv":       FormatCSV,
+		"RESULTS.CSV":       FormatCSV,
+		"results.bin":       FormatBinary,
+		"runs/a/run.sayl":   FormatBinary,
+		"results.csv.gz":    FormatJSONL,
+		"dir.csv/results":   FormatJSONL,
+		"results.bin.jsonl": FormatJSONL,
+	}
+	for path, want := range tests {
+		if got := FormatFromPath(path); got != want {
+			t.Errorf("FormatFromPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Amr-9/sayl/internal/attacker"
+	"github.com/Amr-9/sayl/internal/circuitbreaker"
+	"github.com/Amr-9/sayl/internal/results"
+	"github.com/Amr-9/sayl/internal/stats"
+	"github.com/Amr-9/sayl/internal/threshold"
+	"github.com/Amr-9/sayl/pkg/models"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Runner executes a single load test: it drives the attack engine for the configured
+// duration and feeds every result into a Monitor. The TUI and the headless CLI both
+// use it, so results are counted the same way regardless of how the run is displayed.
+type Runner struct {
+	config    models.Config
+	monitor   *stats.Monitor
+	engine    *attacker.Engine
+	breaker   *circuitbreaker.Breaker
+	results   chan models.Result
+	observers []Observer
+
+	// snapMu serialises Snapshot calls, which may come from the display and from
+	// live endpoints such as the metrics server at the same time.
+	snapMu sync.Mutex
+
+	// Set when Run returns.
+	elapsed     time.Duration
+	interrupted bool
+	resultsLog  *models.ResultsLog
+}
+
+// Observer is notified when a run starts, so live endpoints (such as the
+// Prometheus metrics server) can follow whichever run is current.
+type Observer interface {
+	Attach(r *Runner)
+}
+
+// ResultObserver is an Observer that also receives every result as it is recorded,
+// e.g. to stream sampled per-request points. Record is called from the collecting
+// goroutine, so it must not block.
+type ResultObserver interface {
+	Observer
+	Record(res models.Result, success bool)
+}
+
+// New prepares a run for cfg. The duration is derived from the stages when it is not set.
+func New(cfg models.Config, observers ...Observer) *Runner {
+	if cfg.Duration == 0 && len(cfg.Stages) > 0 {
+		for _, s := range cfg.Stages {
+			cfg.Duration += s.Duration
+		}
+	}
+	if len(cfg.SuccessCodes) == 0 {
+		cfg.SuccessCodes = map[int]bool{200: true}
+	}
+
+	// stop_if was already parsed by the config loader, so this cannot fail.
+	breaker, _ := circuitbreaker.NewBreaker(cfg.CircuitBreaker)
+
+	monitor := stats.NewMonitor()
+	monitor.SetPercentiles(cfg.Percentiles)
+
+	return &Runner{
+		config:    cfg,
+		monitor:   monitor,
+		engine:    attacker.NewEngine(),
+		breaker:   breaker,
+		results:   make(chan models.Result, 10000),
+		observers: observers,
+	}
+}
+
+// Config returns the effective configuration of the run.
+func (r *Runner) Config() models.Config {
+	return r.config
+}
+
+// Monitor returns the live metrics collector.
+func (r *Runner) Monitor() *stats.Monitor {
+	return r.monitor
+}
+
+// InFlight returns the number of requests currently being executed.
+func (r *Runner) InFlight() int64 {
+	return r.engine.InFlight()
+}
+
+// TargetRate returns the current target rate in requests per second.
+func (r *Runner) TargetRate() float64 {
+	return r.engine.TargetRate()
+}
+
+// SetTracer records a span per scenario iteration and per step, and propagates
+// the step spans to the target. It must be called before the engine starts,
+// e.g. from Observer.Attach.
+func (r *Runner) SetTracer(t trace.Tracer) {
+	r.engine.SetTracer(t)
+}
+
+// CircuitOpen reports whether the circuit breaker has stopped the run.
+func (r *Runner) CircuitOpen() bool {
+	return r.breaker.IsTripped()
+}
+
+// Run executes the test and blocks until the engine has stopped and every buffered
+// result has been recorded. Cancelling parent (e.g. on SIGINT/SIGTERM) ends the run
+// early; the run is then marked as interrupted.
+func (r *Runner) Run(parent context.Context) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+	go r.stopAfterDuration(ctx, cancel)
+
+	// The raw results file is optional: if it cannot be created the test still
+	// runs and the error is reported alongside the results.
+	var sink *results.Writer
+	if path := r.config.Output.Results; path != "" {
+		w, err := results.Create(path, r.config.Output.ResultsFormat)
+		if err != nil {
+			r.resultsLog = &models.ResultsLog{Path: path, Format: r.config.Output.ResultsFormat, Error: err.Error()}
+		} else {
+			sink = w
+		}
+	}
+
+	var recorders []ResultObserver
+	for _, o := range r.observers {
+		o.Attach(r)
+		if ro, ok := o.(ResultObserver); ok {
+			recorders = append(recorders, ro)
+		}
+	}
+
+	start := time.Now()
+	go r.engine.Attack(ctx, r.config, r.results)
+
+	// Attack closes the channel once all workers have exited, so ranging over it
+	// drains every result before Run returns.
+	for res := range r.results {
+		isSuccess := r.config.SuccessCodes[res.Status] && res.Error == nil
+		r.monitor.Add(res, isSuccess)
+		if sink != nil {
+			sink.Write(res, isSuccess)
+		}
+		for _, ro := range recorders {
+			ro.Record(res, isSuccess)
+		}
+		// A tripped breaker stops the engine; the remaining results are still drained.
+		// The Monitor's failure count already includes assertion failures.
+		if r.breaker != nil && !r.breaker.IsTripped() {
+			total, failures, _ := r.monitor.GetStats()
+			if r.breaker.Check(total, failures, 0) {
+				cancel()
+			}
+		}
+	}
+
+	r.elapsed = time.Since(start)
+	r.interrupted = parent.Err() != nil
+
+	if sink != nil {
+		err := sink.Close()
+		r.resultsLog = sink.Summary()
+		if err != nil {
+			r.resultsLog.Error = err.Error()
+		}
+	}
+}
+
+// stopAfterDuration cancels the run once the test time reaches the configured
+// duration. The test time stops while the run is paused and jumps forward when a
+// stage is skipped, so the deadline is re-evaluated whenever it changes.
+func (r *Runner) stopAfterDuration(ctx context.Context, cancel context.CancelFunc) {
+	for {
+		remaining := r.config.Duration - r.engine.Elapsed()
+		if remaining <= 0 {
+			cancel()
+			return
+		}
+		timer := time.NewTimer(remaining)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-r.engine.ClockChanged():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// Snapshot returns the current metrics with the run metadata filled in.
+// It is safe to call from several goroutines.
+func (r *Runner) Snapshot() models.Report {
+	r.snapMu.Lock()
+	rep := r.monitor.Snapshot()
+	r.snapMu.Unlock()
+
+	rep.TargetURL = r.config.URL
+	rep.Method = r.config.Method
+	rep.Duration = r.config.Duration
+	rep.Concurrency = r.config.Concurrency
+	return rep
+}
+
+// FinalReport returns the report after Run has returned, with the actual elapsed
+// time, the interrupted marker and evaluated thresholds.
+func (r *Runner) FinalReport() models.Report {
+	rep := r.Snapshot()
+	rep.Elapsed = r.elapsed
+	rep.Interrupted = r.interrupted
+	rep.ResultsLog = r.resultsLog
+	if r.breaker.IsTripped() {
+		rep.CircuitBroken = true
+		rep.CircuitBreakReason = r.breaker.Reason()
+	}
+	if len(r.config.Thresholds) > 0 {
+		rep.Thresholds = threshold.Evaluate(rep, r.config.Thresholds)
+	}
+	return rep
+}
+
+// Elapsed returns the test time, which excludes pauses and includes skipped
+// stages. The wall-clock time of a finished run is in FinalReport.
+func (r *Runner) Elapsed() time.Duration {
+	return r.engine.Elapsed()
+}
+
+// Paused reports whether the run is paused.
+func (r *Runner) Paused() bool {
+	return r.engine.Paused()
+}
+
+// Stage returns the index of the running stage and the number of stages.
+func (r *Runner) Stage() (int, int) {
+	return r.engine.Stage()
+}
+
+// RateStep is the amount the rate controls change the target rate by: a tenth
+// of the configured rate or of the highest stage target, at least 1 req/s.
+func (r *Runner) RateStep() float64 {
+	peak := r.config.Rate
+	for _, s := range r.config.Stages {
+		peak = max(peak, s.Target)
+	}
+	return max(math.Round(float64(peak)/10), 1)
+}
+
+// Pause stops sending new requests until Resume. Each control action is
+// annotated in the time series of the report.
+func (r *Runner) Pause() error {
+	if err := r.engine.Pause(); err != nil {
+		return err
+	}
+	r.monitor.Annotate("pause", "paused")
+	return nil
+}
+
+// Resume continues a paused run.
+func (r *Runner) Resume() error {
+	if err := r.engine.Resume(); err != nil {
+		return err
+	}
+	r.monitor.Annotate("resume", "resumed")
+	return nil
+}
+
+// AdjustRate changes the target rate by delta requests per second and returns
+// the new target rate.
+func (r *Runner) AdjustRate(delta float64) (float64, error) {
+	target, err := r.engine.AdjustRate(delta)
+	if err != nil {
+		return 0, err
+	}
+	r.monitor.Annotate("rate", fmt.Sprintf("rate %+g → %g req/s", delta, math.Round(target)))
+	return target, nil
+}
+
+// SkipStage ends the running stage and returns the index of the next one;
+// skipping the last stage ends the run.
+func (r *Runner) SkipStage() (int, error) {
+	next, err := r.engine.SkipStage()
+	if err != nil {
+		return 0, err
+	}
+	_, count := r.engine.Stage()
+	label := fmt.Sprintf("skipped to stage %d/%d", next+1, count)
+	if next == count {
+		label = "skipped the last stage"
+	}
+	r.monitor.Annotate("skip_stage", label)
+	return next, nil
+}
@@ -0,0 +1,187 @@
+package stats
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/Amr-9/sayl/pkg/models"
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// archiveTiers controls how the time series history older than the ring is kept.
+// A bucket leaving the ring is folded into a tier 0 point; when a tier holds more
+// than max points, its oldest point is merged into the next, coarser tier. Widths
+// are in ring buckets and each divides the next, so merged points stay aligned.
+// The last tier is unbounded: a 24-hour run keeps about 330 points in total.
+var archiveTiers = []struct {
+	width int
+	max   int // 0 = unbounded
+}{
+	{width: 10, max: 90},  // 10s points for the 15 minutes before the ring
+	{width: 60, max: 105}, // 1m points for the next 1h45m
+	{width: 600, max: 0},  // 10m points beyond that
+}
+
+// archiveBucket aggregates several ring buckets. Its histograms use two
+// significant digits, like stepBucket, which keeps long runs small.
+type archiveBucket struct {
+	start, end   int // Ring buckets covered: [start, end)
+	requests     int64
+	success      int64
+	fail         int64
+	totalLatency int64 // microseconds
+	statusCodes  map[int]int64
+	hist         *hdrhistogram.Histogram
+	bins         []int64
+	steps        map[string]*stepBucket
+
+	point *models.SecondStats // Cached summary, nil after the bucket changed
+}
+
+func newArchiveBucket(start int) *archiveBucket {
+	return &archiveBucket{
+		start:       start,
+		end:         start,
+		statusCodes: make(map[int]int64),
+		hist:        hdrhistogram.New(1, 30000000, 2),
+		bins:        newLatencyBins(),
+		steps:       make(map[string]*stepBucket),
+	}
+}
+
+// merge adds the contents of o, which must directly follow a.
+func (a *archiveBucket) merge(o *archiveBucket) {
+	a.end = o.end
+	a.requests += o.requests
+	a.success += o.success
+	a.fail += o.fail
+	a.totalLatency += o.totalLatency
+	for code, n := range o.statusCodes {
+		a.statusCodes[code] += n
+	}
+	a.hist.Merge(o.hist)
+	for i, n := range o.bins {
+		a.bins[i] += n
+	}
+	for name, sb := range o.steps {
+		a.step(name).add(sb)
+	}
+	a.point = nil
+}
+
+func (a *archiveBucket) step(name string) *stepBucket {
+	sb, ok := a.steps[name]
+	if !ok {
+		sb = &stepBucket{hist: hdrhistogram.New(1, 30000000, 2)}
+		a.steps[name] = sb
+	}
+	return sb
+}
+
+func (sb *stepBucket) add(o *stepBucket) {
+	sb.requests += o.requests
+	sb.fail += o.fail
+	sb.hist.Merge(o.hist)
+}
+
+// summary returns the time series point of the bucket, computing it only after
+// the bucket changed.
+func (a *archiveBucket) summary(bucketSeconds int, ps []float64) models.SecondStats {
+	if a.point != nil {
+		return *a.point
+	}
+	us := func(v int64) time.Duration { return time.Duration(v) * time.Microsecond }
+	avgLatency := 0.0
+	if a.requests > 0 {
+		avgLatency = float64(a.totalLatency) / float64(a.requests) / 1000.0
+	}
+	statusCodes := make(map[string]int, len(a.statusCodes))
+	for code, n := range a.statusCodes {
+		statusCodes[statusKey(code)] = int(n)
+	}
+	a.point = &models.SecondStats{
+		Second:      a.end * bucketSeconds,
+		Seconds:     (a.end - a.start) * bucketSeconds,
+		Requests:    a.requests,
+		Success:     a.success,
+		Failures:    a.fail,
+		AvgLatency:  avgLatency,
+		P50:         us(a.hist.ValueAtQuantile(50)),
+		P75:         us(a.hist.ValueAtQuantile(75)),
+		P90:         us(a.hist.ValueAtQuantile(90)),
+		P95:         us(a.hist.ValueAtQuantile(95)),
+		P99:         us(a.hist.ValueAtQuantile(99)),
+		StatusCodes: statusCodes,
+		Percentiles: percentiles(a.hist, ps),
+		Histogram:   binCounts(a.bins),
+		Steps:       summarizeSteps(a.steps),
+	}
+	return *a.point
+}
+
+// fold merges the ring bucket of absolute index abs into the history. It is
+// called with bucketMu held, once the bucket is too old to receive results.
+func (m *Monitor) fold(abs int) {
+	b := m.bucketRing[abs%m.bucketRingCap]
+	folded := newArchiveBucket(abs)
+	folded.end = abs + 1
+	folded.requests = atomic.LoadInt64(&b.requests)
+	folded.success = atomic.LoadInt64(&b.success)
+	folded.fail = atomic.LoadInt64(&b.fail)
+	folded.totalLatency = atomic.LoadInt64(&b.totalLatency)
+	b.statusCodes.Range(func(key, value interface{}) bool {
+		folded.statusCodes[key.(int)] = value.(*atomic.Int64).Load()
+		return true
+	})
+	b.histMu.Lock()
+	folded.hist.Merge(b.cumulative)
+	folded.hist.Merge(b.histograms[0])
+	folded.hist.Merge(b.histograms[1])
+	b.histMu.Unlock()
+	for i := range b.bins {
+		folded.bins[i] = atomic.LoadInt64(&b.bins[i])
+	}
+	b.stepMu.Lock()
+	for name, sb := range b.steps {
+		if sb.requests > 0 {
+			folded.step(name).add(sb)
+		}
+	}
+	b.stepMu.Unlock()
+
+	m.historyMu.Lock()
+	defer m.historyMu.Unlock()
+	m.pushArchive(0, folded)
+}
+
+// pushArchive merges a into tier t, cascading the oldest point of a full tier
+// into the next one. It must be called with historyMu held.
+func (m *Monitor) pushArchive(t int, a *archiveBucket) {
+	tier := &m.history[t]
+	width := archiveTiers[t].width
+	if n := len(*tier); n > 0 && (*tier)[n-1].start/width == a.start/width {
+		(*tier)[n-1].merge(a)
+	} else {
+		bucket := newArchiveBucket(a.start)
+		bucket.merge(a)
+		*tier = append(*tier, bucket)
+	}
+
+	if limit := archiveTiers[t].max; limit > 0 && len(*tier) > limit {
+		oldest := (*tier)[0]
+		(*tier)[0] = nil
+		*tier = (*tier)[1:]
+		m.pushArchive(t+1, oldest)
+	}
+}
+
+// archiveSnapshot appends the archived points, oldest first, to dst. It must be
+// called with historyMu held.
+func (m *Monitor) archiveSnapshot(dst []models.SecondStats, bucketSeconds int) []models.SecondStats {
+	for t := len(m.history) - 1; t >= 0; t-- {
+		for _, a := range m.history[t] {
+			dst = append(dst, a.summary(bucketSeconds, m.percentiles))
+		}
+	}
+	return dst
+}
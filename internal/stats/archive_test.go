@@ -0,0 +1,162 @@
+package stats
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+var archiveStart = time.Date(2026, 3, 14, 15, 9, 26, 0, time.UTC)
+
+// perSecond is the number of synthetic requests completed in second s. Every
+// fourth request of a second fails, so success and failures are checked separately.
+func perSecond(s int) int { return 1 + s%5 }
+
+// feed adds the requests of seconds [from, to) to m, half a second into each.
+func feed(m *Monitor, clock *time.Time, from, to int) {
+	for s := from; s < to; s++ {
+		*clock = archiveStart.Add(time.Duration(s)*time.Second + 500*time.Millisecond)
+		for i := 0; i < perSecond(s); i++ {
+			status := 200
+			if i%4 == 3 {
+				status = 500
+			}
+			step := "browse"
+			if i%2 == 1 {
+				step = "buy"
+			}
+			m.Add(models.Result{
+				Timestamp: *clock,
+				Latency:   time.Duration(1+i) * time.Millisecond,
+				Status:    status,
+				StepName:  step,
+			}, status == 200)
+		}
+	}
+}
+
+// checkSeries checks that the time series covers seconds [0, elapsed) exactly
+// once and that every point holds the requests of the seconds it covers.
+func checkSeries(t *testing.T, series []models.SecondStats, elapsed int) {
+	t.Helper()
+	covered := 0
+	var total int64
+	for i, p := range series {
+		width := p.Seconds
+		if width == 0 {
+			width = 1
+		}
+		if p.Second-width != covered {
+			t.Fatalf("point %d covers (%d, %d], want it to start at %d", i, p.Second-width, p.Second, covered)
+		}
+
+		var want, wantFail int64
+		for s := covered; s < p.Second; s++ {
+			want += int64(perSecond(s))
+			wantFail += int64(perSecond(s) / 4)
+		}
+		if p.Requests != want || p.Failures != wantFail || p.Success != want-wantFail {
+			t.Fatalf("point %d (%d, %d]: requests %d, success %d, failures %d; want %d, %d, %d",
+				i, covered, p.Second, p.Requests, p.Success, p.Failures, want, want-wantFail, wantFail)
+		}
+
+		var codes, binned, steps int64
+		for _, n := range p.StatusCodes {
+			codes += int64(n)
+		}
+		for _, n := range p.Histogram {
+			binned += n
+		}
+		for _, st := range p.Steps {
+			steps += st.Requests
+		}
+		if codes != want || binned != want || steps != want {
+			t.Fatalf("point %d (%d, %d]: %d status codes, %d binned latencies, %d step requests; want %d",
+				i, covered, p.Second, codes, binned, steps, want)
+		}
+
+		covered = p.Second
+		total += p.Requests
+	}
+	if covered != elapsed {
+		t.Fatalf("series ends at %d, want %d", covered, elapsed)
+	}
+	var want int64
+	for s := 0; s < elapsed; s++ {
+		want += int64(perSecond(s))
+	}
+	if total != want {
+		t.Fatalf("series holds %d requests, want %d", total, want)
+	}
+}
+
+// TestArchiveTiers runs long enough to fill the ring and the first two archive
+// tiers, checking the whole series at each stage.
+func TestArchiveTiers(t *testing.T) {
+	var clock time.Time
+	m := NewMonitorAt(archiveStart, func() time.Time { return clock }, time.Second)
+
+	// The ring alone, the first fold, 10s points, 1m points and 10m points.
+	last := 0
+	for _, elapsed := range []int{bucketWindow, bucketWindow + 1, bucketWindow + 95, 1300, 7999, 9000} {
+		t.Run(fmt.Sprintf("%ds", elapsed), func(t *testing.T) {
+			feed(m, &clock, last, elapsed)
+			last = elapsed
+			// The last bucket is complete once the clock reaches its end.
+			clock = archiveStart.Add(time.Duration(elapsed) * time.Second)
+			checkSeries(t, m.Snapshot().TimeSeriesData, elapsed)
+		})
+	}
+
+	m.historyMu.Lock()
+	defer m.historyMu.Unlock()
+	for i, tier := range archiveTiers {
+		if tier.max > 0 && len(m.history[i]) != tier.max {
+			t.Errorf("tier %d holds %d points, want it full at %d", i, len(m.history[i]), tier.max)
+		}
+		for _, a := range m.history[i] {
+			if a.start%tier.width != 0 || a.end-a.start > tier.width {
+				t.Errorf("tier %d point [%d, %d) is not aligned to %d buckets", i, a.start, a.end, tier.width)
+			}
+		}
+	}
+	if len(m.history[2]) == 0 {
+		t.Error("no point reached the last tier")
+	}
+}
+
+// TestArchiveLateResult adds results whose completion time falls before the
+// ring, as replayed records that finished out of order do. They are counted in
+// the oldest bucket still in the ring instead of being lost at the handoff.
+func TestArchiveLateResult(t *testing.T) {
+	var clock time.Time
+	m := NewMonitorAt(archiveStart, func() time.Time { return clock }, time.Second)
+	feed(m, &clock, 0, 400)
+
+	for _, s := range []int{400 - bucketWindow, 10} {
+		clock = archiveStart.Add(time.Duration(s) * time.Second)
+		m.Add(models.Result{Timestamp: clock, Latency: time.Millisecond, Status: 200}, true)
+	}
+
+	clock = archiveStart.Add(400 * time.Second)
+	rep := m.Snapshot()
+	var total int64
+	for _, p := range rep.TimeSeriesData {
+		total += p.Requests
+	}
+	if total != rep.TotalRequests {
+		t.Fatalf("series holds %d requests, report %d", total, rep.TotalRequests)
+	}
+	oldest := 400 - bucketWindow + 1
+	for _, p := range rep.TimeSeriesData {
+		if p.Seconds == 0 && p.Second == oldest+1 {
+			if want := int64(perSecond(oldest) + 2); p.Requests != want {
+				t.Fatalf("oldest ring bucket holds %d requests, want %d", p.Requests, want)
+			}
+			return
+		}
+	}
+	t.Fatalf("no point for the oldest ring bucket %d", oldest)
+}
@@ -0,0 +1,209 @@
+package stats
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/Amr-9/sayl/pkg/models"
+	"golang.org/x/net/http2"
+)
+
+// Error classes. Errors are counted per class; the messages behind each class are
+// kept as samples only, so the set of keys stays small and stable across runs.
+// GraphQL errors are the exception: they are split by error code or message (see
+// graphQLClass), since which GraphQL errors occurred is the point of testing a
+// GraphQL API, and the number of those classes is capped by the Monitor.
+const (
+	ErrorDNS           = "dns"                // Host name could not be resolved
+	ErrorConnRefused   = "connection_refused" // Nothing listening on the target port
+	ErrorConnReset     = "connection_reset"   // Connection reset or closed by the server
+	ErrorTLS           = "tls_handshake"      // TLS handshake or certificate failure
+	ErrorDialTimeout   = "timeout_dial"       // Timed out while connecting
+	ErrorHeaderTimeout = "timeout_header"     // Timed out waiting for the response headers
+	ErrorBodyTimeout   = "timeout_body"       // Timed out while reading the response body
+	ErrorHTTP2Stream   = "http2_stream"       // HTTP/2 stream reset or protocol error
+	ErrorBodyRead      = "body_read"          // Response body could not be read
+	ErrorCanceled      = "canceled"           // Request canceled, usually when the run stops
+	ErrorGraphQL       = "graphql"            // GraphQL response carried an "errors" array; prefix of its classes
+	ErrorOther         = "other"
+)
+
+// maxErrorSamples is the number of distinct messages kept for each error class.
+const maxErrorSamples = 5
+
+// graphQLPrefix starts the message of every GraphQL error, see attacker.GraphQLError.
+const graphQLPrefix = "graphql error"
+
+// maxGraphQLMessage is the number of characters of a GraphQL error message kept in
+// its class.
+const maxGraphQLMessage = 80
+
+// maxGraphQLClasses caps the number of distinct GraphQL error classes in a run;
+// further GraphQL errors are counted under the plain ErrorGraphQL class.
+const maxGraphQLClasses = 20
+
+// ClassifyError returns the class of a request error. Typed errors are matched with
+// errors.As/errors.Is; errors that only survive as text (results files replayed by
+// 'sayl report') fall back to matching their message.
+func ClassifyError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var bodyErr *models.BodyReadError
+	inBody := errors.As(err, &bodyErr)
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		if dnsErr.IsTimeout {
+			return ErrorDialTimeout
+		}
+		return ErrorDNS
+	}
+	if isTLSError(err) {
+		return ErrorTLS
+	}
+	if errors.Is(err, context.Canceled) {
+		return ErrorCanceled
+	}
+	if IsTimeout(err) || errors.Is(err, context.DeadlineExceeded) {
+		var opErr *net.OpError
+		switch {
+		case inBody:
+			return ErrorBodyTimeout
+		case errors.As(err, &opErr) && opErr.Op == "dial":
+			return ErrorDialTimeout
+		default:
+			return ErrorHeaderTimeout
+		}
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return ErrorConnRefused
+	}
+	var streamErr http2.StreamError
+	var goAway http2.GoAwayError
+	if errors.As(err, &streamErr) || errors.As(err, &goAway) {
+		return ErrorHTTP2Stream
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return ErrorConnReset
+	}
+	if inBody {
+		return ErrorBodyRead
+	}
+	// The transport reports a connection closed before any response as a bare EOF.
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrorConnReset
+	}
+	return classifyMessage(err.Error())
+}
+
+func isTLSError(err error) bool {
+	var (
+		recordErr  tls.RecordHeaderError
+		alertErr   tls.AlertError
+		verifyErr  *tls.CertificateVerificationError
+		unknownCA  x509.UnknownAuthorityError
+		hostErr    x509.HostnameError
+		invalidErr x509.CertificateInvalidError
+	)
+	return errors.As(err, &recordErr) || errors.As(err, &alertErr) || errors.As(err, &verifyErr) ||
+		errors.As(err, &unknownCA) || errors.As(err, &hostErr) || errors.As(err, &invalidErr) ||
+		strings.Contains(err.Error(), "TLS handshake") || strings.Contains(err.Error(), "HTTPS client")
+}
+
+// classifyMessage maps an error message to its class, for errors whose type is
+// unknown or was lost.
+func classifyMessage(msg string) string {
+	if strings.HasPrefix(msg, graphQLPrefix) {
+		return graphQLClass(msg)
+	}
+	msg = strings.ToLower(msg)
+	body := strings.Contains(msg, models.BodyReadPrefix)
+	switch {
+	case strings.Contains(msg, "no such host"), strings.Contains(msg, "server misbehaving"):
+		return ErrorDNS
+	case strings.Contains(msg, "tls"), strings.Contains(msg, "https client"), strings.Contains(msg, "x509"), strings.Contains(msg, "certificate"):
+		return ErrorTLS
+	case strings.Contains(msg, "context canceled"), strings.Contains(msg, "operation was canceled"):
+		return ErrorCanceled
+	case strings.Contains(msg, "timeout"), strings.Contains(msg, "deadline exceeded"):
+		switch {
+		case body:
+			return ErrorBodyTimeout
+		case strings.Contains(msg, "dial"):
+			return ErrorDialTimeout
+		default:
+			return ErrorHeaderTimeout
+		}
+	case strings.Contains(msg, "connection refused"):
+		return ErrorConnRefused
+	case strings.Contains(msg, "stream error"), strings.Contains(msg, "http2:"):
+		return ErrorHTTP2Stream
+	case strings.Contains(msg, "connection reset"), strings.Contains(msg, "broken pipe"):
+		return ErrorConnReset
+	case body:
+		return ErrorBodyRead
+	case strings.HasSuffix(msg, "eof"):
+		return ErrorConnReset
+	}
+	return ErrorOther
+}
+
+// graphQLClass returns the class of a GraphQL error message, which reads
+// "graphql error [CODE]: message" or "graphql error: message". The class is
+// "graphql:CODE" when the server sent an extensions.code, and otherwise "graphql:"
+// followed by the message, with IPs and ports masked and cut to maxGraphQLMessage.
+func graphQLClass(msg string) string {
+	rest := msg[len(graphQLPrefix):]
+	if strings.HasPrefix(rest, " [") {
+		if end := strings.Index(rest, "]:"); end > 2 {
+			return ErrorGraphQL + ":" + rest[2:end]
+		}
+	}
+	rest = strings.TrimSpace(strings.TrimPrefix(rest, ":"))
+	if rest == "" {
+		return ErrorGraphQL
+	}
+	rest = sanitizeError(rest)
+	if runes := []rune(rest); len(runes) > maxGraphQLMessage {
+		rest = string(runes[:maxGraphQLMessage]) + "…"
+	}
+	return ErrorGraphQL + ":" + rest
+}
+
+// errorSamples keeps the first distinct messages seen for one error class.
+type errorSamples struct {
+	mu   sync.Mutex
+	msgs []string
+}
+
+// add records msg unless it is already known or the sample set is full. The
+// message is only sanitized while there is room, keeping the regex off the hot path.
+func (s *errorSamples) add(msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.msgs) >= maxErrorSamples {
+		return
+	}
+	msg = sanitizeError(msg)
+	for _, m := range s.msgs {
+		if m == msg {
+			return
+		}
+	}
+	s.msgs = append(s.msgs, msg)
+}
+
+func (s *errorSamples) snapshot() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.msgs...)
+}
@@ -0,0 +1,127 @@
+package stats
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Amr-9/sayl/pkg/models"
+	"golang.org/x/net/http2"
+)
+
+// timeoutError is a net.Error that timed out, like the transport's.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// urlErr wraps err the way http.Client does.
+func urlErr(err error) error {
+	return &url.Error{Op: "Get", URL: "http://127.0.0.1:8080/orders", Err: err}
+}
+
+func opErr(op string, err error) error {
+	return &net.OpError{Op: op, Net: "tcp", Err: err}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"nil", nil, ""},
+		{"dns", urlErr(opErr("dial", &net.DNSError{Err: "no such host", Name: "api.invalid"})), ErrorDNS},
+		{"dns timeout", urlErr(opErr("dial", &net.DNSError{Err: "i/o timeout", Name: "api", IsTimeout: true})), ErrorDialTimeout},
+		{"connection refused", urlErr(opErr("dial", os.NewSyscallError("connect", syscall.ECONNREFUSED))), ErrorConnRefused},
+		{"connection reset", urlErr(opErr("read", os.NewSyscallError("read", syscall.ECONNRESET))), ErrorConnReset},
+		{"broken pipe", urlErr(opErr("write", os.NewSyscallError("write", syscall.EPIPE))), ErrorConnReset},
+		{"bare eof", urlErr(io.EOF), ErrorConnReset},
+		{"unknown authority", urlErr(x509.UnknownAuthorityError{}), ErrorTLS},
+		{"dial timeout", urlErr(opErr("dial", timeoutError{})), ErrorDialTimeout},
+		{"header timeout", urlErr(fmt.Errorf("%w (Client.Timeout exceeded while awaiting headers)", context.DeadlineExceeded)), ErrorHeaderTimeout},
+		{"body timeout", &models.BodyReadError{Err: opErr("read", timeoutError{})}, ErrorBodyTimeout},
+		{"body cut short", &models.BodyReadError{Err: io.ErrUnexpectedEOF}, ErrorBodyRead},
+		{"canceled", urlErr(context.Canceled), ErrorCanceled},
+		{"http2 stream", urlErr(http2.StreamError{StreamID: 3, Code: http2.ErrCodeRefusedStream}), ErrorHTTP2Stream},
+		{"http2 goaway", urlErr(http2.GoAwayError{ErrCode: http2.ErrCodeEnhanceYourCalm}), ErrorHTTP2Stream},
+		{"unknown", errors.New("something odd happened"), ErrorOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyError(tt.err); got != tt.want {
+				t.Fatalf("ClassifyError(%v) = %q, want %q", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestClassifyMessage covers errors replayed from results files, which only keep
+// their message.
+func TestClassifyMessage(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{`Get "http://api.invalid/": dial tcp: lookup api.invalid: no such host`, ErrorDNS},
+		{`Get "https://[IP]:[PORT]/": tls: failed to verify certificate: x509: certificate signed by unknown authority`, ErrorTLS},
+		{`Get "http://[IP]:[PORT]/": dial tcp [IP]:[PORT]: connect: connection refused`, ErrorConnRefused},
+		{`Get "http://[IP]:[PORT]/": dial tcp [IP]:[PORT]: i/o timeout`, ErrorDialTimeout},
+		{`Get "http://[IP]:[PORT]/": context deadline exceeded (Client.Timeout exceeded while awaiting headers)`, ErrorHeaderTimeout},
+		{`reading response body: context deadline exceeded (Client.Timeout or context cancellation while reading body)`, ErrorBodyTimeout},
+		{`reading response body: unexpected EOF`, ErrorBodyRead},
+		{`Post "http://[IP]:[PORT]/": context canceled`, ErrorCanceled},
+		{`Get "http://[IP]:[PORT]/": stream error: stream ID 3; REFUSED_STREAM`, ErrorHTTP2Stream},
+		{`Get "http://[IP]:[PORT]/": read tcp [CONN_TUPLE]: read: connection reset by peer`, ErrorConnReset},
+		{`Get "http://[IP]:[PORT]/": EOF`, ErrorConnReset},
+		{`teapot`, ErrorOther},
+		{`graphql error [UNAUTHENTICATED]: Not logged in`, "graphql:UNAUTHENTICATED"},
+		{`graphql error [INTERNAL_SERVER_ERROR]: connection to 10.0.0.7:5432 lost`, "graphql:INTERNAL_SERVER_ERROR"},
+		{`graphql error: Cannot query field "nme" on type "User".`, `graphql:Cannot query field "nme" on type "User".`},
+		{`graphql error: upstream 10.0.0.7:5432 timed out`, "graphql:upstream [IP]:[PORT] timed out"},
+		{`graphql error: [not a code] Field is required`, "graphql:[not a code] Field is required"},
+		{`graphql error: ` + strings.Repeat("é", 100), "graphql:" + strings.Repeat("é", maxGraphQLMessage) + "…"},
+		{`graphql error:`, ErrorGraphQL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.msg, func(t *testing.T) {
+			if got := ClassifyError(errors.New(tt.msg)); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGraphQLClassesAreCapped(t *testing.T) {
+	m := NewMonitor()
+	add := func(msg string) {
+		m.Add(models.Result{Timestamp: time.Now(), Status: 200, Latency: time.Millisecond, Error: errors.New(msg)}, false)
+	}
+	for i := 0; i < maxGraphQLClasses+5; i++ {
+		add(fmt.Sprintf("graphql error [CODE_%d]: failed", i))
+	}
+	add("graphql error [CODE_0]: failed again")
+
+	errs := m.Snapshot().Errors
+	if got := errs["graphql:CODE_0"]; got != 2 {
+		t.Fatalf("graphql:CODE_0 counted %d times, want 2", got)
+	}
+	if got := errs[ErrorGraphQL]; got != 5 {
+		t.Fatalf("%d errors past the cap, want 5", got)
+	}
+	if len(errs) != maxGraphQLClasses+1 {
+		t.Fatalf("%d error classes, want %d", len(errs), maxGraphQLClasses+1)
+	}
+}
@@ -0,0 +1,48 @@
+package stats
+
+import (
+	"sort"
+	"sync/atomic"
+
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// latencyBoundsUs are models.LatencyBounds in microseconds, the unit of the
+// recorded latencies.
+var latencyBoundsUs = func() []int64 {
+	us := make([]int64, len(models.LatencyBounds))
+	for i, b := range models.LatencyBounds {
+		us[i] = b.Microseconds()
+	}
+	return us
+}()
+
+// latencyBin returns the index of the histogram bin holding latencyUs. The bins
+// are counted next to the HDR histograms, which cannot be read per range without
+// walking every one of their buckets on each Snapshot.
+func latencyBin(latencyUs int64) int {
+	i := sort.Search(len(latencyBoundsUs), func(i int) bool { return latencyBoundsUs[i] >= latencyUs })
+	return min(i, len(latencyBoundsUs)-1)
+}
+
+// newLatencyBins allocates one counter per histogram bin.
+func newLatencyBins() []int64 {
+	return make([]int64, len(latencyBoundsUs))
+}
+
+// binCounts copies the bin counters, with trailing empty bins trimmed. It returns
+// nil when every bin is empty.
+func binCounts(bins []int64) []int64 {
+	n := len(bins)
+	for n > 0 && atomic.LoadInt64(&bins[n-1]) == 0 {
+		n--
+	}
+	if n == 0 {
+		return nil
+	}
+	out := make([]int64, n)
+	for i := range out {
+		out[i] = atomic.LoadInt64(&bins[i])
+	}
+	return out
+}
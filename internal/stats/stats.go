@@ -1,9 +1,11 @@
 package stats
 
 import (
-	"fmt"
+	"errors"
 	"net"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -12,12 +14,14 @@ import (
 	"github.com/HdrHistogram/hdrhistogram-go"
 )
 
-// isTimeout checks if the error is a timeout
-func isTimeout(err error) bool {
+// IsTimeout checks if the error is a timeout
+func IsTimeout(err error) bool {
 	if err == nil {
 		return false
 	}
-	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+	// Wrapped too, e.g. a body read timeout inside a models.BodyReadError.
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
 		return true
 	}
 	if os.IsTimeout(err) {
@@ -57,9 +61,36 @@ type secondBucket struct {
 	activeHist atomic.Int32
 	histMu     sync.Mutex
 	cumulative *hdrhistogram.Histogram
+	bins       []int64 // Latency histogram bins, updated atomically
+
+	// Per-step counters and latencies of this second, keyed by step name. Entries
+	// are reset rather than deleted when the slot is recycled, so steps seen in
+	// earlier seconds do not allocate again. Guarded by stepMu.
+	stepMu sync.Mutex
+	steps  map[string]*stepBucket
+}
+
+// stepBucket holds the metrics of one step within a second. Its histogram uses
+// two significant digits, which is plenty for a chart and keeps the ring small.
+type stepBucket struct {
+	requests int64
+	fail     int64
+	hist     *hdrhistogram.Histogram
+}
+
+// stepStats holds the counters and latency histogram of a single scenario step.
+type stepStats struct {
+	requests          int64
+	success           int64
+	fail              int64
+	assertionFailures int64
+	assertionErrors   map[string]int
+	statusCodes       map[int]int
+	errors            map[string]int // by error class
+	hist              *hdrhistogram.Histogram
 }
 
-// maxErrorBuckets caps the number of unique error messages tracked to prevent
+// maxErrorBuckets caps the number of unique assertion messages tracked to prevent
 // unbounded memory growth during long tests against misconfigured servers.
 const maxErrorBuckets = 100
 
@@ -73,12 +104,16 @@ type Monitor struct {
 
 	// sync.Map values are *atomic.Int64 for true atomic increments.
 	statusCodes     sync.Map // map[int]*atomic.Int64
-	errors          sync.Map // map[string]*atomic.Int64
-	uniqueErrorCount atomic.Int64 // number of distinct keys in errors map
+	errors          sync.Map // map[string]*atomic.Int64, keyed by error class
+	errorSamples    sync.Map // map[string]*errorSamples, keyed by error class
 	assertionErrors sync.Map // map[string]*atomic.Int64
 	protocolCounts  sync.Map // map[string]*atomic.Int64
 
-	totalBytes int64
+	graphQLMu      sync.Mutex
+	graphQLClasses map[string]bool // GraphQL error classes admitted so far, see graphQLClass
+
+	totalBytes     int64 // decoded response bytes
+	totalWireBytes int64 // response bytes as received on the wire
 
 	// Double-buffered global histogram.
 	// Add() records into histograms[activeHist] under histMu.
@@ -88,8 +123,30 @@ type Monitor struct {
 	activeHist atomic.Int32
 	histMu     sync.Mutex
 	cumulative *hdrhistogram.Histogram
+	bins       []int64 // Latency histogram bins (models.LatencyBounds), updated atomically
+
+	// SSE stream metrics. Guarded by streamMu; only streamed results touch these.
+	streamMu     sync.Mutex
+	streams      int64
+	streamEvents int64
+	emptyStreams int64
+	ttfeHist     *hdrhistogram.Histogram
+	eventGapHist *hdrhistogram.Histogram
+
+	// Per-step metrics, keyed by step name. Guarded by stepMu; stepOrder keeps
+	// steps in the order they were first seen, which follows the scenario order.
+	stepMu    sync.Mutex
+	steps     map[string]*stepStats
+	stepOrder []string
+
+	percentiles []float64 // Latency percentiles read from the histograms
+
+	annotationMu sync.Mutex
+	annotations  []models.Annotation // Manual control actions, see Annotate
 
-	startTime time.Time
+	startTime  time.Time
+	now        func() time.Time // wall clock, or the record time when replaying
+	resolution time.Duration    // width of a time series bucket (whole seconds)
 
 	// Ring buffer for per-second buckets. Caps memory at O(bucketWindow) instead
 	// of O(elapsed_seconds). A 1-hour test at ~150KB/bucket would otherwise use ~540MB.
@@ -98,6 +155,12 @@ type Monitor struct {
 	bucketTotal   int // total seconds elapsed since startTime
 	bucketMu      sync.Mutex
 
+	// Downsampled history of the buckets that left the ring, one slice per
+	// archiveTiers entry, so the time series covers the whole run. Guarded by
+	// historyMu; when both are needed, bucketMu is taken first.
+	historyMu sync.Mutex
+	history   [][]*archiveBucket
+
 	// Pre-allocated snapshot buffers — reused on every Snapshot() call to
 	// eliminate repeated heap allocations (previously 5 fresh maps every 100ms).
 	snapStatusMap    map[string]int
@@ -107,9 +170,23 @@ type Monitor struct {
 	snapTimeSeries   []models.SecondStats
 }
 
-const bucketWindow = 300 // keep the last 300 seconds of per-second data
+const bucketWindow = 300 // keep the last 300 seconds of per-second data; older seconds are downsampled
 
+// NewMonitor creates a Monitor for a live run, with one-second time series buckets.
 func NewMonitor() *Monitor {
+	return NewMonitorAt(time.Now(), time.Now, time.Second)
+}
+
+// NewMonitorAt creates a Monitor whose clock is now instead of the wall clock and
+// whose time series buckets are resolution wide (rounded to whole seconds). It is
+// used to replay recorded results: now returns the time of the record being added
+// and, before the final Snapshot, the end of the replayed window.
+func NewMonitorAt(start time.Time, now func() time.Time, resolution time.Duration) *Monitor {
+	resolution = resolution.Round(time.Second)
+	if resolution < time.Second {
+		resolution = time.Second
+	}
+
 	// Pre-allocate all ring slots so getOrCreateBucket never allocates in the hot path.
 	ring := make([]*secondBucket, bucketWindow)
 	for i := range ring {
@@ -119,17 +196,27 @@ func NewMonitor() *Monitor {
 				hdrhistogram.New(1, 30000000, 3),
 			},
 			cumulative: hdrhistogram.New(1, 30000000, 3),
+			bins:       newLatencyBins(),
+			steps:      make(map[string]*stepBucket),
 		}
 	}
 	return &Monitor{
-		startTime: time.Now(),
+		percentiles: models.DefaultPercentiles,
+		startTime:   start,
+		now:         now,
+		resolution:  resolution,
 		histograms: [2]*hdrhistogram.Histogram{
 			hdrhistogram.New(1, 30000000, 3),
 			hdrhistogram.New(1, 30000000, 3),
 		},
 		cumulative:    hdrhistogram.New(1, 30000000, 3),
+		bins:          newLatencyBins(),
+		ttfeHist:      hdrhistogram.New(1, 30000000, 3),
+		eventGapHist:  hdrhistogram.New(1, 30000000, 3),
+		steps:         make(map[string]*stepStats),
 		bucketRing:    ring,
 		bucketRingCap: bucketWindow,
+		history:       make([][]*archiveBucket, len(archiveTiers)),
 		// Pre-allocate with reasonable initial capacities.
 		snapStatusMap:    make(map[string]int, 8),
 		snapErrorMap:     make(map[string]int, 16),
@@ -139,6 +226,14 @@ func NewMonitor() *Monitor {
 	}
 }
 
+// SetPercentiles selects the latency percentiles reported in addition to the
+// fixed P50-P99 fields. It must be called before the first Snapshot.
+func (m *Monitor) SetPercentiles(ps []float64) {
+	if len(ps) > 0 {
+		m.percentiles = ps
+	}
+}
+
 func (m *Monitor) getOrCreateBucket(second int) *secondBucket {
 	m.bucketMu.Lock()
 	defer m.bucketMu.Unlock()
@@ -161,7 +256,28 @@ func (m *Monitor) getOrCreateBucket(second int) *secondBucket {
 		b.cumulative.Reset()
 		b.activeHist.Store(0)
 		b.histMu.Unlock()
+		for i := range b.bins {
+			atomic.StoreInt64(&b.bins[i], 0)
+		}
+		b.stepMu.Lock()
+		for _, sb := range b.steps {
+			sb.requests, sb.fail = 0, 0
+			sb.hist.Reset()
+		}
+		b.stepMu.Unlock()
 		m.bucketTotal++
+
+		// The oldest bucket still in the ring is skipped by Snapshot, so it goes
+		// into the history now: the two together then cover the run without a gap.
+		if oldest := m.bucketTotal - m.bucketRingCap; oldest >= 0 {
+			m.fold(oldest)
+		}
+	}
+	// A result completing before the ring (replayed records are not strictly in
+	// order) would land in a folded or recycled slot, so count it in the oldest
+	// bucket Snapshot still reports.
+	if oldest := m.bucketTotal - m.bucketRingCap + 1; second < oldest {
+		second = oldest
 	}
 	return m.bucketRing[second%m.bucketRingCap]
 }
@@ -170,6 +286,7 @@ func (m *Monitor) getOrCreateBucket(second int) *secondBucket {
 func (m *Monitor) Add(res models.Result, isSuccess bool) {
 	atomic.AddInt64(&m.requests, 1)
 	atomic.AddInt64(&m.totalBytes, res.Bytes)
+	atomic.AddInt64(&m.totalWireBytes, res.WireBytes)
 
 	hasAssertionError := res.AssertionError != nil
 	if hasAssertionError {
@@ -185,49 +302,58 @@ func (m *Monitor) Add(res models.Result, isSuccess bool) {
 
 	// Classify transport timeouts as status 1 for grouping.
 	if res.Status == 0 && res.Error != nil {
-		if isTimeout(res.Error) {
+		if IsTimeout(res.Error) {
 			res.Status = 1
 		}
 	}
 
 	syncMapInc(&m.statusCodes, res.Status)
 
-	if res.Error != nil {
-		sanitized := sanitizeError(res.Error.Error())
-		if _, loaded := m.errors.Load(sanitized); loaded {
-			// Fast path: key already exists, just increment.
-			syncMapInc(&m.errors, sanitized)
-		} else if m.uniqueErrorCount.Load() < maxErrorBuckets {
-			// New key under cap — insert atomically.
-			newVal := &atomic.Int64{}
-			newVal.Store(1)
-			if _, existed := m.errors.LoadOrStore(sanitized, newVal); existed {
-				// Another goroutine beat us; increment the existing counter.
-				syncMapInc(&m.errors, sanitized)
-			} else {
-				m.uniqueErrorCount.Add(1)
-			}
-		} else {
-			// Over cap — fold into "other" bucket to bound memory.
-			syncMapInc(&m.errors, "other")
+	// Errors are counted by class, which bounds the map; the messages behind each
+	// class are kept as samples.
+	errClass := ClassifyError(res.Error)
+	if strings.HasPrefix(errClass, ErrorGraphQL+":") {
+		errClass = m.graphQLClass(errClass)
+	}
+	if errClass != "" {
+		syncMapInc(&m.errors, errClass)
+		samples, ok := m.errorSamples.Load(errClass)
+		if !ok {
+			samples, _ = m.errorSamples.LoadOrStore(errClass, &errorSamples{})
 		}
+		samples.(*errorSamples).add(res.Error.Error())
 	}
 
 	if res.Protocol != "" {
 		syncMapInc(&m.protocolCounts, res.Protocol)
 	}
 
+	if res.Streamed {
+		m.addStream(res)
+	}
+
 	latencyUs := res.Latency.Microseconds()
 
-	// Record latency only for requests that received a response.
-	if res.Error == nil {
+	// Record latency only for requests that received a response (GraphQL and
+	// mid-stream errors carry a real status code and still count).
+	hasResponse := res.Error == nil || res.Status >= 100
+
+	if res.StepName != "" {
+		m.addStep(res, isSuccess && !hasAssertionError, hasResponse, latencyUs, errClass)
+	}
+	bin := latencyBin(latencyUs)
+	if hasResponse {
 		m.histMu.Lock()
 		_ = m.histograms[m.activeHist.Load()].RecordValue(latencyUs)
 		m.histMu.Unlock()
+		atomic.AddInt64(&m.bins[bin], 1)
 	}
 
 	// Per-second tracking.
-	second := int(time.Since(m.startTime).Seconds())
+	second := int(m.now().Sub(m.startTime) / m.resolution)
+	if second < 0 {
+		second = 0
+	}
 	bucket := m.getOrCreateBucket(second)
 
 	atomic.AddInt64(&bucket.requests, 1)
@@ -241,13 +367,240 @@ func (m *Monitor) Add(res models.Result, isSuccess bool) {
 
 	syncMapInc(&bucket.statusCodes, res.Status)
 
-	if res.Error == nil {
+	if hasResponse {
 		bucket.histMu.Lock()
 		_ = bucket.histograms[bucket.activeHist.Load()].RecordValue(latencyUs)
 		bucket.histMu.Unlock()
+		atomic.AddInt64(&bucket.bins[bin], 1)
+	}
+
+	if res.StepName != "" {
+		bucket.stepMu.Lock()
+		sb, ok := bucket.steps[res.StepName]
+		if !ok {
+			sb = &stepBucket{hist: hdrhistogram.New(1, 30000000, 2)}
+			bucket.steps[res.StepName] = sb
+		}
+		sb.requests++
+		if !isSuccess || hasAssertionError {
+			sb.fail++
+		}
+		if hasResponse {
+			_ = sb.hist.RecordValue(latencyUs)
+		}
+		bucket.stepMu.Unlock()
+	}
+}
+
+// addStream records time-to-first-event and inter-event gaps for an SSE result.
+func (m *Monitor) addStream(res models.Result) {
+	m.streamMu.Lock()
+	defer m.streamMu.Unlock()
+
+	m.streams++
+	m.streamEvents += int64(res.Events)
+	if res.Events == 0 {
+		m.emptyStreams++
+		return
+	}
+	_ = m.ttfeHist.RecordValue(res.TimeToFirstEvent.Microseconds())
+	for _, gap := range res.EventGaps {
+		_ = m.eventGapHist.RecordValue(gap.Microseconds())
 	}
 }
 
+// streamSnapshot summarises stream metrics, or returns nil if no streams were recorded.
+func (m *Monitor) streamSnapshot(duration float64) *models.StreamStats {
+	m.streamMu.Lock()
+	defer m.streamMu.Unlock()
+
+	if m.streams == 0 {
+		return nil
+	}
+
+	us := func(v int64) time.Duration { return time.Duration(v) * time.Microsecond }
+	st := &models.StreamStats{
+		Streams:      m.streams,
+		Events:       m.streamEvents,
+		EmptyStreams: m.emptyStreams,
+		TTFEP50:      us(m.ttfeHist.ValueAtQuantile(50)),
+		TTFEP90:      us(m.ttfeHist.ValueAtQuantile(90)),
+		TTFEP99:      us(m.ttfeHist.ValueAtQuantile(99)),
+		TTFEMax:      us(m.ttfeHist.Max()),
+		GapP50:       us(m.eventGapHist.ValueAtQuantile(50)),
+		GapP90:       us(m.eventGapHist.ValueAtQuantile(90)),
+		GapP99:       us(m.eventGapHist.ValueAtQuantile(99)),
+		GapMax:       us(m.eventGapHist.Max()),
+	}
+	if duration > 0 {
+		st.EventsPerSec = float64(m.streamEvents) / duration
+	}
+	return st
+}
+
+// graphQLClass returns class unless maxGraphQLClasses other GraphQL error classes
+// were seen already, in which case the error is counted under ErrorGraphQL.
+func (m *Monitor) graphQLClass(class string) string {
+	m.graphQLMu.Lock()
+	defer m.graphQLMu.Unlock()
+	if m.graphQLClasses[class] {
+		return class
+	}
+	if len(m.graphQLClasses) >= maxGraphQLClasses {
+		return ErrorGraphQL
+	}
+	if m.graphQLClasses == nil {
+		m.graphQLClasses = make(map[string]bool)
+	}
+	m.graphQLClasses[class] = true
+	return class
+}
+
+// addStep records a result against its scenario step. errClass is the class of
+// the result's error ("" when there is none).
+func (m *Monitor) addStep(res models.Result, success, hasResponse bool, latencyUs int64, errClass string) {
+	m.stepMu.Lock()
+	defer m.stepMu.Unlock()
+
+	name := res.StepName
+	st, ok := m.steps[name]
+	if !ok {
+		st = &stepStats{
+			hist:            hdrhistogram.New(1, 30000000, 3),
+			assertionErrors: make(map[string]int),
+			statusCodes:     make(map[int]int),
+			errors:          make(map[string]int),
+		}
+		m.steps[name] = st
+		m.stepOrder = append(m.stepOrder, name)
+	}
+	st.statusCodes[res.Status]++
+	if errClass != "" {
+		st.errors[errClass]++
+	}
+	assertionErr := res.AssertionError
+	st.requests++
+	if success {
+		st.success++
+	} else {
+		st.fail++
+	}
+	if assertionErr != nil {
+		st.assertionFailures++
+		msg := assertionErr.Error()
+		if _, ok := st.assertionErrors[msg]; !ok && len(st.assertionErrors) >= maxErrorBuckets {
+			msg = "other"
+		}
+		st.assertionErrors[msg]++
+	}
+	if hasResponse {
+		_ = st.hist.RecordValue(latencyUs)
+	}
+}
+
+// stepSnapshot summarises per-step metrics in scenario order.
+func (m *Monitor) stepSnapshot(duration float64) []models.StepStats {
+	m.stepMu.Lock()
+	defer m.stepMu.Unlock()
+
+	if len(m.stepOrder) == 0 {
+		return nil
+	}
+
+	us := func(v int64) time.Duration { return time.Duration(v) * time.Microsecond }
+	out := make([]models.StepStats, 0, len(m.stepOrder))
+	for _, name := range m.stepOrder {
+		st := m.steps[name]
+		ss := models.StepStats{
+			Name:     name,
+			Requests: st.requests,
+			Success:  st.success,
+			Failures: st.fail,
+			P50:      us(st.hist.ValueAtQuantile(50)),
+			P75:      us(st.hist.ValueAtQuantile(75)),
+			P90:      us(st.hist.ValueAtQuantile(90)),
+			P95:      us(st.hist.ValueAtQuantile(95)),
+			P99:      us(st.hist.ValueAtQuantile(99)),
+			Min:      us(st.hist.Min()),
+			Max:      us(st.hist.Max()),
+			Mean:     fus(st.hist.Mean()),
+			StdDev:   fus(st.hist.StdDev()),
+
+			Percentiles:       percentiles(st.hist, m.percentiles),
+			AssertionFailures: st.assertionFailures,
+		}
+		if len(st.assertionErrors) > 0 {
+			ss.AssertionErrors = copyMapStringInt(st.assertionErrors)
+		}
+		if len(st.errors) > 0 {
+			ss.Errors = copyMapStringInt(st.errors)
+		}
+		ss.StatusCodes = make(map[string]int, len(st.statusCodes))
+		for code, n := range st.statusCodes {
+			ss.StatusCodes[statusKey(code)] = n
+		}
+		if st.requests > 0 {
+			ss.SuccessRate = float64(st.success) / float64(st.requests) * 100
+		}
+		if duration > 0 {
+			ss.RPS = float64(st.requests) / duration
+		}
+		out = append(out, ss)
+	}
+	return out
+}
+
+// bucketStepSnapshot summarises the steps that ran in a second (nil when none did).
+func bucketStepSnapshot(b *secondBucket) map[string]models.StepSecondStats {
+	b.stepMu.Lock()
+	defer b.stepMu.Unlock()
+	return summarizeSteps(b.steps)
+}
+
+// summarizeSteps returns the metrics of the steps that ran (nil when none did).
+func summarizeSteps(steps map[string]*stepBucket) map[string]models.StepSecondStats {
+	var out map[string]models.StepSecondStats
+	us := func(v int64) time.Duration { return time.Duration(v) * time.Microsecond }
+	for name, sb := range steps {
+		if sb.requests == 0 {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]models.StepSecondStats, len(steps))
+		}
+		out[name] = models.StepSecondStats{
+			Requests: sb.requests,
+			Failures: sb.fail,
+			P50:      us(sb.hist.ValueAtQuantile(50)),
+			P95:      us(sb.hist.ValueAtQuantile(95)),
+			P99:      us(sb.hist.ValueAtQuantile(99)),
+		}
+	}
+	return out
+}
+
+// percentiles reads the latencies at ps from h.
+func percentiles(h *hdrhistogram.Histogram, ps []float64) []models.PercentileValue {
+	out := make([]models.PercentileValue, len(ps))
+	for i, p := range ps {
+		out[i] = models.PercentileValue{P: p, Value: time.Duration(h.ValueAtQuantile(p)) * time.Microsecond}
+	}
+	return out
+}
+
+// fus converts a fractional number of microseconds, e.g. a histogram mean.
+func fus(v float64) time.Duration {
+	return time.Duration(v * float64(time.Microsecond))
+}
+
+// statusKey is the report key of a status code; timeouts are grouped under "Timeout".
+func statusKey(code int) string {
+	if code == 1 {
+		return "Timeout"
+	}
+	return strconv.Itoa(code)
+}
+
 // GetStats returns current counters for circuit breaker checks.
 func (m *Monitor) GetStats() (totalRequests, failures, assertionFailures int64) {
 	return atomic.LoadInt64(&m.requests),
@@ -262,13 +615,16 @@ func (m *Monitor) Snapshot() models.Report {
 	succ := atomic.LoadInt64(&m.success)
 	fail := atomic.LoadInt64(&m.fail)
 	totalBytes := atomic.LoadInt64(&m.totalBytes)
+	totalWireBytes := atomic.LoadInt64(&m.totalWireBytes)
 
-	duration := time.Since(m.startTime).Seconds()
+	duration := m.now().Sub(m.startTime).Seconds()
 	rps := 0.0
 	throughput := 0.0
+	wireThroughput := 0.0
 	if duration > 0 {
 		rps = float64(reqs) / duration
 		throughput = float64(totalBytes) / duration / 1024 / 1024
+		wireThroughput = float64(totalWireBytes) / duration / 1024 / 1024
 	}
 
 	successRate := 0.0
@@ -298,18 +654,14 @@ func (m *Monitor) Snapshot() models.Report {
 	p99 := time.Duration(h.ValueAtQuantile(99)) * time.Microsecond
 	maxLat := time.Duration(h.Max()) * time.Microsecond
 	minLat := time.Duration(h.Min()) * time.Microsecond
+	mean := fus(h.Mean())
+	stdDev := fus(h.StdDev())
+	latencyPercentiles := percentiles(h, m.percentiles)
 
 	// Reuse pre-allocated maps — clear entries without deallocating storage.
 	clear(m.snapStatusMap)
 	m.statusCodes.Range(func(key, value interface{}) bool {
-		code := key.(int)
-		var sKey string
-		if code == 1 {
-			sKey = "Timeout"
-		} else {
-			sKey = fmt.Sprintf("%d", code)
-		}
-		m.snapStatusMap[sKey] = int(value.(*atomic.Int64).Load())
+		m.snapStatusMap[statusKey(key.(int))] = int(value.(*atomic.Int64).Load())
 		return true
 	})
 
@@ -318,12 +670,27 @@ func (m *Monitor) Snapshot() models.Report {
 		m.snapErrorMap[key.(string)] = int(value.(*atomic.Int64).Load())
 		return true
 	})
+	var samples map[string][]string
+	m.errorSamples.Range(func(key, value interface{}) bool {
+		if samples == nil {
+			samples = make(map[string][]string)
+		}
+		samples[key.(string)] = value.(*errorSamples).snapshot()
+		return true
+	})
 
-	// Build time series from the ring buffer — only the visible window.
+	// Build the time series from the downsampled history followed by the ring
+	// buffer. historyMu is taken before bucketMu is released, so the history ends
+	// exactly where the visible window of the ring starts.
+	bucketSeconds := int(m.resolution / time.Second)
 	m.bucketMu.Lock()
 	total := m.bucketTotal
 	ringCap := m.bucketRingCap
+	m.historyMu.Lock()
 	m.bucketMu.Unlock()
+	m.snapTimeSeries = m.archiveSnapshot(m.snapTimeSeries[:0], bucketSeconds)
+	m.historyMu.Unlock()
+	archived := len(m.snapTimeSeries)
 
 	// +1 skips the oldest slot that may be concurrently reset by getOrCreateBucket
 	// when the ring wraps around (tests > bucketWindow seconds).
@@ -331,14 +698,16 @@ func (m *Monitor) Snapshot() models.Report {
 	if windowStart < 0 {
 		windowStart = 0
 	}
-	needed := total - windowStart
+	needed := archived + total - windowStart
 	if cap(m.snapTimeSeries) < needed {
-		m.snapTimeSeries = make([]models.SecondStats, needed, needed+64)
+		grown := make([]models.SecondStats, needed, needed+64)
+		copy(grown, m.snapTimeSeries)
+		m.snapTimeSeries = grown
 	} else {
 		m.snapTimeSeries = m.snapTimeSeries[:needed]
 	}
 
-	for i, absSecond := 0, windowStart; absSecond < total; i, absSecond = i+1, absSecond+1 {
+	for i, absSecond := archived, windowStart; absSecond < total; i, absSecond = i+1, absSecond+1 {
 		bucket := m.bucketRing[absSecond%ringCap]
 
 		bucketReqs := atomic.LoadInt64(&bucket.requests)
@@ -368,19 +737,12 @@ func (m *Monitor) Snapshot() models.Report {
 
 		bucketStatusCodes := make(map[string]int)
 		bucket.statusCodes.Range(func(key, value interface{}) bool {
-			code := key.(int)
-			var sKey string
-			if code == 1 {
-				sKey = "Timeout"
-			} else {
-				sKey = fmt.Sprintf("%d", code)
-			}
-			bucketStatusCodes[sKey] = int(value.(*atomic.Int64).Load())
+			bucketStatusCodes[statusKey(key.(int))] = int(value.(*atomic.Int64).Load())
 			return true
 		})
 
 		m.snapTimeSeries[i] = models.SecondStats{
-			Second:      absSecond + 1,
+			Second:      (absSecond + 1) * bucketSeconds,
 			Requests:    bucketReqs,
 			Success:     bucketSucc,
 			Failures:    bucketFail,
@@ -391,6 +753,9 @@ func (m *Monitor) Snapshot() models.Report {
 			P95:         bp95,
 			P99:         bp99,
 			StatusCodes: bucketStatusCodes,
+			Percentiles: percentiles(bh, m.percentiles),
+			Histogram:   binCounts(bucket.bins),
+			Steps:       bucketStepSnapshot(bucket),
 		}
 	}
 
@@ -414,6 +779,8 @@ func (m *Monitor) Snapshot() models.Report {
 		SuccessRate:       successRate,
 		TotalBytes:        totalBytes,
 		Throughput:        throughput,
+		TotalWireBytes:    totalWireBytes,
+		WireThroughput:    wireThroughput,
 		RPS:               rps,
 		P50:               p50,
 		P75:               p75,
@@ -422,10 +789,41 @@ func (m *Monitor) Snapshot() models.Report {
 		P99:               p99,
 		Max:               maxLat,
 		Min:               minLat,
+		Mean:              mean,
+		StdDev:            stdDev,
+		Percentiles:       latencyPercentiles,
+		HistogramBounds:   models.LatencyBounds,
+		LatencyHistogram:  binCounts(m.bins),
 		StatusCodes:       copyMapStringInt(m.snapStatusMap),
 		Errors:            copyMapStringInt(m.snapErrorMap),
+		ErrorSamples:      samples,
 		AssertionErrors:   copyMapStringInt(m.snapAssertionMap),
 		ProtocolCounts:    copyMapStringInt(m.snapProtocolMap),
 		TimeSeriesData:    append([]models.SecondStats(nil), m.snapTimeSeries...),
+		Stream:            m.streamSnapshot(duration),
+		Steps:             m.stepSnapshot(duration),
+		BucketSeconds:     bucketSeconds,
+		Annotations:       m.annotationSnapshot(),
 	}
 }
+
+// Annotate marks a manual control action in the time series bucket of now.
+func (m *Monitor) Annotate(action, label string) {
+	second := int(m.now().Sub(m.startTime) / m.resolution)
+	if second < 0 {
+		second = 0
+	}
+	m.annotationMu.Lock()
+	m.annotations = append(m.annotations, models.Annotation{
+		Second: (second + 1) * int(m.resolution/time.Second),
+		Action: action,
+		Label:  label,
+	})
+	m.annotationMu.Unlock()
+}
+
+func (m *Monitor) annotationSnapshot() []models.Annotation {
+	m.annotationMu.Lock()
+	defer m.annotationMu.Unlock()
+	return append([]models.Annotation(nil), m.annotations...)
+}
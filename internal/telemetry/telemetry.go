@@ -0,0 +1,294 @@
+// Package telemetry exports live run metrics and, optionally, request traces to an
+// OpenTelemetry (OTLP) endpoint, so load-test activity can be correlated with the
+// traces and metrics of the system under test.
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Amr-9/sayl/internal/runner"
+	"github.com/Amr-9/sayl/pkg/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// DefaultInterval is the metrics export interval when none is configured.
+const DefaultInterval = 10 * time.Second
+
+// Source provides the live data of a run. *runner.Runner implements it.
+type Source interface {
+	Snapshot() models.Report
+	InFlight() int64
+	TargetRate() float64
+	CircuitOpen() bool
+}
+
+// Exporter pushes the metrics of the attached run to an OTLP endpoint and,
+// when traces are enabled, records a span per scenario iteration and step.
+type Exporter struct {
+	meters *sdkmetric.MeterProvider
+	traces *sdktrace.TracerProvider // nil when traces are disabled
+
+	mu  sync.RWMutex
+	src Source
+}
+
+// New creates an exporter for cfg. Nothing is sent until a run is attached.
+func New(ctx context.Context, cfg *models.OTLPConfig) (*Exporter, error) {
+	if cfg.Endpoint == "" {
+		return nil, errors.New("missing OTLP endpoint")
+	}
+	protocol := cfg.Protocol
+	if protocol == "" {
+		protocol = models.OTLPProtocolGRPC
+	}
+	interval := cfg.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	sampleRate := cfg.SampleRate
+	if sampleRate <= 0 || sampleRate > 1 {
+		sampleRate = 1
+	}
+
+	res := resource.NewSchemaless(
+		attribute.String("service.name", "sayl"),
+		attribute.String("sayl.run.id", newRunID()),
+	)
+
+	metricExp, err := newMetricExporter(ctx, protocol, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP metric exporter: %w", err)
+	}
+
+	e := &Exporter{
+		meters: sdkmetric.NewMeterProvider(
+			sdkmetric.WithResource(res),
+			sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExp, sdkmetric.WithInterval(interval))),
+		),
+	}
+	if err := e.registerInstruments(); err != nil {
+		e.meters.Shutdown(ctx)
+		return nil, fmt.Errorf("failed to register OTLP metrics: %w", err)
+	}
+
+	if cfg.Traces {
+		traceExp, err := newTraceExporter(ctx, protocol, cfg)
+		if err != nil {
+			e.meters.Shutdown(ctx)
+			return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+		}
+		e.traces = sdktrace.NewTracerProvider(
+			sdktrace.WithResource(res),
+			sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRate))),
+			sdktrace.WithBatcher(traceExp, sdktrace.WithMaxQueueSize(65536), sdktrace.WithMaxExportBatchSize(4096)),
+		)
+	}
+	return e, nil
+}
+
+// Attach exports the metrics of r and enables tracing on it. It implements runner.Observer.
+func (e *Exporter) Attach(r *runner.Runner) {
+	e.SetSource(r)
+	if e.traces != nil {
+		r.SetTracer(e.traces.Tracer("github.com/Amr-9/sayl"))
+	}
+}
+
+// SetSource makes the exporter report src.
+func (e *Exporter) SetSource(src Source) {
+	e.mu.Lock()
+	e.src = src
+	e.mu.Unlock()
+}
+
+// Shutdown exports the final metrics and any buffered spans, then stops the exporter.
+func (e *Exporter) Shutdown(ctx context.Context) error {
+	var errs []error
+	if e.traces != nil {
+		errs = append(errs, e.traces.Shutdown(ctx))
+	}
+	errs = append(errs, e.meters.Shutdown(ctx))
+	return errors.Join(errs...)
+}
+
+// registerInstruments mirrors the Prometheus endpoint: every instrument is observed
+// from a single snapshot of the run at each export.
+func (e *Exporter) registerInstruments() error {
+	meter := e.meters.Meter("github.com/Amr-9/sayl")
+
+	var errs []error
+	counter := func(name, desc string) metric.Int64ObservableCounter {
+		c, err := meter.Int64ObservableCounter(name, metric.WithDescription(desc), metric.WithUnit("{request}"))
+		errs = append(errs, err)
+		return c
+	}
+	bytesCounter := func(name, desc string) metric.Int64ObservableCounter {
+		c, err := meter.Int64ObservableCounter(name, metric.WithDescription(desc), metric.WithUnit("By"))
+		errs = append(errs, err)
+		return c
+	}
+	gauge := func(name, desc, unit string) metric.Float64ObservableGauge {
+		g, err := meter.Float64ObservableGauge(name, metric.WithDescription(desc), metric.WithUnit(unit))
+		errs = append(errs, err)
+		return g
+	}
+
+	var (
+		requests      = counter("sayl.requests", "Requests completed, by status code (\"Timeout\" for timeouts, \"0\" for other network errors).")
+		failed        = counter("sayl.requests.failed", "Requests that failed (network error, unexpected status or assertion).")
+		assertions    = counter("sayl.assertion_failures", "Requests that failed an assertion.")
+		respBytes     = bytesCounter("sayl.response.bytes", "Response body bytes received after decompression.")
+		wireBytes     = bytesCounter("sayl.response.wire_bytes", "Response body bytes received on the wire.")
+		latency       = gauge("sayl.request.latency", "Request latency percentiles over the run so far (quantile 1 is the maximum).", "s")
+		stepRequests  = counter("sayl.step.requests", "Requests completed, by scenario step.")
+		stepFailed    = counter("sayl.step.requests.failed", "Requests that failed, by scenario step.")
+		stepLatency   = gauge("sayl.step.latency", "Request latency percentiles by scenario step (quantile 1 is the maximum).", "s")
+		inFlight      = gauge("sayl.in_flight", "Requests currently being executed.", "{request}")
+		targetRate    = gauge("sayl.target_rate", "Current target rate in scenario iterations per second.", "{iteration}/s")
+		rps           = gauge("sayl.rps", "Average requests per second since the start of the run.", "{request}/s")
+		circuitOpen   = gauge("sayl.circuit_breaker.open", "1 when the circuit breaker (load.stop_if) has stopped the run.", "1")
+		observed      = []metric.Observable{requests, failed, assertions, respBytes, wireBytes, latency, stepRequests, stepFailed, stepLatency, inFlight, targetRate, rps, circuitOpen}
+		quantileAttrs = []string{"0.5", "0.75", "0.9", "0.95", "0.99", "1"}
+	)
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	_, err := meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		e.mu.RLock()
+		src := e.src
+		e.mu.RUnlock()
+		if src == nil {
+			return nil
+		}
+
+		rep := src.Snapshot()
+		for _, code := range sortedKeys(rep.StatusCodes) {
+			o.ObserveInt64(requests, int64(rep.StatusCodes[code]), metric.WithAttributes(attribute.String("status", code)))
+		}
+		o.ObserveInt64(failed, rep.FailureCount)
+		o.ObserveInt64(assertions, rep.AssertionFailures)
+		o.ObserveInt64(respBytes, rep.TotalBytes)
+		o.ObserveInt64(wireBytes, rep.TotalWireBytes)
+
+		all := []time.Duration{rep.P50, rep.P75, rep.P90, rep.P95, rep.P99, rep.Max}
+		for i, q := range quantileAttrs {
+			o.ObserveFloat64(latency, all[i].Seconds(), metric.WithAttributes(attribute.String("quantile", q)))
+		}
+
+		for _, st := range rep.Steps {
+			step := attribute.String("step", st.Name)
+			o.ObserveInt64(stepRequests, st.Requests, metric.WithAttributes(step))
+			o.ObserveInt64(stepFailed, st.Failures, metric.WithAttributes(step))
+			p := []time.Duration{st.P50, st.P75, st.P90, st.P95, st.P99, st.Max}
+			for i, q := range quantileAttrs {
+				o.ObserveFloat64(stepLatency, p[i].Seconds(), metric.WithAttributes(step, attribute.String("quantile", q)))
+			}
+		}
+
+		o.ObserveFloat64(inFlight, float64(src.InFlight()))
+		o.ObserveFloat64(targetRate, src.TargetRate())
+		o.ObserveFloat64(rps, rep.RPS)
+		open := 0.0
+		if src.CircuitOpen() {
+			open = 1
+		}
+		o.ObserveFloat64(circuitOpen, open)
+		return nil
+	}, observed...)
+	return err
+}
+
+// isURL reports whether the endpoint includes a scheme, in which case it is
+// passed to the exporter as a URL (an http:// URL implies an insecure connection).
+func isURL(endpoint string) bool {
+	return strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://")
+}
+
+func newMetricExporter(ctx context.Context, protocol string, cfg *models.OTLPConfig) (sdkmetric.Exporter, error) {
+	switch protocol {
+	case models.OTLPProtocolGRPC:
+		opts := []otlpmetricgrpc.Option{otlpmetricgrpc.WithHeaders(cfg.Headers)}
+		if isURL(cfg.Endpoint) {
+			opts = append(opts, otlpmetricgrpc.WithEndpointURL(cfg.Endpoint))
+		} else {
+			opts = append(opts, otlpmetricgrpc.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+		return otlpmetricgrpc.New(ctx, opts...)
+	case models.OTLPProtocolHTTP:
+		opts := []otlpmetrichttp.Option{otlpmetrichttp.WithHeaders(cfg.Headers)}
+		if isURL(cfg.Endpoint) {
+			opts = append(opts, otlpmetrichttp.WithEndpointURL(strings.TrimSuffix(cfg.Endpoint, "/")+"/v1/metrics"))
+		} else {
+			opts = append(opts, otlpmetrichttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+		return otlpmetrichttp.New(ctx, opts...)
+	}
+	return nil, fmt.Errorf("unsupported OTLP protocol '%s' (use grpc or http)", protocol)
+}
+
+func newTraceExporter(ctx context.Context, protocol string, cfg *models.OTLPConfig) (sdktrace.SpanExporter, error) {
+	switch protocol {
+	case models.OTLPProtocolGRPC:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithHeaders(cfg.Headers)}
+		if isURL(cfg.Endpoint) {
+			opts = append(opts, otlptracegrpc.WithEndpointURL(cfg.Endpoint))
+		} else {
+			opts = append(opts, otlptracegrpc.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case models.OTLPProtocolHTTP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithHeaders(cfg.Headers)}
+		if isURL(cfg.Endpoint) {
+			opts = append(opts, otlptracehttp.WithEndpointURL(strings.TrimSuffix(cfg.Endpoint, "/")+"/v1/traces"))
+		} else {
+			opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	}
+	return nil, fmt.Errorf("unsupported OTLP protocol '%s' (use grpc or http)", protocol)
+}
+
+// newRunID identifies the run in the resource attributes.
+func newRunID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,272 @@
+package threshold
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// Metric kinds determine how a threshold value is parsed and how the actual value is formatted.
+const (
+	kindLatency = iota // compared in milliseconds, written as a duration ("250ms", "1s")
+	kindPercent        // compared in percent, written as "0.5%" or as a fraction ("0.005")
+	kindNumber         // compared as a plain number
+)
+
+// metricKinds lists the supported metrics for both run-wide and per-step thresholds.
+var metricKinds = map[string]int{
+	"p50":          kindLatency,
+	"p75":          kindLatency,
+	"p90":          kindLatency,
+	"p95":          kindLatency,
+	"p99":          kindLatency,
+	"min":          kindLatency,
+	"max":          kindLatency,
+	"mean":         kindLatency,
+	"stddev":       kindLatency,
+	"error_rate":   kindPercent,
+	"success_rate": kindPercent,
+	"rps":          kindNumber,
+	"requests":     kindNumber,
+	"failures":     kindNumber,
+}
+
+// exprPattern matches "metric op value" where metric may be prefixed by "steps.<name>.".
+// A metric starts with a letter and may end in a fraction ("p99.9"); it is matched
+// case-insensitively ("P95", "Error_Rate") and lowercased by Parse.
+var exprPattern = regexp.MustCompile(`^\s*(?:steps\.(.+)\.)?((?i:[a-z_][a-z0-9_]*)(?:\.[0-9]+)?)\s*(<=|>=|<|>)\s*(\S+)\s*$`)
+
+// percentilePattern matches any percentile metric, e.g. "p99.9".
+var percentilePattern = regexp.MustCompile(`^p([0-9]+(?:\.[0-9]+)?)$`)
+
+// Percentile returns the percentile of a metric such as "p99.9", and whether the
+// metric is a percentile at all.
+func Percentile(metric string) (float64, bool) {
+	m := percentilePattern.FindStringSubmatch(metric)
+	if m == nil {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(m[1], 64)
+	if err != nil || p <= 0 || p >= 100 {
+		return 0, false
+	}
+	return p, true
+}
+
+// Parse parses a threshold expression such as "p95 < 250ms", "error_rate < 0.5%",
+// "rps > 400" or "steps.login.p99 < 1s".
+func Parse(expr string) (models.Threshold, error) {
+	matches := exprPattern.FindStringSubmatch(expr)
+	if matches == nil {
+		return models.Threshold{}, fmt.Errorf("invalid threshold '%s'. Expected format: 'p95 < 250ms', 'error_rate < 1%%' or 'steps.login.p99 < 1s'", expr)
+	}
+
+	th := models.Threshold{
+		Expr:     strings.TrimSpace(expr),
+		Step:     matches[1],
+		Metric:   strings.ToLower(matches[2]),
+		Operator: matches[3],
+	}
+
+	kind, ok := metricKinds[th.Metric]
+	if _, isPercentile := Percentile(th.Metric); !ok && isPercentile {
+		kind, ok = kindLatency, true
+	}
+	if !ok {
+		return models.Threshold{}, fmt.Errorf("unknown threshold metric '%s' in '%s' (supported: p50, p90, p99.9 or any other percentile, min, max, mean, stddev, error_rate, success_rate, rps, requests, failures)", th.Metric, expr)
+	}
+
+	raw := matches[4]
+	switch kind {
+	case kindLatency:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return models.Threshold{}, fmt.Errorf("invalid duration '%s' in threshold '%s' (use a unit, e.g. 250ms or 1s)", raw, expr)
+		}
+		th.Value = float64(d) / float64(time.Millisecond)
+	case kindPercent:
+		isPercent := strings.HasSuffix(raw, "%")
+		v, err := strconv.ParseFloat(strings.TrimSuffix(raw, "%"), 64)
+		if err != nil {
+			return models.Threshold{}, fmt.Errorf("invalid value '%s' in threshold '%s'", raw, expr)
+		}
+		if !isPercent {
+			v *= 100 // Fractions (0.005) are stored as percent (0.5)
+		}
+		th.Value = v
+	default:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return models.Threshold{}, fmt.Errorf("invalid value '%s' in threshold '%s'", raw, expr)
+		}
+		th.Value = v
+	}
+
+	return th, nil
+}
+
+// Evaluate checks every threshold against the final report.
+// A per-step threshold whose step never ran fails with actual "no data".
+func Evaluate(r models.Report, thresholds []models.Threshold) []models.ThresholdResult {
+	results := make([]models.ThresholdResult, 0, len(thresholds))
+	for _, th := range thresholds {
+		actual, ok := lookup(r, th)
+		if !ok {
+			results = append(results, models.ThresholdResult{Expr: th.Expr, Actual: "no data", Passed: false})
+			continue
+		}
+		results = append(results, models.ThresholdResult{
+			Expr:   th.Expr,
+			Actual: format(th.Metric, actual),
+			Passed: compare(actual, th.Operator, th.Value),
+		})
+	}
+	return results
+}
+
+// Passed reports whether every threshold result passed.
+func Passed(results []models.ThresholdResult) bool {
+	for _, res := range results {
+		if !res.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup returns the actual value of a threshold metric in the threshold's unit.
+func lookup(r models.Report, th models.Threshold) (float64, bool) {
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	pct := func(part, total int64) float64 {
+		if total == 0 {
+			return 0
+		}
+		return float64(part) / float64(total) * 100
+	}
+
+	if th.Step != "" {
+		for _, st := range r.Steps {
+			if st.Name != th.Step {
+				continue
+			}
+			switch th.Metric {
+			case "p50":
+				return ms(st.P50), true
+			case "p75":
+				return ms(st.P75), true
+			case "p90":
+				return ms(st.P90), true
+			case "p95":
+				return ms(st.P95), true
+			case "p99":
+				return ms(st.P99), true
+			case "min":
+				return ms(st.Min), true
+			case "max":
+				return ms(st.Max), true
+			case "mean":
+				return ms(st.Mean), true
+			case "stddev":
+				return ms(st.StdDev), true
+			case "error_rate":
+				return pct(st.Failures, st.Requests), true
+			case "success_rate":
+				return st.SuccessRate, true
+			case "rps":
+				return st.RPS, true
+			case "requests":
+				return float64(st.Requests), true
+			case "failures":
+				return float64(st.Failures), true
+			}
+			return percentile(st.Percentiles, th.Metric)
+		}
+		return 0, false
+	}
+
+	switch th.Metric {
+	case "p50":
+		return ms(r.P50), true
+	case "p75":
+		return ms(r.P75), true
+	case "p90":
+		return ms(r.P90), true
+	case "p95":
+		return ms(r.P95), true
+	case "p99":
+		return ms(r.P99), true
+	case "min":
+		return ms(r.Min), true
+	case "max":
+		return ms(r.Max), true
+	case "mean":
+		return ms(r.Mean), true
+	case "stddev":
+		return ms(r.StdDev), true
+	case "error_rate":
+		return pct(r.FailureCount, r.TotalRequests), true
+	case "success_rate":
+		return r.SuccessRate, true
+	case "rps":
+		return r.RPS, true
+	case "requests":
+		return float64(r.TotalRequests), true
+	case "failures":
+		return float64(r.FailureCount), true
+	}
+	return percentile(r.Percentiles, th.Metric)
+}
+
+// percentile finds a percentile metric in the configured set. The config loader
+// adds every percentile used by a threshold to that set.
+func percentile(values []models.PercentileValue, metric string) (float64, bool) {
+	p, ok := Percentile(metric)
+	if !ok {
+		return 0, false
+	}
+	for _, v := range values {
+		if v.P == p {
+			return float64(v.Value) / float64(time.Millisecond), true
+		}
+	}
+	return 0, false
+}
+
+func compare(actual float64, op string, value float64) bool {
+	switch op {
+	case "<":
+		return actual < value
+	case "<=":
+		return actual <= value
+	case ">":
+		return actual > value
+	case ">=":
+		return actual >= value
+	}
+	return false
+}
+
+func format(metric string, v float64) string {
+	kind, ok := metricKinds[metric]
+	if _, isPercentile := Percentile(metric); !ok && isPercentile {
+		kind = kindLatency
+	}
+	switch kind {
+	case kindLatency:
+		if v >= 1000 {
+			return fmt.Sprintf("%.2fs", v/1000)
+		}
+		return fmt.Sprintf("%.1fms", v)
+	case kindPercent:
+		return fmt.Sprintf("%.2f%%", v)
+	default:
+		if metric == "rps" {
+			return fmt.Sprintf("%.1f", v)
+		}
+		return fmt.Sprintf("%.0f", v)
+	}
+}
@@ -0,0 +1,151 @@
+package threshold
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		expr string
+		want models.Threshold
+	}{
+		{"p95 < 250ms", models.Threshold{Metric: "p95", Operator: "<", Value: 250}},
+		{"p99 <= 1s", models.Threshold{Metric: "p99", Operator: "<=", Value: 1000}},
+		{"max < 1m30s", models.Threshold{Metric: "max", Operator: "<", Value: 90000}},
+		{"mean < 500us", models.Threshold{Metric: "mean", Operator: "<", Value: 0.5}},
+		{"p99.9 < 2s", models.Threshold{Metric: "p99.9", Operator: "<", Value: 2000}},
+		{"p99.99<3s", models.Threshold{Metric: "p99.99", Operator: "<", Value: 3000}},
+		{"error_rate < 1%", models.Threshold{Metric: "error_rate", Operator: "<", Value: 1}},
+		{"error_rate < 0.005", models.Threshold{Metric: "error_rate", Operator: "<", Value: 0.5}},
+		{"success_rate >= 99.5%", models.Threshold{Metric: "success_rate", Operator: ">=", Value: 99.5}},
+		{"rps > 400", models.Threshold{Metric: "rps", Operator: ">", Value: 400}},
+		{"failures <= 0", models.Threshold{Metric: "failures", Operator: "<=", Value: 0}},
+		{"  requests >= 1000  ", models.Threshold{Metric: "requests", Operator: ">=", Value: 1000}},
+		{"P95 < 250ms", models.Threshold{Metric: "p95", Operator: "<", Value: 250}},
+		{"Error_Rate < 1%", models.Threshold{Metric: "error_rate", Operator: "<", Value: 1}},
+		{"P99.9 < 1s", models.Threshold{Metric: "p99.9", Operator: "<", Value: 1000}},
+		{"steps.login.p99 < 1s", models.Threshold{Step: "login", Metric: "p99", Operator: "<", Value: 1000}},
+		{"steps.Login.RPS > 10", models.Threshold{Step: "Login", Metric: "rps", Operator: ">", Value: 10}},
+		{"steps.api.v1.p99.9 < 1s", models.Threshold{Step: "api.v1", Metric: "p99.9", Operator: "<", Value: 1000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tt.want.Expr = strings.TrimSpace(tt.expr)
+			if got != tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string // Part of the error message
+	}{
+		{"", "invalid threshold"},
+		{"p95", "invalid threshold"},
+		{"p95 = 250ms", "invalid threshold"},
+		{"p95 == 250ms", "invalid threshold"},
+		{"p95 != 250ms", "invalid threshold"},
+		{"p95 =< 250ms", "invalid threshold"},
+		{"p95 < 250 ms", "invalid threshold"},
+		{"95p < 250ms", "invalid threshold"},
+		{"latency < 250ms", "unknown threshold metric"},
+		{"p100 < 1s", "unknown threshold metric"},
+		{"p0 < 1s", "unknown threshold metric"},
+		{"p95 < 250", "invalid duration"},
+		{"p95 < 250MS", "invalid duration"},
+		{"p95 < 5%", "invalid duration"},
+		{"error_rate < one%", "invalid value"},
+		{"error_rate < 1%%", "invalid value"},
+		{"rps > 10/s", "invalid value"},
+		{"requests > 1k", "invalid value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := Parse(tt.expr)
+			if err == nil {
+				t.Fatalf("expected an error containing %q", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error %q does not contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		metric string
+		want   float64
+		ok     bool
+	}{
+		{"p50", 50, true},
+		{"p99.9", 99.9, true},
+		{"p99.999", 99.999, true},
+		{"p0", 0, false},
+		{"p100", 0, false},
+		{"p", 0, false},
+		{"p99.", 0, false},
+		{"max", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := Percentile(tt.metric)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Percentile(%q) = %v, %v; want %v, %v", tt.metric, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	r := models.Report{
+		TotalRequests: 1000,
+		FailureCount:  5,
+		SuccessRate:   99.5,
+		RPS:           250,
+		P95:           200 * time.Millisecond,
+		Percentiles:   []models.PercentileValue{{P: 99.9, Value: 1500 * time.Millisecond}},
+		Steps:         []models.StepStats{{Name: "login", Requests: 100, P99: 800 * time.Millisecond}},
+	}
+
+	tests := []struct {
+		expr   string
+		actual string
+		passed bool
+	}{
+		{"p95 < 250ms", "200.0ms", true},
+		{"p95 < 200ms", "200.0ms", false},
+		{"p95 <= 200ms", "200.0ms", true},
+		{"p99.9 < 1s", "1.50s", false},
+		{"error_rate < 1%", "0.50%", true},
+		{"error_rate < 0.001", "0.50%", false},
+		{"success_rate >= 99%", "99.50%", true},
+		{"rps > 300", "250.0", false},
+		{"requests >= 1000", "1000", true},
+		{"steps.login.p99 < 1s", "800.0ms", true},
+		{"steps.checkout.p99 < 1s", "no data", false},
+		{"p99.99 < 1s", "no data", false},
+	}
+
+	for _, tt := range tests {
+		th, err := Parse(tt.expr)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.expr, err)
+		}
+		res := Evaluate(r, []models.Threshold{th})[0]
+		if res.Actual != tt.actual || res.Passed != tt.passed {
+			t.Errorf("%s: got %s (passed %v), want %s (passed %v)", tt.expr, res.Actual, res.Passed, tt.actual, tt.passed)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Amr-9/sayl/internal/runner"
 	"github.com/Amr-9/sayl/pkg/models"
 	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
@@ -15,13 +16,15 @@ import (
 type DashModel struct {
 	config   models.Config
 	report   models.Report
+	runner   *runner.Runner // Receives the runtime controls (pause, rate, stages)
 	start    time.Time
 	progress progress.Model
 	history  []string // Rendered history lines
 	tick     int      // For animations
+	notice   string   // Outcome of the last control key
 }
 
-func NewDashModel(cfg models.Config, history []string) *DashModel {
+func NewDashModel(cfg models.Config, history []string, r *runner.Runner) *DashModel {
 	p := progress.New(
 		progress.WithScaledGradient("#00FFFF", "#FF6B9D"),
 		progress.WithoutPercentage(),
@@ -29,6 +32,7 @@ func NewDashModel(cfg models.Config, history []string) *DashModel {
 	return &DashModel{
 		config:   cfg,
 		report:   models.Report{},
+		runner:   r,
 		start:    time.Now(),
 		progress: p,
 		history:  history,
@@ -45,10 +49,77 @@ func (m *DashModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case models.Report:
 		m.report = msg
 		m.tick++
+	case tea.KeyMsg:
+		m.control(msg.String())
 	}
 	return m, nil
 }
 
+// control applies the runtime control bound to key: p or space pauses and
+// resumes, +/- change the target rate by the runner's rate step and n skips to
+// the next stage.
+func (m *DashModel) control(key string) {
+	if m.runner == nil {
+		return
+	}
+	var err error
+	switch key {
+	case "p", " ":
+		if m.runner.Paused() {
+			if err = m.runner.Resume(); err == nil {
+				m.notice = "▶ Resumed"
+			}
+		} else if err = m.runner.Pause(); err == nil {
+			m.notice = "⏸ Paused — press p to resume"
+		}
+	case "+", "=", "-", "_":
+		delta := m.runner.RateStep()
+		if key == "-" || key == "_" {
+			delta = -delta
+		}
+		var target float64
+		if target, err = m.runner.AdjustRate(delta); err == nil {
+			m.notice = fmt.Sprintf("🎚 Target rate %+g → %.0f req/s", delta, target)
+		}
+	case "n":
+		var next int
+		if next, err = m.runner.SkipStage(); err == nil {
+			if _, count := m.runner.Stage(); next < count {
+				m.notice = fmt.Sprintf("⏭ Skipped to stage %d/%d", next+1, count)
+			} else {
+				m.notice = "⏭ Skipped the last stage — finishing"
+			}
+		}
+	default:
+		return
+	}
+	if err != nil {
+		m.notice = "⚠ " + err.Error()
+	}
+}
+
+// controlLine shows the state of the runtime controls and their keys.
+func (m *DashModel) controlLine() string {
+	var parts []string
+	if m.runner.Paused() {
+		parts = append(parts, warnText.Bold(true).Render("⏸ PAUSED"))
+	}
+	parts = append(parts, metaStyle.Render(fmt.Sprintf("target %.0f req/s", m.runner.TargetRate())))
+	if stage, count := m.runner.Stage(); count > 0 {
+		parts = append(parts, metaStyle.Render(fmt.Sprintf("stage %d/%d", min(stage+1, count), count)))
+	}
+	keys := "p pause · +/- rate"
+	if len(m.config.Stages) > 0 {
+		keys += " · n next stage"
+	}
+	parts = append(parts, lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Italic(true).Render(keys+" · ctrl+c stop"))
+	line := strings.Join(parts, metaStyle.Render(" │ "))
+	if m.notice != "" {
+		line += "\n" + lipgloss.NewStyle().Foreground(accentColor).Render(m.notice)
+	}
+	return line
+}
+
 func (m *DashModel) View() string {
 	var s strings.Builder
 
@@ -87,7 +158,11 @@ func (m *DashModel) View() string {
 	// PROGRESS BAR SECTION
 	// ═══════════════════════════════════════════════════════════════
 
+	// The test time stops while paused and jumps when a stage is skipped.
 	elapsed := time.Since(m.start)
+	if m.runner != nil {
+		elapsed = m.runner.Elapsed()
+	}
 	pct := float64(elapsed) / float64(m.config.Duration)
 	if pct > 1.0 {
 		pct = 1.0
@@ -115,6 +190,10 @@ func (m *DashModel) View() string {
 	s.WriteString("\n")
 	s.WriteString(timeInfo)
 	s.WriteString("\n")
+	if m.runner != nil {
+		s.WriteString(m.controlLine())
+		s.WriteString("\n")
+	}
 	s.WriteString(dividerStyle.Render("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
 	s.WriteString("\n\n")
 
@@ -125,6 +204,7 @@ func (m *DashModel) View() string {
 	// Calculate values
 	rps := fmt.Sprintf("%.1f", m.report.RPS)
 	tput := formatThroughput(m.report.TotalBytes, elapsed.Seconds())
+	wireTput := formatThroughput(m.report.TotalWireBytes, elapsed.Seconds())
 	totalData := formatBytes(m.report.TotalBytes)
 
 	// Sparkline
@@ -140,12 +220,14 @@ func (m *DashModel) View() string {
 	spark := renderSparkline(rpsHistory)
 
 	// BOX 1: Performance
-	box1Content := fmt.Sprintf("%s\n%s %s\n%s %s\n%s %s\n%s",
+	box1Content := fmt.Sprintf("%s\n%s %s\n%s %s\n%s %s\n%s %s\n%s",
 		lipgloss.NewStyle().Foreground(purpleColor).Bold(true).Render("📈 Performance"),
 		lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("RPS:"),
 		lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Bold(true).Render(rps),
 		lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("Flow:"),
 		lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Bold(true).Render(tput),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("Wire:"),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Bold(true).Render(wireTput),
 		lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("Data:"),
 		lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Bold(true).Render(totalData),
 		sparklineStyle.Render(spark))
@@ -153,23 +235,17 @@ func (m *DashModel) View() string {
 	box1 := dashBoxStyle.Copy().BorderForeground(purpleColor).Width(24).Render(box1Content)
 
 	// BOX 2: Latency
-	p50 := fmtDuration(m.report.P50)
-	p90 := fmtDuration(m.report.P90)
-	p99 := fmtDuration(m.report.P99)
-	maxLat := fmtDuration(m.report.Max)
-
-	box2Content := fmt.Sprintf("%s\n%s %s\n%s %s\n%s %s\n%s %s",
-		lipgloss.NewStyle().Foreground(orangeColor).Bold(true).Render("⏱️  Latency"),
-		lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("P50:"),
-		lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Bold(true).Render(p50),
-		lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("P90:"),
-		lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Bold(true).Render(p90),
-		lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("P99:"),
-		lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Bold(true).Render(p99),
-		lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render("Max:"),
-		lipgloss.NewStyle().Foreground(yellowColor).Bold(true).Render(maxLat))
-
-	box2 := dashBoxStyle.Copy().BorderForeground(orangeColor).Width(24).Render(box2Content)
+	label := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	value := lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Bold(true)
+	var box2Content strings.Builder
+	box2Content.WriteString(lipgloss.NewStyle().Foreground(orangeColor).Bold(true).Render("⏱️  Latency"))
+	for _, p := range m.report.LatencyPercentiles() {
+		fmt.Fprintf(&box2Content, "\n%s %s", label.Render(strings.ToUpper(p.Label())+":"), value.Render(fmtDuration(p.Value)))
+	}
+	fmt.Fprintf(&box2Content, "\n%s %s", label.Render("Max:"),
+		lipgloss.NewStyle().Foreground(yellowColor).Bold(true).Render(fmtDuration(m.report.Max)))
+
+	box2 := dashBoxStyle.Copy().BorderForeground(orangeColor).Width(24).Render(box2Content.String())
 
 	// BOX 3: Results
 	totalReqs := m.report.SuccessCount + m.report.FailureCount
@@ -206,6 +282,17 @@ func (m *DashModel) View() string {
 	s.WriteString(row1)
 	s.WriteString("\n\n")
 
+	// ═══════════════════════════════════════════════════════════════
+	// STEPS SECTION (chained scenarios only)
+	// ═══════════════════════════════════════════════════════════════
+
+	if len(m.report.Steps) > 0 {
+		s.WriteString(lipgloss.NewStyle().Foreground(primaryColor).Bold(true).Render("🔗 Steps"))
+		s.WriteString("\n")
+		s.WriteString(renderStepTable(m.report.Steps))
+		s.WriteString("\n\n")
+	}
+
 	// ═══════════════════════════════════════════════════════════════
 	// STATUS CODES SECTION (Bar Chart Style)
 	// ═══════════════════════════════════════════════════════════════
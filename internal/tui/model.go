@@ -7,8 +7,8 @@ import (
 	"strings"
 	"time"
 
-	"github.com/Amr-9/sayl/internal/attacker"
-	"github.com/Amr-9/sayl/internal/stats"
+	"github.com/Amr-9/sayl/internal/runner"
+	"github.com/Amr-9/sayl/pkg/config"
 	"github.com/Amr-9/sayl/pkg/models"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -26,19 +26,25 @@ type MainModel struct {
 	state    State
 	config   models.Config
 	report   models.Report
-	results  chan models.Result
-	drainDone chan struct{} // closed by processResults when the channel is fully drained
 	quitting bool
 
+	// ctx is cancelled on SIGINT/SIGTERM. Each run gets its own child context
+	// so Ctrl+C can stop the test without tearing down the program.
+	ctx       context.Context
+	runCtx    context.Context
+	runCancel context.CancelFunc
+	stopping  bool // Ctrl+C pressed; waiting for in-flight requests to drain
+
 	// Phases
 	setupModel tea.Model
 	dashModel  tea.Model
 	sumModel   tea.Model
 
-	monitor *stats.Monitor
+	runner    *runner.Runner
+	observers []runner.Observer // attached to every run (e.g. the metrics endpoint)
 }
 
-func NewModel(cfg *models.Config, startRunning bool) MainModel {
+func NewModel(ctx context.Context, cfg *models.Config, startRunning bool, observers ...runner.Observer) MainModel {
 	if cfg == nil {
 		cfg = &models.Config{
 			Method: "GET",
@@ -63,23 +69,18 @@ func NewModel(cfg *models.Config, startRunning bool) MainModel {
 		state:      initialState,
 		config:     *cfg,
 		setupModel: NewSetupModel(cfg),
+		ctx:        ctx,
+		observers:  observers,
 	}
+	m.runCtx, m.runCancel = context.WithCancel(ctx)
 
 	if startRunning {
 		// If starting immediately, skip setup and initialize stats/dashboard
-
-		// Recalculate duration from stages if not explicitly set
-		if m.config.Duration == 0 && len(m.config.Stages) > 0 {
-			for _, s := range m.config.Stages {
-				m.config.Duration += s.Duration
-			}
-		}
-
-		m.results = make(chan models.Result, 10000)
-		m.drainDone = make(chan struct{})
-		m.monitor = stats.NewMonitor()
+		// (the runner also derives the duration from stages if not explicitly set)
+		m.runner = runner.New(m.config, m.observers...)
+		m.config = m.runner.Config()
 		// History can be empty or populated from config if we want
-		m.dashModel = NewDashModel(m.config, []string{"Loaded from config/flags"})
+		m.dashModel = NewDashModel(m.config, []string{"Loaded from config/flags"}, m.runner)
 	}
 
 	return m
@@ -89,11 +90,11 @@ func (m MainModel) Init() tea.Cmd {
 	if m.state == StateRunning {
 		return tea.Batch(
 			m.startAttacking(),
-			m.processResults(),
 			m.tick(),
+			m.waitForSignal(),
 		)
 	}
-	return nil
+	return m.waitForSignal()
 }
 
 func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -102,9 +103,29 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if msg.String() == "ctrl+c" {
+			// While a test is running, the first Ctrl+C stops it and keeps the partial
+			// results; a second one quits immediately.
+			if m.state == StateRunning && !m.stopping {
+				m.stopping = true
+				m.runCancel()
+				return m, nil
+			}
+			if m.state == StateRunning {
+				// Quitting before the drain finished: the last snapshot is partial.
+				m.report.Interrupted = true
+			}
 			m.quitting = true
 			return m, tea.Quit
 		}
+	case signalMsg:
+		// Outside a run there is nothing to save; a running test stops through
+		// its context and quits once the results are drained.
+		if m.state != StateRunning {
+			m.quitting = true
+			return m, tea.Quit
+		}
+		m.stopping = true
+		return m, nil
 	}
 
 	switch m.state {
@@ -174,14 +195,12 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 
 				m.state = StateRunning
-				m.results = make(chan models.Result, 10000)
-				m.drainDone = make(chan struct{})
-				m.monitor = stats.NewMonitor()
-				m.dashModel = NewDashModel(m.config, history)
+				m.runner = runner.New(m.config, m.observers...)
+				m.config = m.runner.Config()
+				m.dashModel = NewDashModel(m.config, history, m.runner)
 
 				return m, tea.Batch(
 					m.startAttacking(),
-					m.processResults(),
 					m.tick(),
 				)
 			}
@@ -190,11 +209,7 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.dashModel, cmd = m.dashModel.Update(msg)
 		switch msg.(type) {
 		case tickMsg:
-			report := m.monitor.Snapshot()
-			report.TargetURL = m.config.URL
-			report.Method = m.config.Method
-			report.Duration = m.config.Duration
-			report.Concurrency = m.config.Concurrency
+			report := m.runner.Snapshot()
 			m.report = report
 			// Explicitly update dashboard with proper stats
 			m.dashModel, _ = m.dashModel.Update(report)
@@ -202,13 +217,13 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, m.tick()
 		case finishedMsg:
 			m.state = StateSummary
-			m.state = StateSummary
-			m.report = m.monitor.Snapshot()
-			m.report.TargetURL = m.config.URL
-			m.report.Method = m.config.Method
-			m.report.Duration = m.config.Duration
-			m.report.Concurrency = m.config.Concurrency
+			m.report = m.runner.FinalReport()
+			m.report.Config = config.RedactedYAML(&m.config)
 			m.sumModel = NewSummaryModel(m.report)
+			if m.report.Interrupted {
+				// Quit so main can print and save the partial report right away.
+				return m, tea.Quit
+			}
 		}
 	}
 
@@ -217,6 +232,9 @@ func (m MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 type finishedMsg struct{}
 
+// signalMsg is sent when the program context is cancelled by SIGINT/SIGTERM.
+type signalMsg struct{}
+
 type tickMsg time.Time
 
 func (m MainModel) tick() tea.Cmd {
@@ -227,27 +245,17 @@ func (m MainModel) tick() tea.Cmd {
 
 func (m MainModel) startAttacking() tea.Cmd {
 	return func() tea.Msg {
-		engine := attacker.NewEngine()
-		ctx, cancel := context.WithTimeout(context.Background(), m.config.Duration)
-		defer cancel()
-
-		engine.Attack(ctx, m.config, m.results)
-		// Attack() has returned and closed m.results. Wait for processResults to
-		// drain every buffered result before we signal the UI to switch to summary.
-		<-m.drainDone
+		// Run returns only after every buffered result has been recorded,
+		// so the summary always reflects the complete run.
+		m.runner.Run(m.runCtx)
 		return finishedMsg{}
 	}
 }
 
-func (m MainModel) processResults() tea.Cmd {
+func (m MainModel) waitForSignal() tea.Cmd {
 	return func() tea.Msg {
-		// Defer ensures drainDone is always closed even if a panic occurs.
-		defer close(m.drainDone)
-		for res := range m.results {
-			isSuccess := m.config.SuccessCodes[res.Status] && res.Error == nil
-			m.monitor.Add(res, isSuccess)
-		}
-		return nil
+		<-m.ctx.Done()
+		return signalMsg{}
 	}
 }
 
@@ -260,6 +268,9 @@ func (m MainModel) View() string {
 	case StateSetup:
 		return m.setupModel.View()
 	case StateRunning:
+		if m.stopping {
+			return m.dashModel.View() + "\n" + warnText.Render("⏹  Stopping — waiting for in-flight requests to finish...")
+		}
 		return m.dashModel.View()
 	case StateSummary:
 		return m.sumModel.View()
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/Amr-9/sayl/pkg/models"
 	tea "github.com/charmbracelet/bubbletea"
@@ -75,6 +76,13 @@ func (m *SummaryModel) View() string {
 		Foreground(accentColor).
 		Bold(true).
 		Render("✨ TEST COMPLETED SUCCESSFULLY ✨")
+	if m.report.Interrupted {
+		completeBanner = warnText.Bold(true).
+			Render(fmt.Sprintf("⚠ TEST INTERRUPTED AFTER %s — PARTIAL RESULTS ⚠", m.report.Elapsed.Round(time.Second)))
+	} else if failed := failedThresholds(m.report.Thresholds); failed > 0 {
+		completeBanner = errText.Bold(true).
+			Render(fmt.Sprintf("✖ TEST COMPLETED — %d OF %d THRESHOLDS FAILED ✖", failed, len(m.report.Thresholds)))
+	}
 	s.WriteString(lipgloss.NewStyle().Align(lipgloss.Center).Render(completeBanner))
 	s.WriteString("\n\n")
 
@@ -95,7 +103,7 @@ func (m *SummaryModel) View() string {
 	}
 
 	// Box 1: Traffic Summary
-	trafficContent := fmt.Sprintf("%s\n\n%s  %s\n%s  %s\n%s  %s\n%s  %s",
+	trafficContent := fmt.Sprintf("%s\n\n%s  %s\n%s  %s\n%s  %s\n%s  %s\n%s  %s",
 		lipgloss.NewStyle().Foreground(purpleColor).Bold(true).Render("🚀 Traffic Summary"),
 		sumLabelStyle.Width(16).Render("Total Requests:"),
 		sumValueStyle.Render(fmt.Sprintf("%d", m.report.TotalRequests)),
@@ -104,7 +112,9 @@ func (m *SummaryModel) View() string {
 		sumLabelStyle.Width(16).Render("Total Data:"),
 		sumValueStyle.Render(formatBytes(m.report.TotalBytes)),
 		sumLabelStyle.Width(16).Render("Throughput:"),
-		sumValueStyle.Render(formatThroughput(m.report.TotalBytes, m.report.Duration.Seconds())))
+		sumValueStyle.Render(formatThroughput(m.report.TotalBytes, m.report.Duration.Seconds())),
+		sumLabelStyle.Width(16).Render("Wire Data:"),
+		sumValueStyle.Render(formatBytes(m.report.TotalWireBytes)))
 
 	box1 := sumBoxStyle.Copy().BorderForeground(purpleColor).Width(36).Render(trafficContent)
 
@@ -137,26 +147,31 @@ func (m *SummaryModel) View() string {
 
 	latencyBox := sumBoxStyle.Copy().BorderForeground(orangeColor).Width(74)
 
-	latencies := []struct {
+	type latency struct {
 		name  string
 		value string
-	}{
-		{"Min", fmtDuration(m.report.Min)},
-		{"P50", fmtDuration(m.report.P50)},
-		{"P75", fmtDuration(m.report.P75)},
-		{"P90", fmtDuration(m.report.P90)},
-		{"P95", fmtDuration(m.report.P95)},
-		{"P99", fmtDuration(m.report.P99)},
-		{"Max", fmtDuration(m.report.Max)},
 	}
+	latencies := []latency{{"Min", fmtDuration(m.report.Min)}}
+	for _, p := range m.report.LatencyPercentiles() {
+		latencies = append(latencies, latency{strings.ToUpper(p.Label()), fmtDuration(p.Value)})
+	}
+	latencies = append(latencies,
+		latency{"Max", fmtDuration(m.report.Max)},
+		latency{"Mean", fmtDuration(m.report.Mean)},
+		latency{"StdDev", fmtDuration(m.report.StdDev)},
+	)
 
-	// Create latency grid (2 rows)
+	// Create latency grid, three entries per row
 	var latencyContent strings.Builder
 	for i, lat := range latencies {
 		latencyContent.WriteString(fmt.Sprintf("%s %s",
-			sumLabelStyle.Width(5).Render(lat.name+":"),
-			sumValueStyle.Width(12).Render(lat.value)))
-		if i < len(latencies)-1 {
+			sumLabelStyle.Width(8).Render(lat.name+":"),
+			sumValueStyle.Width(10).Render(lat.value)))
+		switch {
+		case i == len(latencies)-1:
+		case i%3 == 2:
+			latencyContent.WriteString("\n")
+		default:
 			latencyContent.WriteString("  │  ")
 		}
 	}
@@ -164,6 +179,98 @@ func (m *SummaryModel) View() string {
 	s.WriteString(latencyBox.Render(latencyContent.String()))
 	s.WriteString("\n\n")
 
+	// ═══════════════════════════════════════════════════════════════
+	// STEPS (chained scenarios only)
+	// ═══════════════════════════════════════════════════════════════
+
+	if len(m.report.Steps) > 0 {
+		s.WriteString(lipgloss.NewStyle().Foreground(primaryColor).Bold(true).Render("🔗 Steps"))
+		s.WriteString("\n")
+		s.WriteString(sumBoxStyle.Copy().BorderForeground(primaryColor).Width(74).Render(renderStepTable(m.report.Steps)))
+		s.WriteString("\n\n")
+	}
+
+	// ═══════════════════════════════════════════════════════════════
+	// THRESHOLDS (pass/fail criteria)
+	// ═══════════════════════════════════════════════════════════════
+
+	if len(m.report.Thresholds) > 0 {
+		borderColor := accentColor
+		if failedThresholds(m.report.Thresholds) > 0 {
+			borderColor = lipgloss.Color("#FF4444")
+		}
+		s.WriteString(lipgloss.NewStyle().Foreground(borderColor).Bold(true).Render("🎯 Thresholds"))
+		s.WriteString("\n")
+
+		var thContent strings.Builder
+		for i, th := range m.report.Thresholds {
+			mark := successText.Bold(true).Render("✓ PASS")
+			if !th.Passed {
+				mark = errText.Bold(true).Render("✗ FAIL")
+			}
+			thContent.WriteString(fmt.Sprintf("%s  %s %s",
+				mark,
+				sumValueStyle.Width(36).Render(th.Expr),
+				sumLabelStyle.Render("actual "+th.Actual)))
+			if i < len(m.report.Thresholds)-1 {
+				thContent.WriteString("\n")
+			}
+		}
+
+		s.WriteString(sumBoxStyle.Copy().BorderForeground(borderColor).Width(74).Render(thContent.String()))
+		s.WriteString("\n\n")
+	}
+
+	// ═══════════════════════════════════════════════════════════════
+	// MANUAL CONTROL (pause, rate changes, stage skips)
+	// ═══════════════════════════════════════════════════════════════
+
+	if len(m.report.Annotations) > 0 {
+		s.WriteString(lipgloss.NewStyle().Foreground(yellowColor).Bold(true).Render("🎛️  Manual Control"))
+		s.WriteString("\n")
+
+		var annContent strings.Builder
+		for i, a := range m.report.Annotations {
+			annContent.WriteString(fmt.Sprintf("%s  %s",
+				sumLabelStyle.Width(8).Render(fmt.Sprintf("%ds", a.Second)),
+				sumValueStyle.Render(a.Label)))
+			if i < len(m.report.Annotations)-1 {
+				annContent.WriteString("\n")
+			}
+		}
+
+		s.WriteString(sumBoxStyle.Copy().BorderForeground(yellowColor).Width(74).Render(annContent.String()))
+		s.WriteString("\n\n")
+	}
+
+	// ═══════════════════════════════════════════════════════════════
+	// STREAM METRICS (SSE steps only)
+	// ═══════════════════════════════════════════════════════════════
+
+	if st := m.report.Stream; st != nil {
+		s.WriteString(lipgloss.NewStyle().Foreground(purpleColor).Bold(true).Render("📡 Stream Metrics (SSE)"))
+		s.WriteString("\n")
+
+		streamContent := fmt.Sprintf("%s %s   %s %s   %s %s\n%s %s / %s / %s\n%s %s / %s / %s",
+			sumLabelStyle.Render("Streams:"),
+			sumValueStyle.Render(fmt.Sprintf("%d", st.Streams)),
+			sumLabelStyle.Render("Events:"),
+			sumValueStyle.Render(fmt.Sprintf("%d", st.Events)),
+			sumLabelStyle.Render("Events/s:"),
+			sumValueStyle.Render(fmt.Sprintf("%.1f", st.EventsPerSec)),
+			sumLabelStyle.Width(22).Render("First event P50/P99/Max:"),
+			sumValueStyle.Render(fmtDuration(st.TTFEP50)),
+			sumValueStyle.Render(fmtDuration(st.TTFEP99)),
+			sumValueStyle.Render(fmtDuration(st.TTFEMax)),
+			sumLabelStyle.Width(22).Render("Event gap P50/P99/Max:"),
+			sumValueStyle.Render(fmtDuration(st.GapP50)),
+			sumValueStyle.Render(fmtDuration(st.GapP99)),
+			sumValueStyle.Render(fmtDuration(st.GapMax)))
+
+		s.WriteString(sumBoxStyle.Copy().BorderForeground(purpleColor).Width(74).Render(streamContent))
+		s.WriteString("\n\n")
+	}
+
 	// ═══════════════════════════════════════════════════════════════
 	// STATUS CODES BAR CHART
 	// ═══════════════════════════════════════════════════════════════
@@ -275,22 +382,31 @@ func (m *SummaryModel) View() string {
 		s.WriteString("\n")
 
 		var errContent strings.Builder
-		i := 0
-		for errStr, count := range m.report.Errors {
+		classes := make([]string, 0, len(m.report.Errors))
+		for class := range m.report.Errors {
+			classes = append(classes, class)
+		}
+		sort.Slice(classes, func(i, j int) bool {
+			return m.report.Errors[classes[i]] > m.report.Errors[classes[j]]
+		})
+		for i, class := range classes {
 			if i >= 5 {
-				errContent.WriteString(fmt.Sprintf("  ... and %d more error types\n", len(m.report.Errors)-5))
+				errContent.WriteString(fmt.Sprintf("  ... and %d more error types\n", len(classes)-5))
 				break
 			}
-			i++
-
-			cleanErr := errStr
-			if len(cleanErr) > 55 {
-				cleanErr = cleanErr[:52] + "..."
-			}
 
 			errContent.WriteString(fmt.Sprintf("  %s  %s\n",
-				sumLabelStyle.Width(55).Render(cleanErr),
-				errText.Bold(true).Render(fmt.Sprintf("×%d", count))))
+				sumLabelStyle.Width(55).Render(class),
+				errText.Bold(true).Render(fmt.Sprintf("×%d", m.report.Errors[class]))))
+
+			// The first sample message shows what is behind the class.
+			if samples := m.report.ErrorSamples[class]; len(samples) > 0 {
+				sample := samples[0]
+				if len(sample) > 62 {
+					sample = sample[:59] + "..."
+				}
+				errContent.WriteString("    " + sumLabelStyle.Render(sample) + "\n")
+			}
 		}
 
 		s.WriteString(sumBoxStyle.Copy().BorderForeground(lipgloss.Color("#FF4444")).Width(74).Render(errContent.String()))
@@ -309,3 +425,14 @@ func (m *SummaryModel) View() string {
 
 	return s.String()
 }
+
+// failedThresholds counts the thresholds that did not pass.
+func failedThresholds(results []models.ThresholdResult) int {
+	failed := 0
+	for _, th := range results {
+		if !th.Passed {
+			failed++
+		}
+	}
+	return failed
+}
@@ -2,7 +2,11 @@ package tui
 
 import (
 	"fmt"
+	"strings"
 	"time"
+
+	"github.com/Amr-9/sayl/pkg/models"
+	"github.com/charmbracelet/lipgloss"
 )
 
 func fmtDuration(d time.Duration) string {
@@ -70,3 +74,54 @@ func renderSparkline(values []int) string {
 	}
 	return sb
 }
+
+// renderStepTable renders one row per scenario step: requests, success rate,
+// latency percentiles and the most frequent error. Used by the dashboard and the summary.
+func renderStepTable(steps []models.StepStats) string {
+	label := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	value := lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+
+	var b strings.Builder
+	b.WriteString(label.Render(fmt.Sprintf("  %-18s %8s %8s %9s %9s %9s", "Step", "Reqs", "Success", "P50", "P95", "P99")))
+	for _, st := range steps {
+		rate := successText
+		if st.SuccessRate < 99 {
+			rate = warnText
+		}
+		if st.SuccessRate < 90 {
+			rate = errText
+		}
+		name := st.Name
+		if len([]rune(name)) > 18 {
+			name = string([]rune(name)[:17]) + "…"
+		}
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("  %s %s %s %s %s %s",
+			value.Bold(true).Render(fmt.Sprintf("%-18s", name)),
+			value.Render(fmt.Sprintf("%8d", st.Requests)),
+			rate.Render(fmt.Sprintf("%7.1f%%", st.SuccessRate)),
+			value.Render(fmt.Sprintf("%9s", fmtDuration(st.P50))),
+			value.Render(fmt.Sprintf("%9s", fmtDuration(st.P95))),
+			value.Render(fmt.Sprintf("%9s", fmtDuration(st.P99)))))
+		if top, n := topError(st.Errors); n > 0 {
+			if len(top) > 50 {
+				top = top[:47] + "..."
+			}
+			b.WriteString("\n")
+			b.WriteString(errText.Render(fmt.Sprintf("    ↳ %s ×%d", top, n)))
+		}
+	}
+	return b.String()
+}
+
+// topError returns the most frequent error message and its count.
+func topError(errs map[string]int) (string, int) {
+	var top string
+	var n int
+	for msg, count := range errs {
+		if count > n || (count == n && msg < top) {
+			top, n = msg, count
+		}
+	}
+	return top, n
+}
@@ -0,0 +1,155 @@
+package web
+
+import (
+	"sort"
+	"time"
+)
+
+// seriesWindow is the number of time series points sent to the browser, so the
+// frames of a long run stay small.
+const seriesWindow = 300
+
+// frame is the snapshot pushed to the browser. Latencies are in milliseconds and
+// the time series keeps only what the live charts draw.
+type frame struct {
+	Target      string  `json:"target"`
+	Method      string  `json:"method"`
+	Elapsed     float64 `json:"elapsed"`  // Test time in seconds, without pauses
+	Duration    float64 `json:"duration"` // Configured duration in seconds
+	InFlight    int64   `json:"in_flight"`
+	TargetRate  float64 `json:"target_rate"`
+	CircuitOpen bool    `json:"circuit_open"`
+	Paused      bool    `json:"paused"`
+
+	Requests    int64   `json:"requests"`
+	Success     int64   `json:"success"`
+	Failures    int64   `json:"failures"`
+	Assertions  int64   `json:"assertion_failures"`
+	SuccessRate float64 `json:"success_rate"`
+	RPS         float64 `json:"rps"`         // Average over the run
+	CurrentRPS  float64 `json:"current_rps"` // Over the last complete second
+	Mean        float64 `json:"mean"`
+	Max         float64 `json:"max"`
+
+	Percentiles []string   `json:"percentiles"` // Labels of Latencies and of point.Latencies
+	Latencies   []float64  `json:"latencies"`
+	StatusCodes []countRow `json:"status_codes"`
+	Errors      []errorRow `json:"errors"`
+	Steps       []stepRow  `json:"steps"`
+	Series      []point    `json:"series"`
+}
+
+type countRow struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type errorRow struct {
+	Class  string `json:"class"`
+	Count  int    `json:"count"`
+	Sample string `json:"sample,omitempty"`
+}
+
+type stepRow struct {
+	Name        string  `json:"name"`
+	Requests    int64   `json:"requests"`
+	Failures    int64   `json:"failures"`
+	SuccessRate float64 `json:"success_rate"`
+	RPS         float64 `json:"rps"`
+	P50         float64 `json:"p50"`
+	P95         float64 `json:"p95"`
+	P99         float64 `json:"p99"`
+}
+
+type point struct {
+	Second    int       `json:"t"`
+	RPS       float64   `json:"rps"`
+	Failures  int64     `json:"failures"`
+	Latencies []float64 `json:"latencies"`
+}
+
+// newFrame takes a snapshot of src.
+func newFrame(src Source) frame {
+	rep := src.Snapshot()
+	f := frame{
+		Target:      rep.TargetURL,
+		Method:      rep.Method,
+		Elapsed:     src.Elapsed().Seconds(),
+		Duration:    rep.Duration.Seconds(),
+		InFlight:    src.InFlight(),
+		TargetRate:  src.TargetRate(),
+		CircuitOpen: src.CircuitOpen(),
+		Paused:      src.Paused(),
+		Requests:    rep.TotalRequests,
+		Success:     rep.SuccessCount,
+		Failures:    rep.FailureCount,
+		Assertions:  rep.AssertionFailures,
+		SuccessRate: rep.SuccessRate,
+		RPS:         rep.RPS,
+		Mean:        ms(rep.Mean),
+		Max:         ms(rep.Max),
+	}
+
+	for _, p := range rep.LatencyPercentiles() {
+		f.Percentiles = append(f.Percentiles, p.Label())
+		f.Latencies = append(f.Latencies, ms(p.Value))
+	}
+
+	for code, n := range rep.StatusCodes {
+		f.StatusCodes = append(f.StatusCodes, countRow{code, n})
+	}
+	sort.Slice(f.StatusCodes, func(i, j int) bool { return f.StatusCodes[i].Name < f.StatusCodes[j].Name })
+
+	for class, n := range rep.Errors {
+		row := errorRow{Class: class, Count: n}
+		if samples := rep.ErrorSamples[class]; len(samples) > 0 {
+			row.Sample = samples[0]
+		}
+		f.Errors = append(f.Errors, row)
+	}
+	for msg, n := range rep.AssertionErrors {
+		f.Errors = append(f.Errors, errorRow{Class: "assertion", Count: n, Sample: msg})
+	}
+	sort.Slice(f.Errors, func(i, j int) bool {
+		if f.Errors[i].Count != f.Errors[j].Count {
+			return f.Errors[i].Count > f.Errors[j].Count
+		}
+		return f.Errors[i].Class+f.Errors[i].Sample < f.Errors[j].Class+f.Errors[j].Sample
+	})
+
+	for _, st := range rep.Steps {
+		f.Steps = append(f.Steps, stepRow{
+			Name:        st.Name,
+			Requests:    st.Requests,
+			Failures:    st.Failures,
+			SuccessRate: st.SuccessRate,
+			RPS:         st.RPS,
+			P50:         ms(st.P50),
+			P95:         ms(st.P95),
+			P99:         ms(st.P99),
+		})
+	}
+
+	series := rep.TimeSeriesData
+	if len(series) > seriesWindow {
+		series = series[len(series)-seriesWindow:]
+	}
+	for _, sec := range series {
+		width := max(sec.Seconds, rep.BucketSeconds, 1)
+		pt := point{Second: sec.Second, RPS: float64(sec.Requests) / float64(width), Failures: sec.Failures}
+		for _, p := range sec.LatencyPercentiles() {
+			pt.Latencies = append(pt.Latencies, ms(p.Value))
+		}
+		f.Series = append(f.Series, pt)
+	}
+	// The last bucket is still filling.
+	if n := len(f.Series); n >= 2 {
+		f.CurrentRPS = f.Series[n-2].RPS
+	}
+	return f
+}
+
+// ms converts d to milliseconds with microsecond precision.
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,203 @@
+package web
+
+// dashboardTemplate is the live dashboard. It reuses the look of the HTML report
+// and is filled in by the snapshot events; untrusted strings such as error
+// messages are inserted as text, never as markup.
+const dashboardTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Sayl Live Dashboard</title>
+    {{chartjs}}
+    <style>
+{{stylesheet}}
+        .live-status { color: #888; font-size: 0.9rem; margin-top: 8px; }
+        .live-dot { display: inline-block; width: 10px; height: 10px; border-radius: 50%; background: #888; margin-right: 6px; }
+        .live-dot.on { background: #00ff88; box-shadow: 0 0 8px #00ff88; }
+        .live-dot.off { background: #ff4757; }
+        .progress { height: 6px; background: rgba(255,255,255,0.08); border-radius: 3px; margin-top: 12px; overflow: hidden; }
+        .progress div { height: 100%; width: 0; background: linear-gradient(90deg, #00d9ff, #00ff88); transition: width 0.5s; }
+        .hidden { display: none; }
+        td.sample { font-family: monospace; color: #ff6b81; word-break: break-all; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>⚡ Sayl Live Dashboard</h1>
+            <div style="margin-top: 20px; padding: 15px; background: rgba(0,0,0,0.2); border-radius: 10px; display: inline-block; min-width: 50%;">
+                <div style="font-size: 1.2rem; margin-bottom: 5px;">
+                    <span id="method" style="color: #00d9ff; font-weight: bold;"></span>
+                    <span id="target" style="color: #fff;">Waiting for the run to start…</span>
+                </div>
+                <div class="live-status"><span id="dot" class="live-dot"></span><span id="status">Connecting…</span></div>
+                <div class="progress"><div id="progress"></div></div>
+            </div>
+        </div>
+
+        <div id="circuit" class="status-table hidden" style="margin-bottom: 40px; border-color: rgba(255, 71, 87, 0.3);">
+            <h3 style="color: #ff4757;">⛔ Circuit breaker open</h3>
+        </div>
+
+        <div class="summary-grid" id="cards"></div>
+
+        <div class="charts-grid">
+            <div class="chart-container">
+                <h3>📈 Requests per Second</h3>
+                <div class="chart-wrapper"><canvas id="rpsChart"></canvas></div>
+            </div>
+            <div class="chart-container">
+                <h3>⏱️ Latency (ms)</h3>
+                <div class="chart-wrapper"><canvas id="latencyChart"></canvas></div>
+            </div>
+        </div>
+
+        <div class="status-table" style="margin-bottom: 40px;">
+            <h3>📊 Status Codes</h3>
+            <table>
+                <thead><tr><th>Status</th><th>Count</th></tr></thead>
+                <tbody id="statusCodes"></tbody>
+            </table>
+        </div>
+
+        <div id="stepsSection" class="status-table hidden" style="margin-bottom: 40px;">
+            <h3>🧩 Steps</h3>
+            <table>
+                <thead><tr><th>Step</th><th>Requests</th><th>Failures</th><th>Success</th><th>RPS</th><th>P50</th><th>P95</th><th>P99</th></tr></thead>
+                <tbody id="steps"></tbody>
+            </table>
+        </div>
+
+        <div id="errorsSection" class="status-table hidden" style="margin-bottom: 40px; border-color: rgba(255, 71, 87, 0.3);">
+            <h3>❌ Errors</h3>
+            <table>
+                <thead><tr><th>Class</th><th>Count</th><th>Sample</th></tr></thead>
+                <tbody id="errors"></tbody>
+            </table>
+        </div>
+
+        <div class="footer">
+            <p>Updated every second · the final report replaces this page when the run ends</p>
+        </div>
+    </div>
+
+    <script>
+        const latencyColors = ['#00ff88', '#00d9ff', '#ffbb00', '#ff6b6b', '#ff00ff', '#b388ff', '#ffffff'];
+        const grid = { color: 'rgba(255,255,255,0.05)' };
+
+        const rpsChart = new Chart(document.getElementById('rpsChart'), {
+            type: 'line',
+            data: { labels: [], datasets: [
+                { label: 'RPS', data: [], borderColor: '#00d9ff', backgroundColor: 'rgba(0,217,255,0.1)', fill: true, tension: 0.4, pointRadius: 0 },
+                { label: 'Failures', data: [], borderColor: '#ff4757', tension: 0.4, pointRadius: 0 }
+            ] },
+            options: { responsive: true, maintainAspectRatio: false, animation: false,
+                plugins: { legend: { position: 'top', labels: { usePointStyle: true } } },
+                scales: { y: { beginAtZero: true, grid }, x: { grid } } }
+        });
+        const latencyChart = new Chart(document.getElementById('latencyChart'), {
+            type: 'line',
+            data: { labels: [], datasets: [] },
+            options: { responsive: true, maintainAspectRatio: false, animation: false,
+                plugins: { legend: { position: 'top', labels: { usePointStyle: true } } },
+                scales: { y: { beginAtZero: true, grid }, x: { grid } } }
+        });
+
+        const $ = id => document.getElementById(id);
+        const fmtMs = v => v >= 1000 ? (v / 1000).toFixed(2) + 's' : v.toFixed(v < 10 ? 2 : 1) + 'ms';
+        const fmtClock = s => { s = Math.max(0, Math.floor(s)); return String(Math.floor(s / 60)).padStart(2, '0') + ':' + String(s % 60).padStart(2, '0'); };
+
+        function row(cells, sampleIndex) {
+            const tr = document.createElement('tr');
+            cells.forEach((c, i) => {
+                const td = document.createElement('td');
+                td.textContent = c;
+                if (i === sampleIndex) td.className = 'sample';
+                tr.appendChild(td);
+            });
+            return tr;
+        }
+
+        function fill(id, rows, sampleIndex) {
+            $(id).replaceChildren(...rows.map(r => row(r, sampleIndex)));
+        }
+
+        function card(value, label, color) {
+            const div = document.createElement('div');
+            div.className = 'summary-card';
+            const v = document.createElement('div');
+            v.className = 'value';
+            v.textContent = value;
+            if (color) v.style.color = color;
+            const l = document.createElement('div');
+            l.className = 'label';
+            l.textContent = label;
+            div.append(v, l);
+            return div;
+        }
+
+        function render(f) {
+            $('method').textContent = f.method;
+            $('target').textContent = f.target;
+            let status = (f.paused ? 'Paused · ' : 'Running · ') + fmtClock(f.elapsed);
+            if (f.duration > 0) {
+                status += ' / ' + fmtClock(f.duration);
+                $('progress').style.width = Math.min(100, f.elapsed / f.duration * 100) + '%';
+            }
+            status += ' · ' + f.in_flight + ' in flight';
+            if (f.target_rate > 0) status += ' · target ' + f.target_rate.toFixed(0) + ' req/s';
+            $('status').textContent = status;
+            $('circuit').classList.toggle('hidden', !f.circuit_open);
+
+            const cards = [
+                card(f.requests, 'Total Requests'),
+                card(f.success_rate.toFixed(1) + '%', 'Success Rate', f.success_rate < 99 ? '#ff6b81' : null),
+                card(f.current_rps.toFixed(0), 'Current RPS'),
+                card(f.rps.toFixed(0), 'Average RPS'),
+            ];
+            (f.percentiles || []).forEach((p, i) => cards.push(card(fmtMs(f.latencies[i]), p.toUpperCase() + ' Latency')));
+            cards.push(card(fmtMs(f.mean), 'Mean Latency'), card(fmtMs(f.max), 'Max Latency'), card(f.failures, 'Failures', f.failures ? '#ff6b81' : null));
+            if (f.assertion_failures) cards.push(card(f.assertion_failures, 'Assertion Failures', '#ff6b81'));
+            $('cards').replaceChildren(...cards);
+
+            const series = f.series || [];
+            const labels = series.map(p => p.t + 's');
+            rpsChart.data.labels = labels;
+            rpsChart.data.datasets[0].data = series.map(p => p.rps);
+            rpsChart.data.datasets[1].data = series.map(p => p.failures);
+            rpsChart.update('none');
+
+            latencyChart.data.labels = labels;
+            latencyChart.data.datasets = (f.percentiles || []).map((p, i) => ({
+                label: p.toUpperCase(), data: series.map(pt => (pt.latencies || [])[i]),
+                borderColor: latencyColors[i % latencyColors.length], tension: 0.4, pointRadius: 0
+            }));
+            latencyChart.update('none');
+
+            fill('statusCodes', (f.status_codes || []).map(s => [s.name, s.count]));
+
+            const steps = f.steps || [];
+            $('stepsSection').classList.toggle('hidden', steps.length === 0);
+            fill('steps', steps.map(s => [s.name, s.requests, s.failures, s.success_rate.toFixed(1) + '%', s.rps.toFixed(1), fmtMs(s.p50), fmtMs(s.p95), fmtMs(s.p99)]));
+
+            const errors = f.errors || [];
+            $('errorsSection').classList.toggle('hidden', errors.length === 0);
+            fill('errors', errors.map(e => [e.class, e.count, e.sample || '']), 2);
+        }
+
+        const events = new EventSource('events');
+        events.onopen = () => { $('dot').className = 'live-dot on'; };
+        events.onerror = () => { $('dot').className = 'live-dot off'; $('status').textContent = 'Disconnected, retrying…'; };
+        events.addEventListener('snapshot', e => render(JSON.parse(e.data)));
+        events.addEventListener('final', e => {
+            events.close();
+            const html = JSON.parse(e.data).html;
+            document.open();
+            document.write(html);
+            document.close();
+        });
+    </script>
+</body>
+</html>
+`
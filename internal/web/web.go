@@ -0,0 +1,277 @@
+// Package web serves a live dashboard of a run to the browser, as an alternative
+// to the TUI that a whole team can watch. Snapshots are pushed once per second
+// over Server-Sent Events; when the run ends the page is replaced by the final
+// HTML report.
+package web
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Amr-9/sayl/internal/report"
+	"github.com/Amr-9/sayl/internal/runner"
+	"github.com/Amr-9/sayl/pkg/models"
+)
+
+// Source provides the live data of a run. *runner.Runner implements it.
+type Source interface {
+	Snapshot() models.Report
+	InFlight() int64
+	TargetRate() float64
+	CircuitOpen() bool
+	Paused() bool
+	Elapsed() time.Duration
+}
+
+// pushInterval is how often a snapshot is sent to the browsers.
+const pushInterval = time.Second
+
+// finishTimeout bounds how long Finish waits for the browsers to receive the
+// final report before the program goes on to exit.
+const finishTimeout = 5 * time.Second
+
+// Server serves the dashboard at / and the event stream at /events for the run
+// it is attached to.
+type Server struct {
+	listener net.Listener
+	server   *http.Server
+	page     *template.Template
+
+	mu    sync.Mutex
+	src   Source
+	subs  map[*subscriber]struct{}
+	last  []byte // Latest snapshot event, sent to new subscribers first
+	final []byte // Final report event, set by Finish
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// subscriber is one open event stream. Events are sent through a buffer of one,
+// so a slow browser skips snapshots instead of delaying the others.
+type subscriber struct {
+	events chan []byte
+	closed chan struct{} // Closed when the stream ends
+}
+
+// Listen binds addr (e.g. ":8089") and starts serving the dashboard in the
+// background. Until a run is attached, the page waits for data.
+func Listen(addr string) (*Server, error) {
+	page, err := template.New("dashboard").Funcs(template.FuncMap{
+		"chartjs":    report.ChartScript,
+		"stylesheet": report.Stylesheet,
+	}).Parse(dashboardTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dashboard template: %w", err)
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start web dashboard on '%s': %w", addr, err)
+	}
+
+	s := &Server{
+		listener: ln,
+		page:     page,
+		subs:     make(map[*subscriber]struct{}),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handlePage)
+	mux.HandleFunc("/events", s.handleEvents)
+	s.server = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go s.server.Serve(ln)
+	go s.loop()
+	return s, nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Attach makes the server show r. It implements runner.Observer.
+func (s *Server) Attach(r *runner.Runner) {
+	s.SetSource(r)
+}
+
+// SetSource makes the server show src.
+func (s *Server) SetSource(src Source) {
+	s.mu.Lock()
+	s.src = src
+	s.mu.Unlock()
+}
+
+// Finish replaces the dashboard of every open page with the final report. It
+// returns once the browsers have received it, or after finishTimeout.
+func (s *Server) Finish(rep models.Report, opts report.HTMLOptions) error {
+	var html bytes.Buffer
+	if err := report.RenderHTML(&html, rep, opts); err != nil {
+		return fmt.Errorf("failed to render the final report: %w", err)
+	}
+	payload, err := json.Marshal(struct {
+		HTML string `json:"html"`
+	}{html.String()})
+	if err != nil {
+		return fmt.Errorf("failed to encode the final report: %w", err)
+	}
+	event := formatEvent("final", payload)
+
+	s.mu.Lock()
+	s.final = event
+	var pending []chan struct{}
+	for sub := range s.subs {
+		sub.push(event)
+		pending = append(pending, sub.closed)
+	}
+	s.mu.Unlock()
+
+	timeout := time.After(finishTimeout)
+	for _, closed := range pending {
+		select {
+		case <-closed:
+		case <-timeout:
+			return nil
+		}
+	}
+	return nil
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	select {
+	case <-s.stop:
+	default:
+		close(s.stop)
+		<-s.done
+	}
+	return s.server.Close()
+}
+
+// loop pushes a snapshot every pushInterval until Finish or Close.
+func (s *Server) loop() {
+	defer close(s.done)
+	ticker := time.NewTicker(pushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.push()
+		}
+	}
+}
+
+// push sends the current snapshot to every subscriber.
+func (s *Server) push() {
+	s.mu.Lock()
+	src, finished := s.src, s.final != nil
+	s.mu.Unlock()
+	if src == nil || finished {
+		return
+	}
+
+	payload, err := json.Marshal(newFrame(src))
+	if err != nil {
+		return
+	}
+	event := formatEvent("snapshot", payload)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.final != nil {
+		return
+	}
+	s.last = event
+	for sub := range s.subs {
+		sub.push(event)
+	}
+}
+
+// push queues event, replacing a snapshot the browser has not read yet.
+func (sub *subscriber) push(event []byte) {
+	for {
+		select {
+		case sub.events <- event:
+			return
+		default:
+		}
+		select {
+		case <-sub.events:
+		default:
+		}
+	}
+}
+
+func formatEvent(name string, data []byte) []byte {
+	return fmt.Appendf(nil, "event: %s\ndata: %s\n\n", name, data)
+}
+
+func (s *Server) handlePage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	s.page.Execute(w, nil)
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	sub := &subscriber{events: make(chan []byte, 1), closed: make(chan struct{})}
+	s.mu.Lock()
+	final, last := s.final, s.last
+	if final == nil {
+		s.subs[sub] = struct{}{}
+	}
+	s.mu.Unlock()
+
+	if final != nil {
+		w.Write(final)
+		flusher.Flush()
+		return
+	}
+	defer func() {
+		s.mu.Lock()
+		delete(s.subs, sub)
+		s.mu.Unlock()
+		close(sub.closed)
+	}()
+
+	if last != nil {
+		w.Write(last)
+	}
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event := <-sub.events:
+			if _, err := w.Write(event); err != nil {
+				return
+			}
+			flusher.Flush()
+			if bytes.HasPrefix(event, []byte("event: final")) {
+				return
+			}
+		}
+	}
+}
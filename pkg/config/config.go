@@ -3,11 +3,18 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Amr-9/sayl/internal/circuitbreaker"
+	"github.com/Amr-9/sayl/internal/results"
+	"github.com/Amr-9/sayl/internal/threshold"
 	"github.com/Amr-9/sayl/internal/validator"
 	"github.com/Amr-9/sayl/pkg/models"
 	"gopkg.in/yaml.v3"
@@ -21,8 +28,49 @@ type YAMLAssertion struct {
 	Message string `yaml:"message,omitempty"` // Custom error message
 }
 
+// YAMLGraphQL represents a GraphQL operation in YAML format
+type YAMLGraphQL struct {
+	Query         string                 `yaml:"query"`
+	Variables     map[string]interface{} `yaml:"variables,omitempty"`
+	OperationName string                 `yaml:"operation_name,omitempty"`
+}
+
+// YAMLMultipart represents a multipart/form-data body in YAML format
+type YAMLMultipart struct {
+	Fields []struct {
+		Name  string `yaml:"name"`
+		Value string `yaml:"value"`
+	} `yaml:"fields,omitempty"`
+	Files []struct {
+		Field       string `yaml:"field"`
+		Path        string `yaml:"path,omitempty"`
+		Filename    string `yaml:"filename,omitempty"`
+		ContentType string `yaml:"content_type,omitempty"`
+		RandomBytes int64  `yaml:"random_bytes,omitempty"`
+	} `yaml:"files,omitempty"`
+}
+
+// YAMLBodyStream represents a streamed request body in YAML format. It accepts either
+// a plain file path (body_stream: ./big.bin) or a mapping with path/random_bytes/chunked.
+type YAMLBodyStream struct {
+	Path        string `yaml:"path,omitempty"`
+	RandomBytes int64  `yaml:"random_bytes,omitempty"`
+	Chunked     bool   `yaml:"chunked,omitempty"`
+}
+
+// UnmarshalYAML supports the short scalar form of body_stream.
+func (y *YAMLBodyStream) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		y.Path = value.Value
+		return nil
+	}
+	type plain YAMLBodyStream
+	return value.Decode((*plain)(y))
+}
+
 // YAMLConfig represents the structure of the YAML configuration file.
 type YAMLConfig struct {
+	Name   string `yaml:"name,omitempty"` // Test name, used to tag streamed metrics
 	Target struct {
 		URL       string            `yaml:"url"`
 		Method    string            `yaml:"method,omitempty"`
@@ -36,6 +84,13 @@ type YAMLConfig struct {
 		HTTP2     *bool             `yaml:"http2,omitempty"`      // Enable HTTP/2 (default: true for HTTPS)
 		HTTP2Only bool              `yaml:"http2_only,omitempty"` // Force HTTP/2 only
 		H2C       bool              `yaml:"h2c,omitempty"`        // HTTP/2 Cleartext
+		GraphQL   *YAMLGraphQL      `yaml:"graphql,omitempty"`
+		Multipart *YAMLMultipart    `yaml:"multipart,omitempty"`
+
+		BodyStream *YAMLBodyStream `yaml:"body_stream,omitempty"` // Stream the body from a file or generated bytes
+
+		CompressRequest string `yaml:"compress_request,omitempty"` // gzip, br or zstd
+		AcceptEncoding  string `yaml:"accept_encoding,omitempty"`  // Accept-Encoding header (default: gzip)
 	} `yaml:"target"`
 
 	Load struct {
@@ -63,11 +118,56 @@ type YAMLConfig struct {
 		Variables  map[string]string `yaml:"variables,omitempty"`
 		Save       map[string]string `yaml:"save,omitempty"` // Alias for variables
 		Assertions []YAMLAssertion   `yaml:"assertions,omitempty"`
+		GraphQL    *YAMLGraphQL      `yaml:"graphql,omitempty"`
+		Multipart  *YAMLMultipart    `yaml:"multipart,omitempty"`
+		BodyStream *YAMLBodyStream   `yaml:"body_stream,omitempty"`
+
+		// Streaming (SSE)
+		Stream string `yaml:"stream,omitempty"` // "sse"
+		SSE    struct {
+			MaxEvents     int    `yaml:"max_events,omitempty"`
+			UntilEvent    string `yaml:"until_event,omitempty"`
+			UntilContains string `yaml:"until_contains,omitempty"`
+			MaxDuration   string `yaml:"max_duration,omitempty"` // Stop reading after this long, e.g. 5m
+		} `yaml:"sse,omitempty"`
+		EventAssertions []YAMLAssertion   `yaml:"event_assertions,omitempty"`
+		EventExtract    map[string]string `yaml:"event_extract,omitempty"`
 	} `yaml:"steps,omitempty"`
 	Data []struct {
 		Name string `yaml:"name"`
 		Path string `yaml:"path"`
 	} `yaml:"data,omitempty"`
+	Thresholds []string `yaml:"thresholds,omitempty"` // Pass/fail criteria, e.g. "p95 < 250ms"
+	Report     struct {
+		Percentiles []float64 `yaml:"percentiles,omitempty"` // Latency percentiles to report, e.g. [50, 90, 99, 99.9]
+		EmbedJSON   bool      `yaml:"embed_json,omitempty"`  // Bundle the report JSON inside report.html
+	} `yaml:"report,omitempty"`
+	Output struct {
+		Dir           string `yaml:"dir,omitempty"`            // Parent of the run folders (default "runs")
+		Results       string `yaml:"results,omitempty"`        // Raw per-request results file
+		ResultsFormat string `yaml:"results_format,omitempty"` // jsonl, csv or bin
+		OTLP          *struct {
+			Endpoint   string            `yaml:"endpoint"`
+			Protocol   string            `yaml:"protocol,omitempty"` // grpc (default) or http
+			Insecure   bool              `yaml:"insecure,omitempty"`
+			Headers    map[string]string `yaml:"headers,omitempty"`
+			Interval   string            `yaml:"interval,omitempty"` // Metrics export interval (default 10s)
+			Traces     bool              `yaml:"traces,omitempty"`
+			SampleRate float64           `yaml:"sample_rate,omitempty"` // Fraction of iterations traced (default 1)
+		} `yaml:"otlp,omitempty"`
+		InfluxDB *struct {
+			URL        string            `yaml:"url"` // Write URL, or udp://host:port
+			Token      string            `yaml:"token,omitempty"`
+			Tags       map[string]string `yaml:"tags,omitempty"`
+			SampleRate float64           `yaml:"sample_rate,omitempty"` // Fraction of requests written as points
+		} `yaml:"influxdb,omitempty"`
+		StatsD *struct {
+			Address    string            `yaml:"address"`
+			Prefix     string            `yaml:"prefix,omitempty"`
+			Tags       map[string]string `yaml:"tags,omitempty"`
+			SampleRate float64           `yaml:"sample_rate,omitempty"` // Fraction of requests sent as timings
+		} `yaml:"statsd,omitempty"`
+	} `yaml:"output,omitempty"`
 }
 
 // LoadConfig reads a YAML file and converts it into a models.Config.
@@ -101,6 +201,7 @@ func LoadConfig(path string) (*models.Config, error) {
 	}
 
 	cfg := &models.Config{
+		Name:        yamlCfg.Name,
 		URL:         yamlCfg.Target.URL,
 		Method:      yamlCfg.Target.Method,
 		Headers:     yamlCfg.Target.Headers,
@@ -111,6 +212,9 @@ func LoadConfig(path string) (*models.Config, error) {
 		HTTP2:       http2Enabled,
 		HTTP2Only:   yamlCfg.Target.HTTP2Only,
 		H2C:         yamlCfg.Target.H2C,
+
+		CompressRequest: yamlCfg.Target.CompressRequest,
+		AcceptEncoding:  yamlCfg.Target.AcceptEncoding,
 	}
 
 	// Handle Steps
@@ -125,9 +229,17 @@ func LoadConfig(path string) (*models.Config, error) {
 				vars[k] = v
 			}
 
-			// Handle Step Body (Direct vs File vs JSON)
+			// Handle Step Body (GraphQL vs File vs Direct vs JSON)
 			var bodyData []byte
-			if s.BodyFile != "" {
+			var gql *models.GraphQLRequest
+			if s.GraphQL != nil {
+				gql = s.GraphQL.toModel()
+				b, err := buildGraphQLBody(gql)
+				if err != nil {
+					return nil, fmt.Errorf("failed to build step '%s' graphql body: %w", s.Name, err)
+				}
+				bodyData = b
+			} else if s.BodyFile != "" {
 				b, err := os.ReadFile(s.BodyFile)
 				if err != nil {
 					return nil, fmt.Errorf("failed to read step body file '%s': %w", s.BodyFile, err)
@@ -143,30 +255,12 @@ func LoadConfig(path string) (*models.Config, error) {
 				bodyData = b
 			}
 
-			// Convert YAML assertions to model assertions
-			var assertions []models.Assertion
-			for _, a := range s.Assertions {
-				assertion := models.Assertion{
-					Type:    models.AssertionType(a.Type),
-					Value:   a.Value,
-					Path:    a.Path,
-					Message: a.Message,
-				}
-				// Default to "contains" if type not specified
-				if assertion.Type == "" {
-					assertion.Type = models.AssertContains
-				}
-				assertions = append(assertions, assertion)
-			}
-
-			// Pre-compile regex patterns for performance
-			if len(assertions) > 0 {
-				if err := validator.CompileAssertions(assertions); err != nil {
-					return nil, fmt.Errorf("step '%s': %w", s.Name, err)
-				}
+			assertions, err := convertAssertions(s.Assertions)
+			if err != nil {
+				return nil, fmt.Errorf("step '%s': %w", s.Name, err)
 			}
 
-			cfg.Steps = append(cfg.Steps, models.Step{
+			step := models.Step{
 				Name:       s.Name,
 				URL:        s.URL,
 				Method:     s.Method,
@@ -175,7 +269,47 @@ func LoadConfig(path string) (*models.Config, error) {
 				Extract:    s.Extract,
 				Variables:  vars,
 				Assertions: assertions,
-			})
+				GraphQL:    gql,
+			}
+			if gql != nil {
+				step.Method, step.Headers = graphQLDefaults(step.Method, step.Headers)
+			}
+			if s.Multipart != nil {
+				step.Multipart, err = s.Multipart.toModel()
+				if err != nil {
+					return nil, fmt.Errorf("step '%s': %w", s.Name, err)
+				}
+			}
+			if s.BodyStream != nil {
+				step.BodyStream, err = s.BodyStream.toModel()
+				if err != nil {
+					return nil, fmt.Errorf("step '%s': %w", s.Name, err)
+				}
+			}
+
+			// Handle SSE streaming options
+			if s.Stream != "" {
+				step.Stream = s.Stream
+				step.SSE = &models.SSEOptions{
+					MaxEvents:     s.SSE.MaxEvents,
+					UntilEvent:    s.SSE.UntilEvent,
+					UntilContains: s.SSE.UntilContains,
+				}
+				if s.SSE.MaxDuration != "" {
+					d, err := time.ParseDuration(s.SSE.MaxDuration)
+					if err != nil || d <= 0 {
+						return nil, fmt.Errorf("step '%s': invalid sse.max_duration '%s' (use a positive duration such as 5m)", s.Name, s.SSE.MaxDuration)
+					}
+					step.SSE.MaxDuration = d
+				}
+				step.EventExtract = s.EventExtract
+				step.EventAssertions, err = convertAssertions(s.EventAssertions)
+				if err != nil {
+					return nil, fmt.Errorf("step '%s' event_assertions: %w", s.Name, err)
+				}
+			}
+
+			cfg.Steps = append(cfg.Steps, step)
 		}
 	}
 
@@ -221,8 +355,16 @@ func LoadConfig(path string) (*models.Config, error) {
 		}
 	}
 
-	// Handle Body (Direct vs File vs JSON)
-	if yamlCfg.Target.BodyFile != "" {
+	// Handle Body (GraphQL vs File vs Direct vs JSON)
+	if yamlCfg.Target.GraphQL != nil {
+		cfg.GraphQL = yamlCfg.Target.GraphQL.toModel()
+		bodyData, err := buildGraphQLBody(cfg.GraphQL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build graphql body: %w", err)
+		}
+		cfg.Body = bodyData
+		cfg.Method, cfg.Headers = graphQLDefaults(cfg.Method, cfg.Headers)
+	} else if yamlCfg.Target.BodyFile != "" {
 		bodyData, err := os.ReadFile(yamlCfg.Target.BodyFile)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read body file '%s': %w", yamlCfg.Target.BodyFile, err)
@@ -238,6 +380,24 @@ func LoadConfig(path string) (*models.Config, error) {
 		cfg.Body = bodyData
 	}
 
+	// Handle Multipart Body
+	if yamlCfg.Target.Multipart != nil {
+		mp, err := yamlCfg.Target.Multipart.toModel()
+		if err != nil {
+			return nil, err
+		}
+		cfg.Multipart = mp
+	}
+
+	// Handle Streamed Body
+	if yamlCfg.Target.BodyStream != nil {
+		bs, err := yamlCfg.Target.BodyStream.toModel()
+		if err != nil {
+			return nil, err
+		}
+		cfg.BodyStream = bs
+	}
+
 	// Handle Success Codes
 	if len(yamlCfg.Load.SuccessCodes) > 0 {
 		cfg.SuccessCodes = make(map[int]bool)
@@ -262,9 +422,165 @@ func LoadConfig(path string) (*models.Config, error) {
 		}
 	}
 
+	// Handle Thresholds
+	for _, expr := range yamlCfg.Thresholds {
+		th, err := threshold.Parse(expr)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Thresholds = append(cfg.Thresholds, th)
+	}
+
+	cfg.Percentiles = yamlCfg.Report.Percentiles
+	cfg.EmbedJSON = yamlCfg.Report.EmbedJSON
+
+	// Handle Output
+	cfg.Output.Dir = yamlCfg.Output.Dir
+	cfg.Output.Results = yamlCfg.Output.Results
+	cfg.Output.ResultsFormat = strings.ToLower(yamlCfg.Output.ResultsFormat)
+	if o := yamlCfg.Output.OTLP; o != nil {
+		cfg.Output.OTLP = &models.OTLPConfig{
+			Endpoint:   o.Endpoint,
+			Protocol:   strings.ToLower(o.Protocol),
+			Insecure:   o.Insecure,
+			Headers:    o.Headers,
+			Traces:     o.Traces,
+			SampleRate: o.SampleRate,
+		}
+		if o.Interval != "" {
+			d, err := time.ParseDuration(o.Interval)
+			if err != nil {
+				return nil, fmt.Errorf("invalid output.otlp.interval: %w", err)
+			}
+			cfg.Output.OTLP.Interval = d
+		}
+	}
+	if i := yamlCfg.Output.InfluxDB; i != nil {
+		cfg.Output.InfluxDB = &models.InfluxDBConfig{
+			URL:        i.URL,
+			Token:      i.Token,
+			Tags:       i.Tags,
+			SampleRate: i.SampleRate,
+		}
+	}
+	if sd := yamlCfg.Output.StatsD; sd != nil {
+		cfg.Output.StatsD = &models.StatsDConfig{
+			Address:    sd.Address,
+			Prefix:     sd.Prefix,
+			Tags:       sd.Tags,
+			SampleRate: sd.SampleRate,
+		}
+	}
+
 	return cfg, nil
 }
 
+func (g *YAMLGraphQL) toModel() *models.GraphQLRequest {
+	return &models.GraphQLRequest{
+		Query:         g.Query,
+		Variables:     g.Variables,
+		OperationName: g.OperationName,
+	}
+}
+
+// toModel converts a YAML multipart body and checks that every file part can be read.
+func (y *YAMLMultipart) toModel() (*models.MultipartBody, error) {
+	mb := &models.MultipartBody{}
+	for _, f := range y.Fields {
+		mb.Fields = append(mb.Fields, models.MultipartField{Name: f.Name, Value: f.Value})
+	}
+	for _, f := range y.Files {
+		if f.RandomBytes <= 0 && f.Path != "" {
+			if _, err := os.Stat(f.Path); err != nil {
+				return nil, fmt.Errorf("failed to read multipart file '%s': %w", f.Path, err)
+			}
+		}
+		mb.Files = append(mb.Files, models.MultipartFile{
+			Field:       f.Field,
+			Path:        f.Path,
+			Filename:    f.Filename,
+			ContentType: f.ContentType,
+			RandomBytes: f.RandomBytes,
+		})
+	}
+	return mb, nil
+}
+
+// toModel converts a YAML body stream and checks that the file can be read.
+func (y *YAMLBodyStream) toModel() (*models.BodyStream, error) {
+	if y.RandomBytes <= 0 && y.Path != "" {
+		if _, err := os.Stat(y.Path); err != nil {
+			return nil, fmt.Errorf("failed to read body stream file '%s': %w", y.Path, err)
+		}
+	}
+	return &models.BodyStream{
+		Path:        y.Path,
+		RandomBytes: y.RandomBytes,
+		Chunked:     y.Chunked,
+	}, nil
+}
+
+// buildGraphQLBody encodes a GraphQL operation as a standard JSON request body.
+// Template placeholders inside the query or variables are kept verbatim and
+// rendered per request like any other body.
+func buildGraphQLBody(gql *models.GraphQLRequest) ([]byte, error) {
+	payload := struct {
+		Query         string                 `json:"query"`
+		Variables     map[string]interface{} `json:"variables,omitempty"`
+		OperationName string                 `json:"operationName,omitempty"`
+	}{
+		Query:         gql.Query,
+		Variables:     gql.Variables,
+		OperationName: gql.OperationName,
+	}
+	return json.Marshal(payload)
+}
+
+// graphQLDefaults fills in POST and a JSON Content-Type for GraphQL requests
+// unless the user set them explicitly.
+func graphQLDefaults(method string, headers map[string]string) (string, map[string]string) {
+	if method == "" {
+		method = "POST"
+	}
+	for k := range headers {
+		if strings.EqualFold(k, "Content-Type") {
+			return method, headers
+		}
+	}
+	merged := make(map[string]string, len(headers)+1)
+	for k, v := range headers {
+		merged[k] = v
+	}
+	merged["Content-Type"] = "application/json"
+	return method, merged
+}
+
+// convertAssertions converts YAML assertions to model assertions and pre-compiles
+// their regex patterns for performance.
+func convertAssertions(yamlAssertions []YAMLAssertion) ([]models.Assertion, error) {
+	var assertions []models.Assertion
+	for _, a := range yamlAssertions {
+		assertion := models.Assertion{
+			Type:    models.AssertionType(a.Type),
+			Value:   a.Value,
+			Path:    a.Path,
+			Message: a.Message,
+		}
+		// Default to "contains" if type not specified
+		if assertion.Type == "" {
+			assertion.Type = models.AssertContains
+		}
+		assertions = append(assertions, assertion)
+	}
+
+	if len(assertions) > 0 {
+		if err := validator.CompileAssertions(assertions); err != nil {
+			return nil, err
+		}
+	}
+	return assertions, nil
+}
+
 // Validate checks if the configuration is valid so we can start running immediately.
 // Returns detailed errors with suggestions for fixing issues.
 func Validate(cfg *models.Config) error {
@@ -299,6 +615,30 @@ func Validate(cfg *models.Config) error {
 		}
 	}
 
+	if cfg.GraphQL != nil && strings.TrimSpace(cfg.GraphQL.Query) == "" {
+		result.Add(ValidationError{
+			Field:   "target.graphql.query",
+			Message: "missing required GraphQL query",
+			Hint:    GetHint("graphql.query"),
+		})
+	}
+
+	validateMultipart(result, "target.multipart", cfg.Multipart, len(cfg.Body) > 0)
+	validateBodyStream(result, "target", cfg.BodyStream, len(cfg.Body) > 0 || cfg.Multipart != nil)
+	validateCompressRequest(result, "target", cfg.CompressRequest, cfg.Multipart, cfg.BodyStream)
+
+	if cfg.CompressRequest != "" && !isValidRequestEncoding(cfg.CompressRequest) {
+		err := ValidationError{
+			Field:    "target.compress_request",
+			Value:    cfg.CompressRequest,
+			Message:  "unsupported request compression",
+			Expected: "gzip, br or zstd",
+			Hint:     GetHint("target.compress_request"),
+		}
+		err.DidYouMean = FindClosestMatch(cfg.CompressRequest, validRequestEncodings)
+		result.Add(err)
+	}
+
 	// Load Profile Validation
 	if len(cfg.Stages) > 0 {
 		// Stages validation
@@ -379,6 +719,144 @@ func Validate(cfg *models.Config) error {
 			}
 			result.Add(err)
 		}
+		validateMultipart(result, fmt.Sprintf("steps[%d].multipart", i), step.Multipart, step.Body != "")
+		validateBodyStream(result, fmt.Sprintf("steps[%d]", i), step.BodyStream, step.Body != "" || step.Multipart != nil)
+		validateCompressRequest(result, fmt.Sprintf("steps[%d]", i), cfg.CompressRequest, step.Multipart, step.BodyStream)
+		if step.GraphQL != nil && strings.TrimSpace(step.GraphQL.Query) == "" {
+			result.Add(ValidationError{
+				Field:   fmt.Sprintf("steps[%d].graphql.query", i),
+				Message: "missing required GraphQL query",
+				Hint:    GetHint("graphql.query"),
+			})
+		}
+		if step.Stream != "" && step.Stream != models.StreamSSE {
+			result.Add(ValidationError{
+				Field:    fmt.Sprintf("steps[%d].stream", i),
+				Value:    step.Stream,
+				Message:  "unsupported stream mode",
+				Expected: "sse",
+				Hint:     GetHint("steps.stream"),
+			})
+		}
+		if step.SSE != nil && step.SSE.MaxEvents < 0 {
+			result.Add(ValidationError{
+				Field:    fmt.Sprintf("steps[%d].sse.max_events", i),
+				Value:    fmt.Sprintf("%d", step.SSE.MaxEvents),
+				Message:  "max_events cannot be negative",
+				Expected: "non-negative integer (0 = read until the stream closes)",
+			})
+		}
+	}
+
+	if f := cfg.Output.ResultsFormat; f != "" && !isValidResultsFormat(f) {
+		err := ValidationError{
+			Field:    "output.results_format",
+			Value:    f,
+			Message:  "unsupported results format",
+			Expected: "jsonl, csv or bin",
+			Hint:     GetHint("output.results"),
+		}
+		err.DidYouMean = FindClosestMatch(f, results.Formats)
+		result.Add(err)
+	}
+
+	if o := cfg.Output.OTLP; o != nil {
+		if o.Endpoint == "" {
+			result.Add(ValidationError{
+				Field:   "output.otlp.endpoint",
+				Message: "missing OTLP endpoint",
+				Hint:    GetHint("output.otlp"),
+			})
+		}
+		if o.Protocol != "" && o.Protocol != models.OTLPProtocolGRPC && o.Protocol != models.OTLPProtocolHTTP {
+			err := ValidationError{
+				Field:    "output.otlp.protocol",
+				Value:    o.Protocol,
+				Message:  "unsupported OTLP protocol",
+				Expected: "grpc or http",
+				Hint:     GetHint("output.otlp"),
+			}
+			err.DidYouMean = FindClosestMatch(o.Protocol, models.OTLPProtocols)
+			result.Add(err)
+		}
+		if o.Interval < 0 {
+			result.Add(ValidationError{
+				Field:    "output.otlp.interval",
+				Value:    o.Interval.String(),
+				Message:  "interval cannot be negative",
+				Expected: "positive duration (e.g., 10s)",
+			})
+		}
+		validateSampleRate(result, "output.otlp.sample_rate", o.SampleRate)
+	}
+
+	if i := cfg.Output.InfluxDB; i != nil {
+		if u, err := url.Parse(i.URL); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "udp") {
+			result.Add(ValidationError{
+				Field:    "output.influxdb.url",
+				Value:    i.URL,
+				Message:  "invalid InfluxDB URL",
+				Expected: "http(s):// write URL or udp://host:port",
+				Hint:     GetHint("output.influxdb"),
+			})
+		}
+		validateSampleRate(result, "output.influxdb.sample_rate", i.SampleRate)
+	}
+	if sd := cfg.Output.StatsD; sd != nil {
+		if _, _, err := net.SplitHostPort(sd.Address); err != nil {
+			result.Add(ValidationError{
+				Field:    "output.statsd.address",
+				Value:    sd.Address,
+				Message:  "invalid StatsD address",
+				Expected: "host:port (e.g., localhost:8125)",
+				Hint:     GetHint("output.statsd"),
+			})
+		}
+		validateSampleRate(result, "output.statsd.sample_rate", sd.SampleRate)
+	}
+
+	// Validate per-step thresholds reference existing steps
+	var stepNames []string
+	for _, step := range cfg.Steps {
+		stepNames = append(stepNames, step.Name)
+	}
+	for i, th := range cfg.Thresholds {
+		if th.Step == "" {
+			continue
+		}
+		found := false
+		for _, name := range stepNames {
+			if name == th.Step {
+				found = true
+				break
+			}
+		}
+		if !found {
+			err := ValidationError{
+				Field:   fmt.Sprintf("thresholds[%d]", i),
+				Value:   th.Expr,
+				Message: fmt.Sprintf("unknown step '%s'", th.Step),
+				Hint:    GetHint("thresholds"),
+			}
+			if len(stepNames) == 0 {
+				err.Message = "per-step thresholds require a 'steps' scenario"
+			} else {
+				err.DidYouMean = FindClosestMatch(th.Step, stepNames)
+			}
+			result.Add(err)
+		}
+	}
+
+	for i, p := range cfg.Percentiles {
+		if p <= 0 || p >= 100 {
+			result.Add(ValidationError{
+				Field:    fmt.Sprintf("report.percentiles[%d]", i),
+				Value:    fmt.Sprintf("%g", p),
+				Message:  "percentile out of range",
+				Expected: "a number between 0 and 100 (exclusive), e.g. 99.9",
+				Hint:     GetHint("report.percentiles"),
+			})
+		}
 	}
 
 	// Set default success code if none provided
@@ -390,9 +868,150 @@ func Validate(cfg *models.Config) error {
 		return fmt.Errorf("%s", result.FormatErrors())
 	}
 
+	cfg.Percentiles = resolvePercentiles(cfg.Percentiles, cfg.Thresholds)
 	return nil
 }
 
+// resolvePercentiles returns the sorted, de-duplicated percentile set of a run:
+// the configured one (or the default) plus every percentile used by a threshold.
+func resolvePercentiles(configured []float64, thresholds []models.Threshold) []float64 {
+	set := configured
+	if len(set) == 0 {
+		set = models.DefaultPercentiles
+	}
+	ps := append([]float64(nil), set...)
+	for _, th := range thresholds {
+		if p, ok := threshold.Percentile(th.Metric); ok {
+			ps = append(ps, p)
+		}
+	}
+	slices.Sort(ps)
+	return slices.Compact(ps)
+}
+
+// ParsePercentiles parses a comma-separated percentile list such as "50,90,99,99.9".
+func ParsePercentiles(s string) ([]float64, error) {
+	var ps []float64
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(part), "p"))
+		if part == "" {
+			continue
+		}
+		p, err := strconv.ParseFloat(part, 64)
+		if err != nil || p <= 0 || p >= 100 {
+			return nil, fmt.Errorf("invalid percentile '%s' (use numbers between 0 and 100, e.g. 50,90,99,99.9)", part)
+		}
+		ps = append(ps, p)
+	}
+	return ps, nil
+}
+
+// validateMultipart checks that every multipart part is named, every file part has a
+// source and the multipart body is not combined with another body.
+func validateMultipart(result *ValidationResult, field string, mb *models.MultipartBody, hasOtherBody bool) {
+	if mb == nil {
+		return
+	}
+	if hasOtherBody {
+		result.Add(ValidationError{
+			Field:   field,
+			Message: "cannot be combined with body, body_file, body_json or graphql",
+			Hint:    "Send the other values as multipart fields instead",
+		})
+	}
+	for i, f := range mb.Fields {
+		if f.Name == "" {
+			result.Add(ValidationError{
+				Field:   fmt.Sprintf("%s.fields[%d].name", field, i),
+				Message: "missing required field name",
+				Hint:    GetHint("multipart"),
+			})
+		}
+	}
+	for i, f := range mb.Files {
+		if f.Field == "" {
+			result.Add(ValidationError{
+				Field:   fmt.Sprintf("%s.files[%d].field", field, i),
+				Message: "missing required form field name",
+				Hint:    GetHint("multipart"),
+			})
+		}
+		if f.Path == "" && f.RandomBytes <= 0 {
+			result.Add(ValidationError{
+				Field:    fmt.Sprintf("%s.files[%d]", field, i),
+				Message:  "file part has no content source",
+				Expected: "either 'path' or a positive 'random_bytes'",
+				Hint:     GetHint("multipart"),
+			})
+		}
+	}
+}
+
+// validateBodyStream checks that a streamed body has exactly one source and is not
+// combined with another body.
+func validateBodyStream(result *ValidationResult, prefix string, bs *models.BodyStream, hasOtherBody bool) {
+	if bs == nil {
+		return
+	}
+	field := prefix + ".body_stream"
+	if bs.Path == "" && bs.RandomBytes <= 0 {
+		result.Add(ValidationError{
+			Field:    field,
+			Message:  "body stream has no content source",
+			Expected: "either 'path' or a positive 'random_bytes'",
+			Hint:     GetHint("body_stream"),
+		})
+	} else if bs.Path != "" && bs.RandomBytes > 0 {
+		result.Add(ValidationError{
+			Field:    field,
+			Message:  "body stream has both 'path' and 'random_bytes'",
+			Expected: "either 'path' or a positive 'random_bytes'",
+			Hint:     GetHint("body_stream"),
+		})
+	}
+	if hasOtherBody {
+		result.Add(ValidationError{
+			Field:   field,
+			Message: "cannot be combined with body, body_file, body_json, graphql or multipart",
+			Hint:    GetHint("body_stream"),
+		})
+	}
+}
+
+// validateCompressRequest checks that compress_request is not combined with a body
+// the engine streams as it is: multipart and streamed bodies are sent uncompressed.
+func validateCompressRequest(result *ValidationResult, prefix, enc string, mb *models.MultipartBody, bs *models.BodyStream) {
+	if enc == "" {
+		return
+	}
+	if mb != nil {
+		result.Add(ValidationError{
+			Field:   prefix + ".multipart",
+			Message: "cannot be combined with target.compress_request",
+			Hint:    "Multipart bodies are streamed as they are built and sent uncompressed; remove compress_request",
+		})
+	}
+	if bs != nil {
+		result.Add(ValidationError{
+			Field:   prefix + ".body_stream",
+			Message: "cannot be combined with target.compress_request",
+			Hint:    "Streamed bodies are sent as they are; compress the file beforehand and set a Content-Encoding header instead",
+		})
+	}
+}
+
+// validateSampleRate checks that a sample rate is a fraction (0 disables sampling).
+func validateSampleRate(result *ValidationResult, field string, rate float64) {
+	if rate < 0 || rate > 1 {
+		result.Add(ValidationError{
+			Field:    field,
+			Value:    fmt.Sprintf("%g", rate),
+			Message:  "sample rate out of range",
+			Expected: "a fraction between 0 and 1 (e.g., 0.1 for 10%)",
+		})
+	}
+}
+
 func dumpErrors(errs []string) string {
 	var out string
 	for i, e := range errs {
@@ -406,16 +1025,35 @@ func dumpErrors(errs []string) string {
 
 // SaveConfig saves the current configuration to a YAML file.
 func SaveConfig(path string, cfg *models.Config) error {
+	data, err := yaml.Marshal(toYAML(cfg))
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	// Append usage instructions
+	comment := fmt.Sprintf("\n# Run this configuration:\n# ./sayl -config %s\n", filepath.Base(path))
+	data = append(data, []byte(comment)...)
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// toYAML builds the YAML form of a configuration as it is resolved for a run,
+// command-line overrides included. Bodies read from body_file or body_json are
+// written as a plain body.
+func toYAML(cfg *models.Config) YAMLConfig {
 	var yamlCfg YAMLConfig
+	yamlCfg.Name = cfg.Name
 	yamlCfg.Target.URL = cfg.URL
 	yamlCfg.Target.Method = cfg.Method
 	yamlCfg.Target.Headers = cfg.Headers
-	if len(cfg.Body) > 0 {
-		// Try to unmarshal as JSON first to save as body_json if possible,
-		// but for simplicity, let's just save as string body for now unless it's complex.
-		// Actually, let's just save as Body string to be safe and simple.
+	switch {
+	case cfg.GraphQL != nil:
+		yamlCfg.Target.GraphQL = fromGraphQL(cfg.GraphQL)
+	case len(cfg.Body) > 0:
 		yamlCfg.Target.Body = string(cfg.Body)
 	}
+	yamlCfg.Target.Multipart = fromMultipart(cfg.Multipart)
+	yamlCfg.Target.BodyStream = fromBodyStream(cfg.BodyStream)
 	if cfg.Timeout > 0 {
 		yamlCfg.Target.Timeout = cfg.Timeout.String()
 	}
@@ -424,6 +1062,8 @@ func SaveConfig(path string, cfg *models.Config) error {
 	yamlCfg.Target.HTTP2 = &cfg.HTTP2
 	yamlCfg.Target.HTTP2Only = cfg.HTTP2Only
 	yamlCfg.Target.H2C = cfg.H2C
+	yamlCfg.Target.CompressRequest = cfg.CompressRequest
+	yamlCfg.Target.AcceptEncoding = cfg.AcceptEncoding
 
 	if len(cfg.Stages) > 0 {
 		for _, s := range cfg.Stages {
@@ -444,15 +1084,141 @@ func SaveConfig(path string, cfg *models.Config) error {
 	for code := range cfg.SuccessCodes {
 		yamlCfg.Load.SuccessCodes = append(yamlCfg.Load.SuccessCodes, code)
 	}
+	slices.Sort(yamlCfg.Load.SuccessCodes)
+	if cb := cfg.CircuitBreaker; cb != nil {
+		yamlCfg.Load.StopIf = cb.StopIf
+		yamlCfg.Load.MinSamples = cb.MinSamples
+	}
 
-	data, err := yaml.Marshal(yamlCfg)
-	if err != nil {
-		return fmt.Errorf("failed to marshal config: %w", err)
+	yamlCfg.Steps = makeLike(yamlCfg.Steps, len(cfg.Steps))
+	for i, s := range cfg.Steps {
+		ys := &yamlCfg.Steps[i]
+		ys.Name = s.Name
+		ys.URL = s.URL
+		ys.Method = s.Method
+		ys.Headers = s.Headers
+		if s.GraphQL != nil {
+			ys.GraphQL = fromGraphQL(s.GraphQL)
+		} else {
+			ys.Body = s.Body
+		}
+		ys.Extract = s.Extract
+		ys.Variables = s.Variables
+		ys.Assertions = fromAssertions(s.Assertions)
+		ys.Multipart = fromMultipart(s.Multipart)
+		ys.BodyStream = fromBodyStream(s.BodyStream)
+		ys.Stream = s.Stream
+		if s.SSE != nil {
+			ys.SSE.MaxEvents = s.SSE.MaxEvents
+			ys.SSE.UntilEvent = s.SSE.UntilEvent
+			ys.SSE.UntilContains = s.SSE.UntilContains
+			if s.SSE.MaxDuration > 0 {
+				ys.SSE.MaxDuration = s.SSE.MaxDuration.String()
+			}
+		}
+		ys.EventAssertions = fromAssertions(s.EventAssertions)
+		ys.EventExtract = s.EventExtract
 	}
 
-	// Append usage instructions
-	comment := fmt.Sprintf("\n# Run this configuration:\n# ./sayl -config %s\n", filepath.Base(path))
-	data = append(data, []byte(comment)...)
+	yamlCfg.Data = makeLike(yamlCfg.Data, len(cfg.Data))
+	for i, d := range cfg.Data {
+		yamlCfg.Data[i].Name = d.Name
+		yamlCfg.Data[i].Path = d.Path
+	}
+	for _, th := range cfg.Thresholds {
+		yamlCfg.Thresholds = append(yamlCfg.Thresholds, th.Expr)
+	}
+	yamlCfg.Report.Percentiles = cfg.Percentiles
+	yamlCfg.Report.EmbedJSON = cfg.EmbedJSON
 
-	return os.WriteFile(path, data, 0644)
+	yamlCfg.Output.Dir = cfg.Output.Dir
+	yamlCfg.Output.Results = cfg.Output.Results
+	yamlCfg.Output.ResultsFormat = cfg.Output.ResultsFormat
+	if o := cfg.Output.OTLP; o != nil {
+		yo := alloc(&yamlCfg.Output.OTLP)
+		yo.Endpoint = o.Endpoint
+		yo.Protocol = o.Protocol
+		yo.Insecure = o.Insecure
+		yo.Headers = o.Headers
+		if o.Interval > 0 {
+			yo.Interval = o.Interval.String()
+		}
+		yo.Traces = o.Traces
+		yo.SampleRate = o.SampleRate
+	}
+	if i := cfg.Output.InfluxDB; i != nil {
+		yi := alloc(&yamlCfg.Output.InfluxDB)
+		yi.URL = i.URL
+		yi.Token = i.Token
+		yi.Tags = i.Tags
+		yi.SampleRate = i.SampleRate
+	}
+	if sd := cfg.Output.StatsD; sd != nil {
+		ys := alloc(&yamlCfg.Output.StatsD)
+		ys.Address = sd.Address
+		ys.Prefix = sd.Prefix
+		ys.Tags = sd.Tags
+		ys.SampleRate = sd.SampleRate
+	}
+	return yamlCfg
+}
+
+// makeLike returns a slice of n zero elements of the type of s, for filling the
+// anonymous struct slices of YAMLConfig. It returns nil when n is zero.
+func makeLike[S ~[]E, E any](s S, n int) S {
+	if n == 0 {
+		return nil
+	}
+	return make(S, n)
+}
+
+// alloc points *p at a new zero value and returns it, for filling the anonymous
+// struct pointers of YAMLConfig.
+func alloc[T any](p **T) *T {
+	*p = new(T)
+	return *p
+}
+
+func fromGraphQL(g *models.GraphQLRequest) *YAMLGraphQL {
+	return &YAMLGraphQL{
+		Query:         g.Query,
+		Variables:     g.Variables,
+		OperationName: g.OperationName,
+	}
+}
+
+func fromMultipart(mb *models.MultipartBody) *YAMLMultipart {
+	if mb == nil {
+		return nil
+	}
+	y := &YAMLMultipart{}
+	y.Fields = makeLike(y.Fields, len(mb.Fields))
+	for i, f := range mb.Fields {
+		y.Fields[i].Name = f.Name
+		y.Fields[i].Value = f.Value
+	}
+	y.Files = makeLike(y.Files, len(mb.Files))
+	for i, f := range mb.Files {
+		y.Files[i].Field = f.Field
+		y.Files[i].Path = f.Path
+		y.Files[i].Filename = f.Filename
+		y.Files[i].ContentType = f.ContentType
+		y.Files[i].RandomBytes = f.RandomBytes
+	}
+	return y
+}
+
+func fromBodyStream(bs *models.BodyStream) *YAMLBodyStream {
+	if bs == nil {
+		return nil
+	}
+	return &YAMLBodyStream{Path: bs.Path, RandomBytes: bs.RandomBytes, Chunked: bs.Chunked}
+}
+
+func fromAssertions(as []models.Assertion) []YAMLAssertion {
+	var out []YAMLAssertion
+	for _, a := range as {
+		out = append(out, YAMLAssertion{Type: string(a.Type), Value: a.Value, Path: a.Path, Message: a.Message})
+	}
+	return out
 }
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"bytes"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/Amr-9/sayl/pkg/models"
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replaces secret values in the configuration echoed by the reports.
+const redacted = "[REDACTED]"
+
+// secretKeyParts mark a key as secret when its lowercased name, with '-' and '_'
+// removed, contains one of them: Authorization, X-API-Key, client_secret, ...
+var secretKeyParts = []string{"password", "passwd", "secret", "token", "apikey", "authorization", "cookie", "credential", "privatekey", "signature"}
+
+// jsonSecretPattern matches a secret-looking field of a JSON body kept as a string.
+var jsonSecretPattern = regexp.MustCompile(`(?i)("[^"]*(?:password|passwd|secret|token|api_?key|authorization|credential)[^"]*"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// RedactedYAML returns the configuration as YAML with its secrets masked, for the
+// reports to document what was tested. It renders the resolved configuration,
+// command-line overrides included, like SaveConfig does. Values under
+// secret-looking keys, passwords in URLs, secret query parameters and
+// Bearer/Basic credentials are replaced. It returns "" if rendering fails.
+func RedactedYAML(cfg *models.Config) string {
+	src, err := yaml.Marshal(toYAML(cfg))
+	if err != nil {
+		return ""
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return ""
+	}
+	redactNode(&doc, false)
+
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return ""
+	}
+	_ = enc.Close()
+	return b.String()
+}
+
+// redactNode masks the secrets below n. secret is set inside the value of a
+// secret key, where every scalar is masked.
+func redactNode(n *yaml.Node, secret bool) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			redactNode(n.Content[i+1], secret || isSecretKey(n.Content[i].Value))
+		}
+	case yaml.ScalarNode:
+		if secret {
+			n.Value, n.Tag, n.Style = redacted, "!!str", 0
+			return
+		}
+		if v := redactValue(n.Value); v != n.Value {
+			n.Value, n.Style = v, 0
+		}
+	default:
+		for _, c := range n.Content {
+			redactNode(c, secret)
+		}
+	}
+}
+
+func isSecretKey(key string) bool {
+	key = strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(key))
+	for _, part := range secretKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValue masks the credentials inside a scalar that is not itself secret.
+func redactValue(v string) string {
+	for _, scheme := range []string{"Bearer ", "Basic "} {
+		if len(v) > len(scheme) && strings.EqualFold(v[:len(scheme)], scheme) {
+			return v[:len(scheme)] + redacted
+		}
+	}
+	if strings.Contains(v, "://") && !strings.ContainsAny(v, " \n") {
+		if u, err := url.Parse(v); err == nil && u.Host != "" {
+			return redactURL(u)
+		}
+	}
+	return jsonSecretPattern.ReplaceAllString(v, `$1"`+redacted+`"`)
+}
+
+// redactURL masks the password and the secret query parameters of u.
+func redactURL(u *url.URL) string {
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redacted)
+	}
+	if u.RawQuery != "" {
+		q := u.Query()
+		changed := false
+		for key := range q {
+			if isSecretKey(key) {
+				q.Set(key, redacted)
+				changed = true
+			}
+		}
+		if changed {
+			u.RawQuery = q.Encode()
+		}
+	}
+	// Keep template placeholders such as {{uuid}} readable.
+	s, err := url.PathUnescape(u.String())
+	if err != nil {
+		return u.String()
+	}
+	return s
+}
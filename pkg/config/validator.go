@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"strings"
+
+	"github.com/Amr-9/sayl/internal/results"
 )
 
 // ValidationError represents a single validation error with context and suggestions
@@ -70,24 +72,36 @@ func (v *ValidationResult) FormatErrors() string {
 }
 
 // Known valid field names for typo detection
-var validTargetFields = []string{"url", "method", "headers", "body", "body_file", "body_json", "timeout", "insecure", "keep_alive", "http2", "http2_only", "h2c"}
+var validTargetFields = []string{"url", "method", "headers", "body", "body_file", "body_json", "timeout", "insecure", "keep_alive", "http2", "http2_only", "h2c", "graphql", "multipart", "body_stream", "compress_request", "accept_encoding"}
 var validLoadFields = []string{"duration", "rate", "concurrency", "success_codes", "stages"}
-var validStepFields = []string{"name", "url", "method", "headers", "body", "body_file", "body_json", "extract", "variables", "save"}
+var validStepFields = []string{"name", "url", "method", "headers", "body", "body_file", "body_json", "extract", "variables", "save", "assertions", "graphql", "multipart", "body_stream", "stream", "sse", "event_assertions", "event_extract"}
 var validHTTPMethods = []string{"GET", "POST", "PUT", "DELETE", "PATCH", "HEAD", "OPTIONS"}
+var validRequestEncodings = []string{"gzip", "br", "zstd"}
 
 // Hints for common fields
 var fieldHints = map[string]string{
-	"target.url":         "Provide the full URL including protocol (e.g., https://api.example.com/v1/users)",
-	"target.method":      "HTTP method: GET, POST, PUT, DELETE, PATCH, HEAD, OPTIONS",
-	"target.timeout":     "Request timeout with unit (e.g., '10s', '30s', '1m')",
-	"target.http2":       "Enable HTTP/2 support (true/false, default: true for HTTPS)",
-	"target.http2_only":  "Force HTTP/2 only - fail if server doesn't support it",
-	"target.h2c":         "Enable HTTP/2 Cleartext for non-TLS URLs (development/testing only)",
-	"load.duration":      "Test duration with unit (e.g., '30s', '2m', '1h')",
-	"load.rate":          "Requests per second as a positive integer (e.g., 100)",
-	"load.concurrency":   "Number of concurrent workers as a positive integer (e.g., 10)",
-	"load.success_codes": "List of HTTP status codes to count as success (e.g., [200, 201])",
-	"load.stages":        "List of stages with 'duration' and 'target' rate for ramping",
+	"target.url":              "Provide the full URL including protocol (e.g., https://api.example.com/v1/users)",
+	"target.method":           "HTTP method: GET, POST, PUT, DELETE, PATCH, HEAD, OPTIONS",
+	"target.timeout":          "Request timeout with unit (e.g., '10s', '30s', '1m')",
+	"target.http2":            "Enable HTTP/2 support (true/false, default: true for HTTPS)",
+	"target.http2_only":       "Force HTTP/2 only - fail if server doesn't support it",
+	"target.h2c":              "Enable HTTP/2 Cleartext for non-TLS URLs (development/testing only)",
+	"target.compress_request": "Compress request bodies and set Content-Encoding: gzip, br or zstd",
+	"load.duration":           "Test duration with unit (e.g., '30s', '2m', '1h')",
+	"load.rate":               "Requests per second as a positive integer (e.g., 100)",
+	"load.concurrency":        "Number of concurrent workers as a positive integer (e.g., 10)",
+	"load.success_codes":      "List of HTTP status codes to count as success (e.g., [200, 201])",
+	"load.stages":             "List of stages with 'duration' and 'target' rate for ramping",
+	"steps.stream":            "Set stream: sse to read the response as Server-Sent Events",
+	"multipart":               "Each part needs a name; file parts need 'path' or 'random_bytes' (e.g. field: avatar, path: ./avatar.png)",
+	"body_stream":             "Stream a body without templating: body_stream: ./big.bin, or path/random_bytes/chunked as a mapping",
+	"report.percentiles":      "Latency percentiles to report, e.g. report: { percentiles: [50, 90, 99, 99.9, 99.99] }",
+	"thresholds":              "Use 'metric op value', e.g. 'p95 < 250ms', 'error_rate < 1%' or 'steps.<name>.p99 < 1s'",
+	"output.results":          "Write every request to a file: output: { results: results.jsonl, results_format: jsonl|csv|bin }",
+	"output.influxdb":         "Stream to InfluxDB: output: { influxdb: { url: http://localhost:8086/api/v2/write?org=acme&bucket=load, token: ... } } or url: udp://localhost:8089",
+	"output.statsd":           "Stream to StatsD: output: { statsd: { address: localhost:8125 } }",
+	"output.otlp":             "Export to OpenTelemetry: output: { otlp: { endpoint: localhost:4317, protocol: grpc|http, insecure: true } }",
+	"graphql.query":           "Provide the GraphQL document, e.g. query: \"query { viewer { id } }\"",
 }
 
 // levenshteinDistance calculates the edit distance between two strings
@@ -178,6 +192,26 @@ func ValidateHTTPMethod(method string) (bool, string) {
 	return false, suggestion
 }
 
+// isValidRequestEncoding checks a compress_request value
+func isValidRequestEncoding(enc string) bool {
+	for _, valid := range validRequestEncodings {
+		if enc == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// isValidResultsFormat checks if the results file format is supported
+func isValidResultsFormat(format string) bool {
+	for _, valid := range results.Formats {
+		if format == valid {
+			return true
+		}
+	}
+	return false
+}
+
 // truncate shortens a string for display
 func truncate(s string, maxLen int) string {
 	if len(s) <= maxLen {
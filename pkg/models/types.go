@@ -2,6 +2,7 @@ package models
 
 import (
 	"regexp"
+	"strconv"
 	"time"
 )
 
@@ -23,6 +24,53 @@ type Assertion struct {
 	Message string         `json:"message,omitempty"` // Custom error message
 }
 
+// StreamSSE marks a step whose response is consumed as a Server-Sent Events stream
+const StreamSSE = "sse"
+
+// SSEOptions controls when an SSE stream step stops reading events
+type SSEOptions struct {
+	MaxEvents     int           `json:"max_events,omitempty"`     // Stop after N events (0 = until the stream closes)
+	UntilEvent    string        `json:"until_event,omitempty"`    // Stop when an event of this type arrives
+	UntilContains string        `json:"until_contains,omitempty"` // Stop when an event payload contains this string
+	MaxDuration   time.Duration `json:"max_duration,omitempty"`   // Stop reading after this long (0 = bounded by the request timeout)
+}
+
+// GraphQLRequest describes a GraphQL operation. The request body is built from it at config load.
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	OperationName string                 `json:"operation_name,omitempty"`
+}
+
+// MultipartBody describes a multipart/form-data request body
+type MultipartBody struct {
+	Fields []MultipartField `json:"fields,omitempty"`
+	Files  []MultipartFile  `json:"files,omitempty"`
+}
+
+// MultipartField is a plain form field; Value supports templating
+type MultipartField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// MultipartFile is a file part, streamed from Path or generated as RandomBytes of random data
+type MultipartFile struct {
+	Field       string `json:"field"`                  // Form field name
+	Path        string `json:"path,omitempty"`         // File to stream from disk
+	Filename    string `json:"filename,omitempty"`     // Filename sent to the server (supports templating)
+	ContentType string `json:"content_type,omitempty"` // Defaults from the file extension
+	RandomBytes int64  `json:"random_bytes,omitempty"` // Generate a random payload of N bytes instead of reading Path
+}
+
+// BodyStream describes a request body streamed from disk or from a shared generated buffer.
+// Streamed bodies are sent as-is: no templating and no request compression.
+type BodyStream struct {
+	Path        string `json:"path,omitempty"`         // File to stream from disk
+	RandomBytes int64  `json:"random_bytes,omitempty"` // Generate a random payload of N bytes instead of reading Path
+	Chunked     bool   `json:"chunked,omitempty"`      // Omit Content-Length and use chunked transfer encoding
+}
+
 // CircuitBreaker defines conditions to stop a test automatically
 type CircuitBreaker struct {
 	// StopIf is the raw condition string, e.g., "errors > 10%"
@@ -36,27 +84,100 @@ type CircuitBreaker struct {
 	IsPercent bool    `json:"-"` // Whether threshold is a percentage
 }
 
+// Threshold is a pass/fail criterion evaluated against the final report, e.g. "p95 < 250ms"
+// or "steps.login.p99 < 1s".
+type Threshold struct {
+	Expr     string  `json:"expr"`           // Raw expression as written in the config
+	Step     string  `json:"step,omitempty"` // Step name for per-step thresholds
+	Metric   string  `json:"metric"`         // p50, p95, error_rate, rps, ...
+	Operator string  `json:"operator"`       // <, <=, >, >=
+	Value    float64 `json:"value"`          // Milliseconds for latency metrics, percent for rates
+}
+
+// ThresholdResult is the outcome of a single threshold after the run
+type ThresholdResult struct {
+	Expr   string `json:"expr"`
+	Actual string `json:"actual"` // Observed value, formatted with its unit
+	Passed bool   `json:"passed"`
+}
+
 // Config defines the load test parameters
 type Config struct {
-	URL            string            `json:"url"`
-	Method         string            `json:"method"`
-	Body           []byte            `json:"body,omitempty"`
-	Headers        map[string]string `json:"headers,omitempty"`
-	Timeout        time.Duration     `json:"timeout"`
-	Insecure       bool              `json:"insecure"`   // Skip TLS verification
-	KeepAlive      bool              `json:"keep_alive"` // Use keep-alive connections
-	HTTP2          bool              `json:"http2"`      // Enable HTTP/2 support
-	HTTP2Only      bool              `json:"http2_only"` // Force HTTP/2 only, fail if not supported
-	H2C            bool              `json:"h2c"`        // Enable HTTP/2 Cleartext (for non-TLS endpoints)
-	Duration       time.Duration     `json:"duration"`
-	Rate           int               `json:"rate"`        // Requests per second
-	Concurrency    int               `json:"concurrency"` // Number of workers
-	SuccessCodes   map[int]bool      `json:"success_codes"`
-	Stages         []Stage           `json:"stages,omitempty"`
-	Steps          []Step            `json:"steps,omitempty"` // For chained scenarios
-	Data           []DataSource      `json:"data,omitempty"`  // distinct CSV data sources
-	CircuitBreaker *CircuitBreaker   `json:"circuit_breaker,omitempty"`
-	Debug          bool              `json:"-"` // Debug mode - run single iteration with detailed output
+	Name            string            `json:"name,omitempty"` // Test name, used to tag streamed metrics
+	URL             string            `json:"url"`
+	Method          string            `json:"method"`
+	Body            []byte            `json:"body,omitempty"`
+	Headers         map[string]string `json:"headers,omitempty"`
+	Timeout         time.Duration     `json:"timeout"`
+	Insecure        bool              `json:"insecure"`                   // Skip TLS verification
+	KeepAlive       bool              `json:"keep_alive"`                 // Use keep-alive connections
+	HTTP2           bool              `json:"http2"`                      // Enable HTTP/2 support
+	HTTP2Only       bool              `json:"http2_only"`                 // Force HTTP/2 only, fail if not supported
+	H2C             bool              `json:"h2c"`                        // Enable HTTP/2 Cleartext (for non-TLS endpoints)
+	CompressRequest string            `json:"compress_request,omitempty"` // Compress request bodies: gzip, br or zstd
+	AcceptEncoding  string            `json:"accept_encoding,omitempty"`  // Accept-Encoding sent with every request (default: gzip)
+	Duration        time.Duration     `json:"duration"`
+	Rate            int               `json:"rate"`        // Requests per second
+	Concurrency     int               `json:"concurrency"` // Number of workers
+	SuccessCodes    map[int]bool      `json:"success_codes"`
+	Stages          []Stage           `json:"stages,omitempty"`
+	Steps           []Step            `json:"steps,omitempty"` // For chained scenarios
+	Data            []DataSource      `json:"data,omitempty"`  // distinct CSV data sources
+	GraphQL         *GraphQLRequest   `json:"graphql,omitempty"`
+	Multipart       *MultipartBody    `json:"multipart,omitempty"`
+	BodyStream      *BodyStream       `json:"body_stream,omitempty"`
+	CircuitBreaker  *CircuitBreaker   `json:"circuit_breaker,omitempty"`
+	Thresholds      []Threshold       `json:"thresholds,omitempty"`  // Pass/fail criteria checked after the run
+	Percentiles     []float64         `json:"percentiles,omitempty"` // Latency percentiles to report (DefaultPercentiles when empty)
+	EmbedJSON       bool              `json:"embed_json,omitempty"`  // Bundle the report JSON inside report.html
+	Output          OutputConfig      `json:"output,omitempty"`
+	Debug           bool              `json:"-"` // Debug mode - run single iteration with detailed output
+}
+
+// OutputConfig controls the files written during and after a run
+type OutputConfig struct {
+	Dir           string          `json:"dir,omitempty"`            // Parent of the timestamped run folders (default "runs")
+	Results       string          `json:"results,omitempty"`        // Path of the raw per-request results file (disabled when empty)
+	ResultsFormat string          `json:"results_format,omitempty"` // jsonl, csv or bin (inferred from the extension when empty)
+	OTLP          *OTLPConfig     `json:"otlp,omitempty"`           // OpenTelemetry export (disabled when nil)
+	InfluxDB      *InfluxDBConfig `json:"influxdb,omitempty"`       // InfluxDB line protocol stream (disabled when nil)
+	StatsD        *StatsDConfig   `json:"statsd,omitempty"`         // StatsD stream (disabled when nil)
+}
+
+// InfluxDBConfig controls streaming of per-second aggregates in InfluxDB line protocol
+type InfluxDBConfig struct {
+	URL        string            `json:"url"`                   // Write URL (http:// or https://) or udp://host:port
+	Token      string            `json:"-"`                     // Sent as "Authorization: Token ..." over HTTP
+	Tags       map[string]string `json:"tags,omitempty"`        // Extra tags added to every point
+	SampleRate float64           `json:"sample_rate,omitempty"` // Fraction of requests also written as individual points (0 = none)
+}
+
+// StatsDConfig controls streaming of per-second aggregates in StatsD format over UDP
+type StatsDConfig struct {
+	Address    string            `json:"address"`               // host:port of the StatsD server
+	Prefix     string            `json:"prefix,omitempty"`      // Metric name prefix (default "sayl.")
+	Tags       map[string]string `json:"tags,omitempty"`        // Extra tags, sent in the DogStatsD "|#key:value" format
+	SampleRate float64           `json:"sample_rate,omitempty"` // Fraction of requests also sent as timings (0 = none)
+}
+
+// OTLP transports supported by the exporter.
+const (
+	OTLPProtocolGRPC = "grpc"
+	OTLPProtocolHTTP = "http"
+)
+
+// OTLPProtocols lists the supported OTLP transports.
+var OTLPProtocols = []string{OTLPProtocolGRPC, OTLPProtocolHTTP}
+
+// OTLPConfig controls the export of run metrics and request spans to an OTLP endpoint
+type OTLPConfig struct {
+	Endpoint   string            `json:"endpoint"`              // host:port, or a URL (http:// implies insecure)
+	Protocol   string            `json:"protocol"`              // grpc or http
+	Insecure   bool              `json:"insecure,omitempty"`    // Disable TLS for host:port endpoints
+	Headers    map[string]string `json:"headers,omitempty"`     // Extra headers, e.g. authentication
+	Interval   time.Duration     `json:"interval"`              // Metrics export interval
+	Traces     bool              `json:"traces,omitempty"`      // Export one span per request, grouped by scenario iteration
+	SampleRate float64           `json:"sample_rate,omitempty"` // Fraction of iterations traced (0 < rate <= 1)
 }
 
 // DataSource defines a source of external data (e.g. CSV file)
@@ -75,6 +196,15 @@ type Step struct {
 	Extract    map[string]string `json:"extract,omitempty"`   // Extraction rules: "var_name": "json_path"
 	Variables  map[string]string `json:"variables,omitempty"` // Variables to pre-calculate and store in session
 	Assertions []Assertion       `json:"assertions,omitempty"`
+	GraphQL    *GraphQLRequest   `json:"graphql,omitempty"`     // GraphQL operation; responses with "errors" count as failures
+	Multipart  *MultipartBody    `json:"multipart,omitempty"`   // multipart/form-data body (replaces Body)
+	BodyStream *BodyStream       `json:"body_stream,omitempty"` // Body streamed from a file or generated bytes (replaces Body)
+
+	// Streaming (SSE) options
+	Stream          string            `json:"stream,omitempty"`           // "sse" to parse the response incrementally
+	SSE             *SSEOptions       `json:"sse,omitempty"`              // Stop conditions for SSE streams
+	EventAssertions []Assertion       `json:"event_assertions,omitempty"` // Assertions applied to every event payload
+	EventExtract    map[string]string `json:"event_extract,omitempty"`    // Extraction rules applied to every event payload
 }
 
 // Stage represents a load test stage
@@ -88,16 +218,106 @@ type Result struct {
 	Timestamp      time.Time
 	Latency        time.Duration
 	Status         int
-	Bytes          int64
+	Bytes          int64  // Decoded response body bytes
+	WireBytes      int64  // Response body bytes as received (before Content-Encoding decoding)
 	Error          error  // Network/server error
 	AssertionError error  // Assertion failure (classified separately)
 	StepName       string // Name of the step for reporting
 	Protocol       string // HTTP protocol used ("HTTP/1.1", "HTTP/2.0")
+
+	// Stream metrics (set only for SSE steps)
+	Streamed         bool            // Response was consumed as an event stream
+	Events           int             // Number of events received
+	TimeToFirstEvent time.Duration   // Time from request start to the first event
+	EventGaps        []time.Duration // Gaps between consecutive events
+}
+
+// BodyReadPrefix starts the message of every BodyReadError.
+const BodyReadPrefix = "reading response body"
+
+// BodyReadError reports a failure while reading a response body, after the status
+// line and headers had already arrived.
+type BodyReadError struct {
+	Err error
+}
+
+func (e *BodyReadError) Error() string { return BodyReadPrefix + ": " + e.Err.Error() }
+
+func (e *BodyReadError) Unwrap() error { return e.Err }
+
+// DefaultPercentiles are the latency percentiles reported when none are configured.
+var DefaultPercentiles = []float64{50, 75, 90, 95, 99}
+
+// LatencyBounds are the upper bounds of the latency histogram bins exported in
+// Report.LatencyHistogram and SecondStats.Histogram: five per decade (1, 1.6, 2.5,
+// 4, 6.3) from 10µs to 63s, so the bins are evenly spaced on a log scale. Bin i
+// counts latencies in (LatencyBounds[i-1], LatencyBounds[i]]; the last bin also
+// holds anything slower.
+var LatencyBounds = func() []time.Duration {
+	var bounds []time.Duration
+	for decade := 10 * time.Microsecond; decade <= 10*time.Second; decade *= 10 {
+		for _, m := range []float64{1, 1.6, 2.5, 4, 6.3} {
+			bounds = append(bounds, time.Duration(float64(decade)*m+0.5))
+		}
+	}
+	return bounds
+}()
+
+// PercentileValue is the latency at one percentile, e.g. {99.9, 180ms}
+type PercentileValue struct {
+	P     float64       `json:"p"`
+	Value time.Duration `json:"value"`
+}
+
+// Label returns the percentile name used in outputs and thresholds, e.g. "p99.9".
+func (p PercentileValue) Label() string {
+	return PercentileLabel(p.P)
+}
+
+// PercentileLabel formats a percentile as "p50", "p99.9" or "p99.99".
+func PercentileLabel(p float64) string {
+	return "p" + strconv.FormatFloat(p, 'f', -1, 64)
+}
+
+// LatencyPercentiles returns the configured percentile set of the report, or the
+// fixed P50-P99 fields for reports written before the set was configurable.
+func (r Report) LatencyPercentiles() []PercentileValue {
+	if len(r.Percentiles) > 0 {
+		return r.Percentiles
+	}
+	return []PercentileValue{{50, r.P50}, {75, r.P75}, {90, r.P90}, {95, r.P95}, {99, r.P99}}
+}
+
+// StepStats holds the metrics of a single scenario step
+type StepStats struct {
+	Name        string        `json:"name"`
+	Requests    int64         `json:"requests"`
+	Success     int64         `json:"success"`
+	Failures    int64         `json:"failures"`
+	SuccessRate float64       `json:"success_rate"`
+	RPS         float64       `json:"rps"`
+	P50         time.Duration `json:"p50"`
+	P75         time.Duration `json:"p75"`
+	P90         time.Duration `json:"p90"`
+	P95         time.Duration `json:"p95"`
+	P99         time.Duration `json:"p99"`
+	Min         time.Duration `json:"min"`
+	Max         time.Duration `json:"max"`
+	Mean        time.Duration `json:"mean"`
+	StdDev      time.Duration `json:"stddev"`
+
+	Percentiles []PercentileValue `json:"percentiles,omitempty"` // The configured percentile set
+
+	StatusCodes       map[string]int `json:"status_codes,omitempty"`
+	Errors            map[string]int `json:"errors,omitempty"` // Errors by class
+	AssertionFailures int64          `json:"assertion_failures,omitempty"`
+	AssertionErrors   map[string]int `json:"assertion_errors,omitempty"` // Assertion failure messages and their counts
 }
 
 // SecondStats captures metrics for a single second of the test
 type SecondStats struct {
-	Second            int            `json:"second"`
+	Second            int            `json:"second"`            // End of the bucket, in seconds since the start
+	Seconds           int            `json:"seconds,omitempty"` // Width of a downsampled point (0 = bucket_seconds)
 	Requests          int64          `json:"requests"`
 	Success           int64          `json:"success"`
 	Failures          int64          `json:"failures"`
@@ -109,34 +329,106 @@ type SecondStats struct {
 	P95               time.Duration  `json:"p95"`
 	P99               time.Duration  `json:"p99"`
 	StatusCodes       map[string]int `json:"status_codes"`
+
+	Percentiles []PercentileValue          `json:"percentiles,omitempty"` // The configured percentile set
+	Histogram   []int64                    `json:"histogram,omitempty"`   // Latency counts per LatencyBounds bin, trailing zeros trimmed
+	Steps       map[string]StepSecondStats `json:"steps,omitempty"`       // Per-step metrics of this bucket, keyed by step name
+}
+
+// LatencyPercentiles returns the configured percentile set of the bucket, or the
+// fixed P50-P99 fields for results written before percentiles were configurable.
+func (s SecondStats) LatencyPercentiles() []PercentileValue {
+	if len(s.Percentiles) > 0 {
+		return s.Percentiles
+	}
+	return []PercentileValue{{50, s.P50}, {75, s.P75}, {90, s.P90}, {95, s.P95}, {99, s.P99}}
+}
+
+// StepSecondStats holds the metrics of one scenario step within a time series bucket
+type StepSecondStats struct {
+	Requests int64         `json:"requests"`
+	Failures int64         `json:"failures"`
+	P50      time.Duration `json:"p50"`
+	P95      time.Duration `json:"p95"`
+	P99      time.Duration `json:"p99"`
 }
 
 // Report is the final summary of the load test
 type Report struct {
-	TargetURL          string         `json:"target_url"`
-	Method             string         `json:"method"`
-	Duration           time.Duration  `json:"duration"` // Configured duration
-	Concurrency        int            `json:"concurrency"`
-	TotalRequests      int64          `json:"total_requests"`
-	SuccessCount       int64          `json:"success_count"`
-	FailureCount       int64          `json:"failure_count"`
-	AssertionFailures  int64          `json:"assertion_failures"` // Separate from network failures
-	SuccessRate        float64        `json:"success_rate"`
-	TotalBytes         int64          `json:"total_bytes"`
-	Throughput         float64        `json:"throughput"` // MB/s
-	RPS                float64        `json:"rps"`
-	P50                time.Duration  `json:"p50"`
-	P75                time.Duration  `json:"p75"`
-	P90                time.Duration  `json:"p90"`
-	P95                time.Duration  `json:"p95"`
-	P99                time.Duration  `json:"p99"`
-	Max                time.Duration  `json:"max"`
-	Min                time.Duration  `json:"min"`
-	StatusCodes        map[string]int `json:"status_codes"`
-	Errors             map[string]int `json:"errors"`
-	AssertionErrors    map[string]int `json:"assertion_errors,omitempty"`    // Assertion failures by message
-	ProtocolCounts     map[string]int `json:"protocol_counts,omitempty"`     // Protocol distribution (HTTP/1.1, HTTP/2.0)
-	TimeSeriesData     []SecondStats  `json:"time_series_data"`
-	CircuitBroken      bool           `json:"circuit_broken,omitempty"`
-	CircuitBreakReason string         `json:"circuit_break_reason,omitempty"`
+	TargetURL          string              `json:"target_url"`
+	Method             string              `json:"method"`
+	Duration           time.Duration       `json:"duration"`              // Configured duration
+	Elapsed            time.Duration       `json:"elapsed"`               // Actual run time (shorter than Duration when interrupted)
+	Interrupted        bool                `json:"interrupted,omitempty"` // Run was stopped early by a signal; metrics are partial
+	Concurrency        int                 `json:"concurrency"`
+	TotalRequests      int64               `json:"total_requests"`
+	SuccessCount       int64               `json:"success_count"`
+	FailureCount       int64               `json:"failure_count"`
+	AssertionFailures  int64               `json:"assertion_failures"` // Separate from network failures
+	SuccessRate        float64             `json:"success_rate"`
+	TotalBytes         int64               `json:"total_bytes"`      // Decoded response body bytes
+	Throughput         float64             `json:"throughput"`       // MB/s (decoded)
+	TotalWireBytes     int64               `json:"total_wire_bytes"` // Response body bytes on the wire (compressed)
+	WireThroughput     float64             `json:"wire_throughput"`  // MB/s (wire)
+	RPS                float64             `json:"rps"`
+	P50                time.Duration       `json:"p50"`
+	P75                time.Duration       `json:"p75"`
+	P90                time.Duration       `json:"p90"`
+	P95                time.Duration       `json:"p95"`
+	P99                time.Duration       `json:"p99"`
+	Max                time.Duration       `json:"max"`
+	Min                time.Duration       `json:"min"`
+	Mean               time.Duration       `json:"mean"`
+	StdDev             time.Duration       `json:"stddev"`
+	Percentiles        []PercentileValue   `json:"percentiles,omitempty"`       // The configured percentile set, in order
+	HistogramBounds    []time.Duration     `json:"histogram_bounds,omitempty"`  // Upper bounds of the histogram bins (LatencyBounds)
+	LatencyHistogram   []int64             `json:"latency_histogram,omitempty"` // Latency counts per bin, trailing zeros trimmed
+	StatusCodes        map[string]int      `json:"status_codes"`
+	Errors             map[string]int      `json:"errors"`                     // Errors by class (dns, connection_refused, timeout_header, ...)
+	ErrorSamples       map[string][]string `json:"error_samples,omitempty"`    // Distinct messages seen for each error class
+	AssertionErrors    map[string]int      `json:"assertion_errors,omitempty"` // Assertion failures by message
+	ProtocolCounts     map[string]int      `json:"protocol_counts,omitempty"`  // Protocol distribution (HTTP/1.1, HTTP/2.0)
+	TimeSeriesData     []SecondStats       `json:"time_series_data"`
+	BucketSeconds      int                 `json:"bucket_seconds,omitempty"` // Width of a time series bucket (1 unless re-bucketed by 'sayl report')
+	Stream             *StreamStats        `json:"stream,omitempty"`         // SSE stream metrics (nil when no stream steps ran)
+	Steps              []StepStats         `json:"steps,omitempty"`          // Per-step metrics in scenario order
+	Thresholds         []ThresholdResult   `json:"thresholds,omitempty"`     // Threshold outcomes (set after the run)
+	CircuitBroken      bool                `json:"circuit_broken,omitempty"`
+	CircuitBreakReason string              `json:"circuit_break_reason,omitempty"`
+	ResultsLog         *ResultsLog         `json:"results_log,omitempty"` // Raw per-request results file (nil when disabled)
+	Config             string              `json:"config,omitempty"`      // The run configuration as YAML, secrets redacted
+	Annotations        []Annotation        `json:"annotations,omitempty"` // Manual control actions, in order
+}
+
+// Annotation marks a manual control action (pause, resume, rate change, stage
+// skip) in the time series.
+type Annotation struct {
+	Second int    `json:"second"` // Time series bucket the action happened in
+	Action string `json:"action"` // pause, resume, rate or skip_stage
+	Label  string `json:"label"`  // Human-readable description, e.g. "rate +20 → 220 req/s"
+}
+
+// ResultsLog describes the raw per-request results file written during the run
+type ResultsLog struct {
+	Path    string `json:"path"`
+	Format  string `json:"format"`
+	Records int64  `json:"records"`           // Records written
+	Dropped int64  `json:"dropped,omitempty"` // Records dropped because the writer fell behind
+	Error   string `json:"error,omitempty"`   // Set when the file could not be created or written
+}
+
+// StreamStats summarises SSE stream behaviour across all streamed requests
+type StreamStats struct {
+	Streams      int64         `json:"streams"`       // Number of streamed responses
+	Events       int64         `json:"events"`        // Total events received
+	EmptyStreams int64         `json:"empty_streams"` // Streams that ended without a single event
+	TTFEP50      time.Duration `json:"ttfe_p50"`      // Time to first event
+	TTFEP90      time.Duration `json:"ttfe_p90"`
+	TTFEP99      time.Duration `json:"ttfe_p99"`
+	TTFEMax      time.Duration `json:"ttfe_max"`
+	GapP50       time.Duration `json:"gap_p50"` // Inter-event gap
+	GapP90       time.Duration `json:"gap_p90"`
+	GapP99       time.Duration `json:"gap_p99"`
+	GapMax       time.Duration `json:"gap_max"`
+	EventsPerSec float64       `json:"events_per_sec"` // Average event rate over the test
 }